@@ -0,0 +1,133 @@
+// Command compare loads two cross-language benchmark JSON reports (see
+// benchmarks/stats.go for the schema) and prints, per named operation,
+// the speedup ratio between them along with whether their 95% confidence
+// intervals on the median overlap — an overlap means the difference
+// isn't distinguishable from noise at this sample size, so reporting a
+// speedup number without that flag would overstate the comparison's
+// precision.
+//
+// Run with: go run ./benchmarks/compare <baseline.json> <candidate.json>
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const supportedSchemaVersion = 2
+
+// environment and stats mirror the fields of benchmarks/stats.go's
+// Environment/Stats; duplicated here rather than imported because this
+// tree has no module manifest tying the two directories together.
+type environment struct {
+	CPU       string `json:"cpu"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	GoVersion string `json:"go_version"`
+	GitCommit string `json:"git_commit"`
+}
+
+type stats struct {
+	Median   float64 `json:"median_ns"`
+	Mean     float64 `json:"mean_ns"`
+	Min      float64 `json:"min_ns"`
+	P95      float64 `json:"p95_ns"`
+	P99      float64 `json:"p99_ns"`
+	StdDev   float64 `json:"stddev_ns"`
+	CI95Low  float64 `json:"ci95_low_ns"`
+	CI95High float64 `json:"ci95_high_ns"`
+}
+
+type benchResult struct {
+	Name       string  `json:"name"`
+	Iterations int     `json:"iterations"`
+	Batches    int     `json:"batches"`
+	Stats      stats   `json:"stats"`
+	Throughput float64 `json:"throughput_ops_sec"`
+}
+
+type report struct {
+	SchemaVersion int           `json:"schema_version"`
+	Environment   environment   `json:"environment"`
+	Results       []benchResult `json:"results"`
+}
+
+func loadReport(path string) (report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var r report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return report{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// ciOverlaps reports whether two [low, high] confidence intervals share
+// any point, in which case their medians aren't distinguishably different.
+func ciOverlaps(aLow, aHigh, bLow, bHigh float64) bool {
+	return aLow <= bHigh && bLow <= aHigh
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s <baseline.json> <candidate.json>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	baseline, err := loadReport(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	candidate, err := loadReport(os.Args[2])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if baseline.SchemaVersion != supportedSchemaVersion || candidate.SchemaVersion != supportedSchemaVersion {
+		fmt.Fprintf(os.Stderr, "warning: expected schema_version %d, got %d (baseline) and %d (candidate)\n",
+			supportedSchemaVersion, baseline.SchemaVersion, candidate.SchemaVersion)
+	}
+
+	byName := make(map[string]benchResult, len(baseline.Results))
+	for _, r := range baseline.Results {
+		byName[r.Name] = r
+	}
+
+	fmt.Printf("baseline:  %s @ %s (%s/%s, %s)\n", baseline.Environment.GitCommit, baseline.Environment.CPU,
+		baseline.Environment.GOOS, baseline.Environment.GOARCH, baseline.Environment.GoVersion)
+	fmt.Printf("candidate: %s @ %s (%s/%s, %s)\n\n", candidate.Environment.GitCommit, candidate.Environment.CPU,
+		candidate.Environment.GOOS, candidate.Environment.GOARCH, candidate.Environment.GoVersion)
+
+	candidateByName := make(map[string]benchResult, len(candidate.Results))
+	names := make([]string, 0, len(candidate.Results))
+	for _, r := range candidate.Results {
+		candidateByName[r.Name] = r
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-28s %14s %14s %10s  %s\n", "operation", "baseline ns", "candidate ns", "speedup", "significant?")
+	for _, name := range names {
+		c := candidateByName[name]
+		b, ok := byName[name]
+		if !ok {
+			fmt.Printf("%-28s %14s %14.2f %10s  %s\n", name, "(missing)", c.Stats.Median, "-", "-")
+			continue
+		}
+
+		speedup := b.Stats.Median / c.Stats.Median
+		overlap := ciOverlaps(b.Stats.CI95Low, b.Stats.CI95High, c.Stats.CI95Low, c.Stats.CI95High)
+		significance := "yes"
+		if overlap {
+			significance = "no (CIs overlap)"
+		}
+
+		fmt.Printf("%-28s %14.2f %14.2f %9.2fx  %s\n", name, b.Stats.Median, c.Stats.Median, speedup, significance)
+	}
+}
@@ -1,8 +1,11 @@
 // Cross-Language Overhead Measurement - Go Binding
 //
 // This measures FFI overhead for Go bindings compared to the Rust baseline.
+// The statistics harness (batching, outlier rejection, bootstrap CIs, the
+// JSON report schema) lives in stats.go; this file only defines the
+// operations under test.
 //
-// Run with: go run benchmarks/cross_language_overhead.go
+// Run with: go run ./benchmarks
 
 package main
 
@@ -10,59 +13,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"time"
 )
 
-const (
-	iterations       = 100000
-	warmupIterations = 1000
-)
-
-// BenchResult holds benchmark results
-type BenchResult struct {
-	Name       string  `json:"name"`
-	Iterations int     `json:"iterations"`
-	TotalNs    int64   `json:"total_ns"`
-	AvgNs      float64 `json:"avg_ns"`
-	Throughput float64 `json:"throughput"`
-}
-
-func newBenchResult(name string, iterations int, totalNs int64) BenchResult {
-	avgNs := float64(totalNs) / float64(iterations)
-	throughput := 1_000_000_000.0 / avgNs
-	return BenchResult{
-		Name:       name,
-		Iterations: iterations,
-		TotalNs:    totalNs,
-		AvgNs:      avgNs,
-		Throughput: throughput,
-	}
-}
-
-func (r BenchResult) Print() {
-	fmt.Printf("%-40s %10d iterations, %10.2f ns/op, %12.0f ops/sec\n",
-		r.Name, r.Iterations, r.AvgNs, r.Throughput)
-}
-
-// Warmup runs the function to trigger optimizations
-func warmup(f func()) {
-	for i := 0; i < warmupIterations; i++ {
-		f()
-	}
-}
-
-// Bench measures function execution time
-func bench(name string, iterations int, f func()) BenchResult {
-	warmup(f)
-
-	start := time.Now()
-	for i := 0; i < iterations; i++ {
-		f()
-	}
-	elapsed := time.Since(start)
-
-	return newBenchResult(name, iterations, elapsed.Nanoseconds())
-}
+const iterations = 100000
 
 // Simulate Point type (replace with actual nanopdf.Point when available)
 type Point struct {
@@ -190,7 +143,7 @@ func (b *Buffer) AppendData(data []byte) {
 func main() {
 	fmt.Println("=== Cross-Language Overhead Benchmark (Go) ===")
 	fmt.Println()
-	fmt.Printf("Iterations: %d\n", iterations)
+	fmt.Printf("Iterations: %d (%d batches of %d)\n", iterations, batchCount, iterations/batchCount)
 	fmt.Printf("Warmup: %d\n", warmupIterations)
 	fmt.Println()
 
@@ -350,9 +303,16 @@ func main() {
 
 	fmt.Println()
 
-	// Output JSON for cross-language comparison
+	// Output JSON for cross-language comparison. The Rust baseline emits
+	// the same schema_version/environment/results shape so
+	// benchmarks/compare can load either side without special-casing.
+	report := Report{
+		SchemaVersion: schemaVersion,
+		Environment:   currentEnvironment(),
+		Results:       results,
+	}
 	fmt.Println("--- JSON Output ---")
-	jsonBytes, _ := json.MarshalIndent(results, "", "  ")
+	jsonBytes, _ := json.MarshalIndent(report, "", "  ")
 	fmt.Println(string(jsonBytes))
 }
 
@@ -0,0 +1,316 @@
+// Statistically-rigorous benchmark harness shared by
+// cross_language_overhead.go and the Rust baseline's equivalent driver.
+//
+// Each named benchmark runs as a fixed number of measurement batches
+// rather than one long loop, so per-batch timing noise (GC pauses,
+// scheduler preemption, thermal throttling) shows up as a distribution
+// instead of being averaged away into a single misleading number.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaVersion identifies the JSON report shape emitted by Report, so a
+// consumer (benchmarks/compare, or a future Rust-side report) can tell
+// which fields to expect.
+const schemaVersion = 2
+
+// batchCount is the number of independent measurement batches each
+// benchmark runs; more batches means a tighter bootstrap CI on the
+// median at the cost of longer runs.
+const batchCount = 30
+
+// outlierRejectionThreshold is the fraction of batches that may be
+// discarded as outliers before the whole measurement is considered too
+// noisy to trust and is re-run.
+const outlierRejectionThreshold = 0.10
+
+// maxMeasurementAttempts bounds the re-run loop outlierRejectionThreshold
+// can trigger, so a benchmark that's inherently this noisy still
+// terminates instead of looping forever.
+const maxMeasurementAttempts = 3
+
+// Environment captures the machine and build a report was generated on,
+// so benchmarks/compare can flag comparisons across incompatible runs.
+type Environment struct {
+	CPU       string `json:"cpu"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	GoVersion string `json:"go_version"`
+	GitCommit string `json:"git_commit"`
+}
+
+// currentEnvironment inspects the running process for Environment's fields.
+func currentEnvironment() Environment {
+	return Environment{
+		CPU:       cpuModel(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		GitCommit: gitCommit(),
+	}
+}
+
+// cpuModel returns a human-readable CPU identifier. On Linux it reads the
+// "model name" field from /proc/cpuinfo; elsewhere (or on failure) it
+// falls back to arch/core-count, which is all runtime exposes portably.
+func cpuModel() string {
+	if runtime.GOOS == "linux" {
+		if out, err := exec.Command("sh", "-c", "grep -m1 'model name' /proc/cpuinfo").Output(); err == nil {
+			if idx := strings.Index(string(out), ":"); idx >= 0 {
+				return strings.TrimSpace(string(out[idx+1:]))
+			}
+		}
+	}
+	return runtime.GOARCH + " (" + strconv.Itoa(runtime.NumCPU()) + " cores)"
+}
+
+// gitCommit returns the current commit hash, or "unknown" if this isn't a
+// git checkout (e.g. a vendored snapshot run outside its repo).
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Stats summarizes a benchmark's retained per-batch ns/op samples.
+type Stats struct {
+	Median     float64 `json:"median_ns"`
+	Mean       float64 `json:"mean_ns"`
+	Min        float64 `json:"min_ns"`
+	P95        float64 `json:"p95_ns"`
+	P99        float64 `json:"p99_ns"`
+	StdDev     float64 `json:"stddev_ns"`
+	CI95Low    float64 `json:"ci95_low_ns"`
+	CI95High   float64 `json:"ci95_high_ns"`
+	DiscardedN int     `json:"discarded_outliers"`
+}
+
+// BenchResult holds one named benchmark's full measurement: the retained
+// per-batch samples (so benchmarks/compare can recompute anything it
+// needs without re-running the benchmark) plus the aggregate Stats.
+type BenchResult struct {
+	Name       string    `json:"name"`
+	Iterations int       `json:"iterations"`
+	Batches    int       `json:"batches"`
+	Samples    []float64 `json:"samples"`
+	Stats      Stats     `json:"stats"`
+	Throughput float64   `json:"throughput_ops_sec"`
+}
+
+func (r BenchResult) Print() {
+	fmt.Printf("%-40s %10d iterations, %10.2f ns/op (median, CI95 [%.2f, %.2f]), %12.0f ops/sec\n",
+		r.Name, r.Iterations, r.Stats.Median, r.Stats.CI95Low, r.Stats.CI95High, r.Throughput)
+}
+
+// Report is the top-level JSON document bench results are collected
+// into; schema_version lets a consumer detect an incompatible layout
+// before trying to parse the rest.
+type Report struct {
+	SchemaVersion int           `json:"schema_version"`
+	Environment   Environment   `json:"environment"`
+	Results       []BenchResult `json:"results"`
+}
+
+// warmupIterations is run before any batch is timed, to let the Go
+// runtime settle (JIT-free here, but allocator/GC steady-state still
+// takes a few thousand iterations to reach).
+const warmupIterations = 1000
+
+func warmup(f func()) {
+	for i := 0; i < warmupIterations; i++ {
+		f()
+	}
+}
+
+// bench measures f as batchCount independent batches of iterations/batchCount
+// calls each, discards outlier batches beyond median+3*MAD, and returns the
+// retained samples plus their aggregate Stats (including a bootstrap 95%
+// CI on the median).
+func bench(name string, iterations int, f func()) BenchResult {
+	warmup(f)
+
+	perBatch := iterations / batchCount
+	if perBatch < 1 {
+		perBatch = 1
+	}
+
+	var samples []float64
+	var discarded int
+	for attempt := 0; attempt < maxMeasurementAttempts; attempt++ {
+		samples = measureBatches(perBatch, f)
+		retained, rejected := rejectOutliers(samples)
+		discarded = len(samples) - len(retained)
+		if float64(discarded)/float64(len(samples)) <= outlierRejectionThreshold || rejected == 0 || attempt == maxMeasurementAttempts-1 {
+			samples = retained
+			break
+		}
+		// Too noisy — try again rather than reporting a distribution
+		// that's mostly outliers.
+	}
+
+	st := computeStats(samples)
+	st.DiscardedN = discarded
+
+	return BenchResult{
+		Name:       name,
+		Iterations: iterations,
+		Batches:    len(samples),
+		Samples:    samples,
+		Stats:      st,
+		Throughput: 1_000_000_000.0 / st.Median,
+	}
+}
+
+// measureBatches runs batchCount independent batches of perBatch calls to
+// f, returning each batch's mean nanoseconds/op.
+func measureBatches(perBatch int, f func()) []float64 {
+	samples := make([]float64, batchCount)
+	for b := 0; b < batchCount; b++ {
+		start := time.Now()
+		for i := 0; i < perBatch; i++ {
+			f()
+		}
+		elapsed := time.Since(start)
+		samples[b] = float64(elapsed.Nanoseconds()) / float64(perBatch)
+	}
+	return samples
+}
+
+// rejectOutliers discards samples further than 3 median-absolute-deviations
+// above the median — the robust (non-normal-assuming) analog of a 3-sigma
+// rule, appropriate for the right-skewed distributions timing data
+// produces (a batch can only be slowed down, not sped up, by a GC pause).
+func rejectOutliers(samples []float64) (retained []float64, rejectedCount int) {
+	med := median(samples)
+	mad := medianAbsoluteDeviation(samples, med)
+	threshold := med + 3*mad
+
+	retained = make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if mad == 0 || s <= threshold {
+			retained = append(retained, s)
+		} else {
+			rejectedCount++
+		}
+	}
+	if len(retained) == 0 {
+		// Degenerate case (every sample identical and somehow "above"
+		// threshold due to floating point): keep everything rather than
+		// return an empty distribution.
+		return samples, 0
+	}
+	return retained, rejectedCount
+}
+
+func computeStats(samples []float64) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	lo, hi := bootstrapMedianCI(samples, 1000, 0.025, 0.975)
+
+	return Stats{
+		Median:   median(samples),
+		Mean:     mean(samples),
+		Min:      sorted[0],
+		P95:      percentile(sorted, 0.95),
+		P99:      percentile(sorted, 0.99),
+		StdDev:   stddev(samples),
+		CI95Low:  lo,
+		CI95High: hi,
+	}
+}
+
+func mean(samples []float64) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	return sum / float64(len(samples))
+}
+
+func median(samples []float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 0.5)
+}
+
+func medianAbsoluteDeviation(samples []float64, med float64) float64 {
+	deviations := make([]float64, len(samples))
+	for i, s := range samples {
+		deviations[i] = math.Abs(s - med)
+	}
+	return median(deviations)
+}
+
+func stddev(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	m := mean(samples)
+	var sumSq float64
+	for _, s := range samples {
+		d := s - m
+		sumSq += d * d
+	}
+	return math.Sqrt(sumSq / float64(len(samples)-1))
+}
+
+// percentile returns the p-th percentile (0..1) of an already-sorted
+// slice, linearly interpolating between the two nearest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := p * float64(len(sorted)-1)
+	lo := int(math.Floor(idx))
+	hi := int(math.Ceil(idx))
+	if lo == hi {
+		return sorted[lo]
+	}
+	frac := idx - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
+
+// bootstrapMedianCI computes a confidence interval on samples' median by
+// bootstrap resampling: draw len(samples) values with replacement,
+// compute that resample's median, repeat resamples times, then take the
+// [lowPct, highPct] percentiles of the resulting distribution of medians.
+func bootstrapMedianCI(samples []float64, resamples int, lowPct, highPct float64) (low, high float64) {
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	if len(samples) == 1 {
+		return samples[0], samples[0]
+	}
+
+	rng := rand.New(rand.NewSource(1)) // fixed seed: reproducible reports across runs
+	medians := make([]float64, resamples)
+	resample := make([]float64, len(samples))
+	for i := 0; i < resamples; i++ {
+		for j := range resample {
+			resample[j] = samples[rng.Intn(len(samples))]
+		}
+		medians[i] = median(resample)
+	}
+	sort.Float64s(medians)
+	return percentile(medians, lowPct), percentile(medians, highPct)
+}
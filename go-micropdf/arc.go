@@ -0,0 +1,202 @@
+package micropdf
+
+import "math"
+
+// Arc describes an elliptical arc in center parameterization: centered at
+// Center with radii Rx/Ry, rotated by PhiDegrees, sweeping from
+// StartAngleDegrees through SweepDegrees degrees (positive sweeps
+// counter-clockwise, matching MatrixRotate's sign convention).
+type Arc struct {
+	Center            Point
+	Rx, Ry            float32
+	PhiDegrees        float32
+	StartAngleDegrees float32
+	SweepDegrees      float32
+}
+
+// SVGArc describes an elliptical arc in the endpoint parameterization used
+// by the SVG path "A" operator: from Start to End along an ellipse of
+// radii Rx/Ry rotated by PhiDegrees, picking one of the (up to) four
+// matching arcs via LargeArc and Sweep.
+type SVGArc struct {
+	Start, End Point
+	Rx, Ry     float32
+	PhiDegrees float32
+	LargeArc   bool
+	Sweep      bool
+}
+
+// ArcsToCubicsBatch converts each center-parameterized arc into a sequence
+// of cubic Beziers approximating it to PDF path precision (PDF has no
+// native arc operator). Each arc is split into ceil(|sweep|/90°) sub-arcs,
+// the widest single span a cubic can approximate without unacceptable
+// error, and every cubic is concatenated into one slice in order.
+func ArcsToCubicsBatch(arcs []Arc) []CubicBezier {
+	var out []CubicBezier
+	for _, a := range arcs {
+		out = append(out, arcToCubics(a)...)
+	}
+	return out
+}
+
+// SVGArcsToCubicsBatch converts SVG endpoint-parameterized arcs ("A" path
+// operator arguments) to cubic Beziers, first resolving each to its
+// equivalent center-parameterized Arc per the SVG spec's endpoint-to-center
+// conversion (F.6.5). An arc whose radii or endpoints are degenerate
+// (Start == End, or Rx or Ry is zero) has no corresponding ellipse, so it
+// contributes nothing — callers should draw a straight line from Start to
+// End for those instead.
+func SVGArcsToCubicsBatch(arcs []SVGArc) []CubicBezier {
+	var out []CubicBezier
+	for _, a := range arcs {
+		center, ok := a.toCenterForm()
+		if !ok {
+			continue
+		}
+		out = append(out, arcToCubics(center)...)
+	}
+	return out
+}
+
+// arcToCubics splits a into sub-arcs of no more than a quarter turn and
+// approximates each with a cubic Bezier, built in the unit-circle frame
+// centered on the sub-arc's own mid-angle and then mapped into place by
+// the rotation to that mid-angle followed by a's ellipse transform
+// (rotate by PhiDegrees, scale by Rx/Ry, translate to Center).
+func arcToCubics(a Arc) []CubicBezier {
+	if a.SweepDegrees == 0 || a.Rx == 0 || a.Ry == 0 {
+		return nil
+	}
+
+	sweep := float64(a.SweepDegrees) * math.Pi / 180
+	start := float64(a.StartAngleDegrees) * math.Pi / 180
+
+	k := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+	if k < 1 {
+		k = 1
+	}
+	delta := sweep / float64(k)
+	eta := delta / 2
+
+	sinEta, cosEta := math.Sincos(eta)
+	alpha := float32(4.0 / 3.0 * math.Tan(eta/2))
+	cosEtaF, sinEtaF := float32(cosEta), float32(sinEta)
+
+	// P0=(cos(-eta), sin(-eta)), P3=(cos(eta), sin(eta)); P1/P2 pulled off
+	// P0/P3 along the tangent there by alpha, per the standard circular-arc
+	// to cubic-Bezier approximation.
+	local := CubicBezier{
+		P0: Point{X: cosEtaF, Y: -sinEtaF},
+		P1: Point{X: cosEtaF + alpha*sinEtaF, Y: -sinEtaF + alpha*cosEtaF},
+		P2: Point{X: cosEtaF + alpha*sinEtaF, Y: sinEtaF - alpha*cosEtaF},
+		P3: Point{X: cosEtaF, Y: sinEtaF},
+	}
+
+	ellipse := MatrixScale(a.Rx, a.Ry).Concat(MatrixRotate(a.PhiDegrees)).Concat(MatrixTranslate(a.Center.X, a.Center.Y))
+
+	out := make([]CubicBezier, k)
+	for i := 0; i < k; i++ {
+		mid := start + delta*float64(i) + eta
+		out[i] = local.Transform(matrixRotateRadians(mid).Concat(ellipse))
+	}
+	return out
+}
+
+// matrixRotateRadians is MatrixRotate with its angle already in radians,
+// for angles — like an arc's mid-angle — that arise from radian math
+// rather than a degree literal.
+func matrixRotateRadians(rad float64) Matrix {
+	sin, cos := math.Sincos(rad)
+	return Matrix{A: float32(cos), B: float32(sin), C: float32(-sin), D: float32(cos)}
+}
+
+// toCenterForm converts a to center parameterization via the SVG spec's
+// endpoint-to-center conversion (F.6.5). ok is false if a is degenerate
+// (Start == End, or Rx or Ry is zero), which has no corresponding ellipse.
+func (a SVGArc) toCenterForm() (Arc, bool) {
+	if a.Rx == 0 || a.Ry == 0 || a.Start == a.End {
+		return Arc{}, false
+	}
+
+	rx, ry := math.Abs(float64(a.Rx)), math.Abs(float64(a.Ry))
+	phi := float64(a.PhiDegrees) * math.Pi / 180
+	sinPhi, cosPhi := math.Sincos(phi)
+
+	// Step 1: the start point in the ellipse's unrotated, centered frame.
+	dx2 := float64(a.Start.X-a.End.X) / 2
+	dy2 := float64(a.Start.Y-a.End.Y) / 2
+	x1p := cosPhi*dx2 + sinPhi*dy2
+	y1p := -sinPhi*dx2 + cosPhi*dy2
+
+	// Step 2: scale up radii that are too small to reach between the
+	// endpoints at all.
+	lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry)
+	if lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx *= s
+		ry *= s
+	}
+
+	// Step 3: the ellipse center in that same unrotated, centered frame.
+	sign := -1.0
+	if a.LargeArc == a.Sweep {
+		sign = 1.0
+	}
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	co := 0.0
+	if den != 0 && num > 0 {
+		co = sign * math.Sqrt(num/den)
+	}
+	cxp := co * (rx * y1p / ry)
+	cyp := co * -(ry * x1p / rx)
+
+	// Step 4: transform the center back to user space.
+	cx := cosPhi*cxp - sinPhi*cyp + float64(a.Start.X+a.End.X)/2
+	cy := sinPhi*cxp + cosPhi*cyp + float64(a.Start.Y+a.End.Y)/2
+
+	// Step 5: the start angle and sweep angle that reach End.
+	ux, uy := (x1p-cxp)/rx, (y1p-cyp)/ry
+	vx, vy := (-x1p-cxp)/rx, (-y1p-cyp)/ry
+
+	startAngle := angleBetween(1, 0, ux, uy)
+	sweep := angleBetween(ux, uy, vx, vy)
+	if !a.Sweep && sweep > 0 {
+		sweep -= 2 * math.Pi
+	} else if a.Sweep && sweep < 0 {
+		sweep += 2 * math.Pi
+	}
+
+	return Arc{
+		Center:            Point{X: float32(cx), Y: float32(cy)},
+		Rx:                float32(rx),
+		Ry:                float32(ry),
+		PhiDegrees:        a.PhiDegrees,
+		StartAngleDegrees: float32(startAngle * 180 / math.Pi),
+		SweepDegrees:      float32(sweep * 180 / math.Pi),
+	}, true
+}
+
+// angleBetween returns the signed angle in radians from vector (ux, uy) to
+// vector (vx, vy).
+func angleBetween(ux, uy, vx, vy float64) float64 {
+	dot := ux*vx + uy*vy
+	length := math.Sqrt(ux*ux+uy*uy) * math.Sqrt(vx*vx+vy*vy)
+	angle := math.Acos(clampUnit(dot / length))
+	if ux*vy-uy*vx < 0 {
+		angle = -angle
+	}
+	return angle
+}
+
+// clampUnit clamps x to [-1, 1], guarding acos against float error pushing
+// an exactly-parallel dot product a hair outside its domain.
+func clampUnit(x float64) float64 {
+	if x > 1 {
+		return 1
+	}
+	if x < -1 {
+		return -1
+	}
+	return x
+}
@@ -0,0 +1,104 @@
+package micropdf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestArcsToCubicsBatch(t *testing.T) {
+	t.Run("QuarterCircleIsOneCubic", func(t *testing.T) {
+		a := Arc{Center: Origin, Rx: 10, Ry: 10, StartAngleDegrees: 0, SweepDegrees: 90}
+		cubics := ArcsToCubicsBatch([]Arc{a})
+		if len(cubics) != 1 {
+			t.Fatalf("expected 1 cubic for a 90 degree sweep, got %d", len(cubics))
+		}
+
+		if !pointNear(cubics[0].P0, Point{X: 10, Y: 0}, 1e-4) {
+			t.Errorf("P0 = %+v, want (10, 0)", cubics[0].P0)
+		}
+		if !pointNear(cubics[0].P3, Point{X: 0, Y: 10}, 1e-4) {
+			t.Errorf("P3 = %+v, want (0, 10)", cubics[0].P3)
+		}
+	})
+
+	t.Run("FullCircleSplitsIntoFourQuarters", func(t *testing.T) {
+		a := Arc{Center: Origin, Rx: 5, Ry: 5, StartAngleDegrees: 0, SweepDegrees: 360}
+		cubics := ArcsToCubicsBatch([]Arc{a})
+		if len(cubics) != 4 {
+			t.Fatalf("expected 4 cubics for a full sweep, got %d", len(cubics))
+		}
+		// Consecutive cubics should join end to end, and the loop should close.
+		for i, c := range cubics {
+			next := cubics[(i+1)%len(cubics)]
+			if !pointNear(c.P3, next.P0, 1e-4) {
+				t.Errorf("cubic %d end %+v doesn't meet cubic %d start %+v", i, c.P3, (i+1)%len(cubics), next.P0)
+			}
+		}
+		if !pointNear(cubics[0].P0, cubics[len(cubics)-1].P3, 1e-4) {
+			t.Errorf("full sweep doesn't close: start %+v, end %+v", cubics[0].P0, cubics[len(cubics)-1].P3)
+		}
+	})
+
+	t.Run("EndpointsLieOnTheEllipse", func(t *testing.T) {
+		a := Arc{Center: Point{X: 3, Y: -2}, Rx: 20, Ry: 8, PhiDegrees: 30, StartAngleDegrees: 10, SweepDegrees: 200}
+		cubics := ArcsToCubicsBatch([]Arc{a})
+		for _, c := range cubics {
+			for _, p := range []Point{c.P0, c.P3} {
+				if d := ellipseResidual(p, a); math.Abs(float64(d)) > 1e-3 {
+					t.Errorf("endpoint %+v isn't on the ellipse: residual %v", p, d)
+				}
+			}
+		}
+	})
+
+	t.Run("ZeroSweepOrRadiusYieldsNothing", func(t *testing.T) {
+		arcs := []Arc{
+			{Center: Origin, Rx: 10, Ry: 10, SweepDegrees: 0},
+			{Center: Origin, Rx: 0, Ry: 10, SweepDegrees: 90},
+		}
+		if got := ArcsToCubicsBatch(arcs); len(got) != 0 {
+			t.Errorf("expected no cubics for degenerate arcs, got %d", len(got))
+		}
+	})
+}
+
+func TestSVGArcsToCubicsBatch(t *testing.T) {
+	t.Run("SemicircleReachesBothEndpoints", func(t *testing.T) {
+		a := SVGArc{Start: Point{X: -10, Y: 0}, End: Point{X: 10, Y: 0}, Rx: 10, Ry: 10, Sweep: true}
+		cubics := SVGArcsToCubicsBatch([]SVGArc{a})
+		if len(cubics) == 0 {
+			t.Fatal("expected at least one cubic")
+		}
+		if !pointNear(cubics[0].P0, a.Start, 1e-3) {
+			t.Errorf("first P0 = %+v, want start %+v", cubics[0].P0, a.Start)
+		}
+		if last := cubics[len(cubics)-1]; !pointNear(last.P3, a.End, 1e-3) {
+			t.Errorf("last P3 = %+v, want end %+v", last.P3, a.End)
+		}
+	})
+
+	t.Run("DegenerateArcIsSkipped", func(t *testing.T) {
+		arcs := []SVGArc{
+			{Start: Point{X: 1, Y: 1}, End: Point{X: 1, Y: 1}, Rx: 5, Ry: 5},
+			{Start: Point{X: 0, Y: 0}, End: Point{X: 1, Y: 1}, Rx: 0, Ry: 5},
+		}
+		if got := SVGArcsToCubicsBatch(arcs); len(got) != 0 {
+			t.Errorf("expected degenerate arcs to contribute nothing, got %d cubics", len(got))
+		}
+	})
+}
+
+func pointNear(a, b Point, tol float32) bool {
+	return a.Sub(b).Distance(Origin) <= tol
+}
+
+// ellipseResidual is 0 when p lies exactly on a's ellipse, via the
+// standard implicit test in the ellipse's unrotated, centered frame.
+func ellipseResidual(p Point, a Arc) float32 {
+	rad := float64(a.PhiDegrees) * math.Pi / 180
+	sin, cos := math.Sincos(-rad)
+	dx, dy := float64(p.X-a.Center.X), float64(p.Y-a.Center.Y)
+	x := dx*cos - dy*sin
+	y := dx*sin + dy*cos
+	return float32(x*x/float64(a.Rx*a.Rx) + y*y/float64(a.Ry*a.Ry) - 1)
+}
@@ -1,6 +1,8 @@
 package micropdf
 
 import (
+	"fmt"
+	"path/filepath"
 	"testing"
 )
 
@@ -143,6 +145,159 @@ func BenchmarkBufferClone1KB(b *testing.B) {
 	}
 }
 
+func BenchmarkBufferSnappyRoundTrip1KB(b *testing.B) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i & 0xff)
+	}
+	buf := NewBufferFromBytes(data)
+	if buf == nil {
+		b.Skip("buffer creation failed")
+	}
+	defer buf.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := RoundTripCompress(buf, CodecSnappy); err != nil || !ok {
+			b.Fatalf("round trip failed: ok=%v err=%v", ok, err)
+		}
+	}
+}
+
+func BenchmarkBufferSnappyRoundTrip16KB(b *testing.B) {
+	data := make([]byte, 16384)
+	for i := range data {
+		data[i] = byte(i & 0xff)
+	}
+	buf := NewBufferFromBytes(data)
+	if buf == nil {
+		b.Skip("buffer creation failed")
+	}
+	defer buf.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := RoundTripCompress(buf, CodecSnappy); err != nil || !ok {
+			b.Fatalf("round trip failed: ok=%v err=%v", ok, err)
+		}
+	}
+}
+
+func BenchmarkBufferZstdRoundTrip1KB(b *testing.B) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i & 0xff)
+	}
+	buf := NewBufferFromBytes(data)
+	if buf == nil {
+		b.Skip("buffer creation failed")
+	}
+	defer buf.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := RoundTripCompress(buf, CodecZstd); err != nil || !ok {
+			b.Fatalf("round trip failed: ok=%v err=%v", ok, err)
+		}
+	}
+}
+
+func BenchmarkBufferZstdRoundTrip16KB(b *testing.B) {
+	data := make([]byte, 16384)
+	for i := range data {
+		data[i] = byte(i & 0xff)
+	}
+	buf := NewBufferFromBytes(data)
+	if buf == nil {
+		b.Skip("buffer creation failed")
+	}
+	defer buf.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := RoundTripCompress(buf, CodecZstd); err != nil || !ok {
+			b.Fatalf("round trip failed: ok=%v err=%v", ok, err)
+		}
+	}
+}
+
+// ============================================================================
+// Cache Benchmarks
+//
+// page.RenderToPNGBounded isn't available outside a real rendering
+// backend, so these exercise the cache's own Put/Get cost against a
+// render-sized payload, comparing a cold run (populate every page) against
+// a warm run (every page already cached) the way a real caller's
+// cold-render-then-cache-hit pattern would.
+// ============================================================================
+
+func simulatedRenderedPage(page int) []byte {
+	data := make([]byte, 8192)
+	for i := range data {
+		data[i] = byte((page + i) & 0xff)
+	}
+	return data
+}
+
+func benchmarkCacheColdRender(b *testing.B, pages int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		path := filepath.Join(b.TempDir(), "bench.cache")
+		c, err := OpenCache(path, CacheOptions{})
+		if err != nil {
+			b.Fatalf("OpenCache: %v", err)
+		}
+		b.StartTimer()
+
+		for p := 0; p < pages; p++ {
+			key := NewCacheKey("bench-doc", p, NewMatrix(1, 0, 0, 1, 0, 0), false, 150)
+			if err := c.Put(key, simulatedRenderedPage(p)); err != nil {
+				b.Fatalf("Put: %v", err)
+			}
+		}
+
+		b.StopTimer()
+		c.Close()
+		b.StartTimer()
+	}
+}
+
+func benchmarkCacheWarmHit(b *testing.B, pages int) {
+	path := filepath.Join(b.TempDir(), "bench.cache")
+	c, err := OpenCache(path, CacheOptions{})
+	if err != nil {
+		b.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	keys := make([]CacheKey, pages)
+	for p := 0; p < pages; p++ {
+		keys[p] = NewCacheKey("bench-doc", p, NewMatrix(1, 0, 0, 1, 0, 0), false, 150)
+		if err := c.Put(keys[p], simulatedRenderedPage(p)); err != nil {
+			b.Fatalf("Put: %v", err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			if _, ok, err := c.Get(key); err != nil || !ok {
+				b.Fatalf("Get: ok=%v err=%v", ok, err)
+			}
+		}
+	}
+}
+
+func BenchmarkCacheColdRender1Page(b *testing.B)    { benchmarkCacheColdRender(b, 1) }
+func BenchmarkCacheColdRender10Pages(b *testing.B)  { benchmarkCacheColdRender(b, 10) }
+func BenchmarkCacheColdRender100Pages(b *testing.B) { benchmarkCacheColdRender(b, 100) }
+
+func BenchmarkCacheWarmHit1Page(b *testing.B)    { benchmarkCacheWarmHit(b, 1) }
+func BenchmarkCacheWarmHit10Pages(b *testing.B)  { benchmarkCacheWarmHit(b, 10) }
+func BenchmarkCacheWarmHit100Pages(b *testing.B) { benchmarkCacheWarmHit(b, 100) }
+
 // ============================================================================
 // Point Benchmarks
 // ============================================================================
@@ -490,6 +645,39 @@ func BenchmarkMatrixTransformRectComplex(b *testing.B) {
 	}
 }
 
+// BenchmarkTransformPoints compares the batched Matrix.TransformPoints
+// against calling Point.Transform once per element, at sizes spanning a
+// single point up to a glyph-heavy page, to confirm the batched form's
+// auto-vectorizable loop pays for itself at realistic sizes.
+func BenchmarkTransformPoints(b *testing.B) {
+	m := MatrixTranslate(10, 20).Concat(MatrixScale(1.5, 1.5)).Concat(MatrixRotate(30))
+	for _, n := range []int{1, 16, 256, 4096} {
+		src := make([]Point, n)
+		for i := range src {
+			src[i] = NewPoint(float32(i), float32(i*2))
+		}
+		dst := make([]Point, n)
+
+		b.Run(fmt.Sprintf("Batch/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.TransformPoints(dst, src)
+			}
+		})
+
+		b.Run(fmt.Sprintf("PerElement/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j, p := range src {
+					dst[j] = p.Transform(m)
+				}
+			}
+		})
+	}
+}
+
 // ============================================================================
 // Quad Benchmarks
 // ============================================================================
@@ -0,0 +1,288 @@
+package micropdf
+
+import "math"
+
+// QuadraticBezier is a quadratic Bezier curve defined by a start point, one
+// control point, and an end point.
+type QuadraticBezier struct {
+	P0, P1, P2 Point
+}
+
+// CubicBezier is a cubic Bezier curve defined by a start point, two control
+// points, and an end point — the curve type used by PDF content stream "c"
+// operators.
+type CubicBezier struct {
+	P0, P1, P2, P3 Point
+}
+
+// Transform maps every control point of c through m.
+func (c QuadraticBezier) Transform(m Matrix) QuadraticBezier {
+	return QuadraticBezier{P0: c.P0.Transform(m), P1: c.P1.Transform(m), P2: c.P2.Transform(m)}
+}
+
+// Transform maps every control point of c through m.
+func (c CubicBezier) Transform(m Matrix) CubicBezier {
+	return CubicBezier{
+		P0: c.P0.Transform(m),
+		P1: c.P1.Transform(m),
+		P2: c.P2.Transform(m),
+		P3: c.P3.Transform(m),
+	}
+}
+
+// Evaluate returns the point on c at parameter t (0 to 1) via de Casteljau.
+func (c QuadraticBezier) Evaluate(t float32) Point {
+	p01 := lerpPoint(c.P0, c.P1, t)
+	p12 := lerpPoint(c.P1, c.P2, t)
+	return lerpPoint(p01, p12, t)
+}
+
+// Evaluate returns the point on c at parameter t (0 to 1) via de Casteljau.
+func (c CubicBezier) Evaluate(t float32) Point {
+	p01 := lerpPoint(c.P0, c.P1, t)
+	p12 := lerpPoint(c.P1, c.P2, t)
+	p23 := lerpPoint(c.P2, c.P3, t)
+
+	p012 := lerpPoint(p01, p12, t)
+	p123 := lerpPoint(p12, p23, t)
+
+	return lerpPoint(p012, p123, t)
+}
+
+// Split divides c at parameter t into two quadratic Beziers covering
+// [0,t] and [t,1] of the original curve, via de Casteljau.
+func (c QuadraticBezier) Split(t float32) (QuadraticBezier, QuadraticBezier) {
+	p01 := lerpPoint(c.P0, c.P1, t)
+	p12 := lerpPoint(c.P1, c.P2, t)
+	mid := lerpPoint(p01, p12, t)
+
+	return QuadraticBezier{P0: c.P0, P1: p01, P2: mid}, QuadraticBezier{P0: mid, P1: p12, P2: c.P2}
+}
+
+// Split divides c at parameter t into two cubic Beziers covering [0,t]
+// and [t,1] of the original curve, via de Casteljau.
+func (c CubicBezier) Split(t float32) (CubicBezier, CubicBezier) {
+	p01 := lerpPoint(c.P0, c.P1, t)
+	p12 := lerpPoint(c.P1, c.P2, t)
+	p23 := lerpPoint(c.P2, c.P3, t)
+
+	p012 := lerpPoint(p01, p12, t)
+	p123 := lerpPoint(p12, p23, t)
+
+	mid := lerpPoint(p012, p123, t)
+
+	return CubicBezier{P0: c.P0, P1: p01, P2: p012, P3: mid}, CubicBezier{P0: mid, P1: p123, P2: p23, P3: c.P3}
+}
+
+// Bounds returns the axis-aligned bounding box of c, found from the roots
+// of c's derivative rather than just the (possibly much looser) control
+// point hull.
+func (c QuadraticBezier) Bounds() Rect {
+	r := boundsFromPoints(c.P0, c.P2)
+
+	// B'(t) = 2(1-t)(P1-P0) + 2t(P2-P1) is linear per axis; solve for the
+	// single root of each component.
+	addAxisRootQuadratic := func(p0, p1, p2 float32, include func(t float32)) {
+		denom := p0 - 2*p1 + p2
+		if denom == 0 {
+			return
+		}
+		t := (p0 - p1) / denom
+		include(t)
+	}
+
+	addAxisRootQuadratic(c.P0.X, c.P1.X, c.P2.X, func(t float32) {
+		if t > 0 && t < 1 {
+			r = r.IncludePoint(c.Evaluate(t))
+		}
+	})
+	addAxisRootQuadratic(c.P0.Y, c.P1.Y, c.P2.Y, func(t float32) {
+		if t > 0 && t < 1 {
+			r = r.IncludePoint(c.Evaluate(t))
+		}
+	})
+
+	return r
+}
+
+// Bounds returns the axis-aligned bounding box of c, found from the roots
+// of c's derivative rather than just the (possibly much looser) control
+// point hull.
+func (c CubicBezier) Bounds() Rect {
+	r := boundsFromPoints(c.P0, c.P3)
+
+	// B'(t) = 3(1-t)^2(P1-P0) + 6(1-t)t(P2-P1) + 3t^2(P3-P2), a quadratic
+	// per axis; solve for its roots with the standard formula.
+	for _, t := range cubicDerivativeRoots(c.P0.X, c.P1.X, c.P2.X, c.P3.X) {
+		if t > 0 && t < 1 {
+			r = r.IncludePoint(c.Evaluate(t))
+		}
+	}
+	for _, t := range cubicDerivativeRoots(c.P0.Y, c.P1.Y, c.P2.Y, c.P3.Y) {
+		if t > 0 && t < 1 {
+			r = r.IncludePoint(c.Evaluate(t))
+		}
+	}
+
+	return r
+}
+
+// cubicDerivativeRoots returns the real roots in t of the derivative of a
+// cubic Bezier's single-axis component, i.e. the extrema candidates of
+// a + 3(1-t)^2(b-a) + ... expressed as the quadratic
+// At^2 + Bt + C = 0 with A = -p0+3p1-3p2+p3, B = 2(p0-2p1+p2), C = p1-p0.
+func cubicDerivativeRoots(p0, p1, p2, p3 float32) []float32 {
+	a := -p0 + 3*p1 - 3*p2 + p3
+	b := 2 * (p0 - 2*p1 + p2)
+	c := p1 - p0
+
+	if a == 0 {
+		if b == 0 {
+			return nil
+		}
+		return []float32{-c / b}
+	}
+
+	disc := float64(b)*float64(b) - 4*float64(a)*float64(c)
+	if disc < 0 {
+		return nil
+	}
+	sqrtDisc := math.Sqrt(disc)
+	t1 := float32((-float64(b) + sqrtDisc) / (2 * float64(a)))
+	t2 := float32((-float64(b) - sqrtDisc) / (2 * float64(a)))
+	return []float32{t1, t2}
+}
+
+func boundsFromPoints(p0, p1 Point) Rect {
+	r := Rect{X0: p0.X, Y0: p0.Y, X1: p0.X, Y1: p0.Y}
+	return r.IncludePoint(p1)
+}
+
+func lerpPoint(a, b Point, t float32) Point {
+	return Point{
+		X: a.X + (b.X-a.X)*t,
+		Y: a.Y + (b.Y-a.Y)*t,
+	}
+}
+
+// maxArcLengthDepth bounds adaptive Simpson recursion so a pathological
+// control polygon (near-cusps, huge handle lengths) can't recurse
+// unboundedly.
+const maxArcLengthDepth = 20
+
+// ArcLength estimates c's arc length to within tol via adaptive Simpson's
+// rule integrating the curve's speed, ‖B'(t)‖, over [0,1].
+func (c QuadraticBezier) ArcLength(tol float32) float32 {
+	speed := func(t float32) float32 {
+		d := quadraticDerivative(c, t)
+		return float32(math.Hypot(float64(d.X), float64(d.Y)))
+	}
+	return adaptiveSimpson(speed, 0, 1, tol, maxArcLengthDepth)
+}
+
+// ArcLength estimates c's arc length to within tol via adaptive Simpson's
+// rule integrating the curve's speed, ‖B'(t)‖, over [0,1].
+func (c CubicBezier) ArcLength(tol float32) float32 {
+	speed := func(t float32) float32 {
+		d := cubicDerivative(c, t)
+		return float32(math.Hypot(float64(d.X), float64(d.Y)))
+	}
+	return adaptiveSimpson(speed, 0, 1, tol, maxArcLengthDepth)
+}
+
+func quadraticDerivative(c QuadraticBezier, t float32) Point {
+	return Point{
+		X: 2*(1-t)*(c.P1.X-c.P0.X) + 2*t*(c.P2.X-c.P1.X),
+		Y: 2*(1-t)*(c.P1.Y-c.P0.Y) + 2*t*(c.P2.Y-c.P1.Y),
+	}
+}
+
+func cubicDerivative(c CubicBezier, t float32) Point {
+	u := 1 - t
+	return Point{
+		X: 3*u*u*(c.P1.X-c.P0.X) + 6*u*t*(c.P2.X-c.P1.X) + 3*t*t*(c.P3.X-c.P2.X),
+		Y: 3*u*u*(c.P1.Y-c.P0.Y) + 6*u*t*(c.P2.Y-c.P1.Y) + 3*t*t*(c.P3.Y-c.P2.Y),
+	}
+}
+
+// simpson computes S(a,b) = (b-a)/6 * (f(a) + 4f((a+b)/2) + f(b)).
+func simpson(f func(float32) float32, a, b float32) float32 {
+	m := (a + b) / 2
+	return (b - a) / 6 * (f(a) + 4*f(m) + f(b))
+}
+
+// adaptiveSimpson integrates f over [a,b] to within tol, recursively
+// bisecting whenever the whole-interval and split-interval estimates
+// disagree by more than 15*tol, halving tol on each recursive call per
+// the standard adaptive-Simpson error-control recurrence. depth bounds
+// how far this can recurse for a pathological integrand.
+func adaptiveSimpson(f func(float32) float32, a, b, tol float32, depth int) float32 {
+	whole := simpson(f, a, b)
+	if depth <= 0 {
+		return whole
+	}
+
+	m := (a + b) / 2
+	left := simpson(f, a, m)
+	right := simpson(f, m, b)
+
+	if float32(math.Abs(float64(left+right-whole))) < 15*tol {
+		return left + right
+	}
+
+	return adaptiveSimpson(f, a, m, tol/2, depth-1) + adaptiveSimpson(f, m, b, tol/2, depth-1)
+}
+
+// arcLengthTableSamples is the resolution of the monotone arc-length
+// table PointAtLength builds before bisecting it.
+const arcLengthTableSamples = 256
+
+// PointAtLength returns the point at arc-length distance s along c
+// (clamped to [0, total length]), and the total arc length of c, via a
+// monotone arc-length table and bisection — the standard approach for
+// placing text along a path at even visual spacing.
+func (c CubicBezier) PointAtLength(s float32) (Point, float32) {
+	var ts, lengths [arcLengthTableSamples + 1]float32
+	var prev Point
+	var total float32
+
+	for i := 0; i <= arcLengthTableSamples; i++ {
+		t := float32(i) / float32(arcLengthTableSamples)
+		p := c.Evaluate(t)
+		if i > 0 {
+			total += p.Distance(prev)
+		}
+		ts[i] = t
+		lengths[i] = total
+		prev = p
+	}
+
+	if s <= 0 {
+		return c.P0, total
+	}
+	if s >= total {
+		return c.P3, total
+	}
+
+	lo, hi := 0, arcLengthTableSamples
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if lengths[mid] < s {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	if lo == 0 {
+		return c.P0, total
+	}
+
+	segLen := lengths[lo] - lengths[lo-1]
+	frac := float32(0)
+	if segLen > 0 {
+		frac = (s - lengths[lo-1]) / segLen
+	}
+	t := ts[lo-1] + (ts[lo]-ts[lo-1])*frac
+	return c.Evaluate(t), total
+}
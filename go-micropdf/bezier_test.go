@@ -0,0 +1,126 @@
+package micropdf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCubicBezier(t *testing.T) {
+	t.Run("EvaluateEndpoints", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(0, 50), P2: NewPoint(100, 50), P3: NewPoint(100, 0)}
+		if !c.Evaluate(0).Equals(c.P0) {
+			t.Errorf("Evaluate(0) = %+v, want P0", c.Evaluate(0))
+		}
+		if !c.Evaluate(1).Equals(c.P3) {
+			t.Errorf("Evaluate(1) = %+v, want P3", c.Evaluate(1))
+		}
+	})
+
+	t.Run("Transform", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(10, 0), P2: NewPoint(10, 10), P3: NewPoint(0, 10)}
+		result := c.Transform(MatrixTranslate(5, 5))
+		if result.P0.X != 5 || result.P0.Y != 5 || result.P3.X != 5 || result.P3.Y != 15 {
+			t.Errorf("unexpected transformed curve: %+v", result)
+		}
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(0, 50), P2: NewPoint(100, 50), P3: NewPoint(100, 0)}
+		left, right := c.Split(0.5)
+		mid := c.Evaluate(0.5)
+		if !left.P3.Equals(mid) || !right.P0.Equals(mid) {
+			t.Errorf("split halves should meet at Evaluate(0.5), got left.P3=%+v right.P0=%+v mid=%+v", left.P3, right.P0, mid)
+		}
+	})
+
+	t.Run("BoundsStraightLine", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(33, 33), P2: NewPoint(66, 66), P3: NewPoint(100, 100)}
+		b := c.Bounds()
+		if b.X0 != 0 || b.Y0 != 0 || b.X1 != 100 || b.Y1 != 100 {
+			t.Errorf("unexpected bounds for degenerate straight line: %+v", b)
+		}
+	})
+
+	t.Run("BoundsBulgingCurve", func(t *testing.T) {
+		// Control points bulge well outside the endpoint-only box.
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(0, 200), P2: NewPoint(100, 200), P3: NewPoint(100, 0)}
+		b := c.Bounds()
+		if b.Y1 <= 100 {
+			t.Errorf("expected bounds to include the curve's bulge, got %+v", b)
+		}
+	})
+
+	t.Run("ArcLengthStraightLine", func(t *testing.T) {
+		// A degenerate cubic with all control points on the line from P0 to
+		// P3 should have arc length equal to the straight-line distance.
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(25, 25), P2: NewPoint(75, 75), P3: NewPoint(100, 100)}
+		got := c.ArcLength(1e-6)
+		want := c.P0.Distance(c.P3)
+		if math.Abs(float64(got-want)) > 1e-3 {
+			t.Errorf("ArcLength() = %f, want %f", got, want)
+		}
+	})
+
+	t.Run("ArcLengthQuarterCircle", func(t *testing.T) {
+		// Standard cubic approximation of a unit-radius quarter circle.
+		const k = 0.5522847498
+		c := CubicBezier{
+			P0: NewPoint(1, 0),
+			P1: NewPoint(1, k),
+			P2: NewPoint(k, 1),
+			P3: NewPoint(0, 1),
+		}
+		got := c.ArcLength(1e-6)
+		want := float32(math.Pi / 2)
+		// The classic tangent-matching kappa constant doesn't make the
+		// cubic's arc length exactly pi/2 — it leaves a small residual
+		// (~2e-4) from approximating an arc with a cubic at all.
+		if math.Abs(float64(got-want)) > 5e-4 {
+			t.Errorf("ArcLength() = %f, want %f (pi/2)", got, want)
+		}
+	})
+
+	t.Run("PointAtLength", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(0, 50), P2: NewPoint(100, 50), P3: NewPoint(100, 0)}
+
+		start, total := c.PointAtLength(0)
+		if !start.Equals(c.P0) {
+			t.Errorf("PointAtLength(0) = %+v, want P0", start)
+		}
+
+		end, _ := c.PointAtLength(total)
+		if end.Distance(c.P3) > 0.5 {
+			t.Errorf("PointAtLength(total) = %+v, want near P3 %+v", end, c.P3)
+		}
+	})
+}
+
+func TestQuadraticBezier(t *testing.T) {
+	t.Run("EvaluateEndpoints", func(t *testing.T) {
+		c := QuadraticBezier{P0: NewPoint(0, 0), P1: NewPoint(50, 100), P2: NewPoint(100, 0)}
+		if !c.Evaluate(0).Equals(c.P0) {
+			t.Errorf("Evaluate(0) = %+v, want P0", c.Evaluate(0))
+		}
+		if !c.Evaluate(1).Equals(c.P2) {
+			t.Errorf("Evaluate(1) = %+v, want P2", c.Evaluate(1))
+		}
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		c := QuadraticBezier{P0: NewPoint(0, 0), P1: NewPoint(50, 100), P2: NewPoint(100, 0)}
+		left, right := c.Split(0.5)
+		mid := c.Evaluate(0.5)
+		if !left.P2.Equals(mid) || !right.P0.Equals(mid) {
+			t.Errorf("split halves should meet at Evaluate(0.5), got left.P2=%+v right.P0=%+v mid=%+v", left.P2, right.P0, mid)
+		}
+	})
+
+	t.Run("ArcLengthStraightLine", func(t *testing.T) {
+		c := QuadraticBezier{P0: NewPoint(0, 0), P1: NewPoint(50, 50), P2: NewPoint(100, 100)}
+		got := c.ArcLength(1e-6)
+		want := c.P0.Distance(c.P2)
+		if math.Abs(float64(got-want)) > 1e-3 {
+			t.Errorf("ArcLength() = %f, want %f", got, want)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+//go:build !cgo || mock
+// +build !cgo mock
+
+package micropdf
+
+// Buffer wraps a native growable byte buffer — the type rendered pixmaps,
+// extracted text, and other PDF content streams are handed back through.
+type Buffer struct {
+	handle uintptr
+	codec  Codec
+}
+
+// Codec identifies the compression format a Buffer's native storage is
+// currently holding.
+type Codec int
+
+const (
+	// CodecNone means the buffer holds uncompressed bytes.
+	CodecNone Codec = iota
+	// CodecSnappy means the buffer holds snappy-compressed bytes.
+	CodecSnappy
+	// CodecZstd means the buffer holds zstd-compressed bytes.
+	CodecZstd
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecNone:
+		return "none"
+	case CodecSnappy:
+		return "snappy"
+	case CodecZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+// codecSnappyID and codecZstdID are the wire values passed down to the
+// native bufferCompress/bufferDecompress functions.
+const (
+	codecSnappyID = 1
+	codecZstdID   = 2
+)
+
+// NewBuffer creates an empty Buffer with capacity bytes pre-reserved.
+// Returns nil if the native allocation fails.
+func NewBuffer(capacity int) *Buffer {
+	handle := bufferNew(capacity)
+	if handle == 0 {
+		return nil
+	}
+	return &Buffer{handle: handle}
+}
+
+// NewBufferFromBytes creates a Buffer holding a copy of data.
+// Returns nil if the native allocation fails.
+func NewBufferFromBytes(data []byte) *Buffer {
+	handle := bufferFromData(data)
+	if handle == 0 {
+		return nil
+	}
+	return &Buffer{handle: handle}
+}
+
+// NewBufferFromString creates a Buffer holding a copy of s.
+// Returns nil if the native allocation fails.
+func NewBufferFromString(s string) *Buffer {
+	return NewBufferFromBytes([]byte(s))
+}
+
+// Free releases b's native buffer. Safe to call on a nil Buffer or one
+// that's already been freed.
+func (b *Buffer) Free() {
+	if b == nil || b.handle == 0 {
+		return
+	}
+	bufferFree(b.handle)
+	b.handle = 0
+}
+
+// Len returns the number of bytes currently in b. If b holds compressed
+// data, this is the decompressed length, matching Bytes/String.
+func (b *Buffer) Len() int {
+	if b == nil || b.handle == 0 {
+		return 0
+	}
+	if b.codec == CodecNone {
+		return bufferLen(b.handle)
+	}
+	return len(b.Bytes())
+}
+
+// IsEmpty reports whether b has no data.
+func (b *Buffer) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+// Bytes returns a copy of b's contents, transparently decompressing first
+// if b currently holds a compressed payload.
+func (b *Buffer) Bytes() []byte {
+	if b == nil || b.handle == 0 {
+		return nil
+	}
+	if b.codec == CodecNone {
+		return bufferData(b.handle)
+	}
+
+	// Decompress a scratch copy rather than the live native buffer, so a
+	// read-only Bytes() call can't leave b's storage decoded underneath a
+	// concurrent caller still expecting it compressed.
+	scratch := NewBufferFromBytes(bufferData(b.handle))
+	if scratch == nil {
+		return nil
+	}
+	defer scratch.Free()
+	if err := scratch.decompress(b.codec); err != nil {
+		return nil
+	}
+	return bufferData(scratch.handle)
+}
+
+// String returns b's contents as a string, transparently decompressing
+// first if needed.
+func (b *Buffer) String() string {
+	return string(b.Bytes())
+}
+
+// Append appends data to b. Appending to a compressed Buffer is not
+// supported — decompress it first.
+func (b *Buffer) Append(data []byte) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec != CodecNone {
+		return ErrUnsupported("buffer: Append on a compressed buffer; DecompressSnappy/DecompressZstd first")
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if code := bufferAppend(b.handle, data); code != 0 {
+		return ErrSystem("buffer: native append failed", nil)
+	}
+	return nil
+}
+
+// Clone returns an independent Buffer holding a copy of b's current
+// (decompressed) contents.
+func (b *Buffer) Clone() *Buffer {
+	if b == nil || b.handle == 0 {
+		return NewBuffer(0)
+	}
+	return NewBufferFromBytes(b.Bytes())
+}
+
+// Codec returns the compression format b's native storage currently holds.
+func (b *Buffer) Codec() Codec {
+	if b == nil {
+		return CodecNone
+	}
+	return b.codec
+}
+
+// CompressSnappy compresses b's contents in place with snappy, operating
+// directly on the native buffer to avoid a Go<->C copy round trip.
+func (b *Buffer) CompressSnappy() error {
+	return b.compress(CodecSnappy, 0)
+}
+
+// DecompressSnappy reverses CompressSnappy, restoring b's native buffer to
+// plain bytes in place.
+func (b *Buffer) DecompressSnappy() error {
+	return b.decompress(CodecSnappy)
+}
+
+// CompressZstd compresses b's contents in place with zstd at the given
+// level, operating directly on the native buffer to avoid a Go<->C copy
+// round trip.
+func (b *Buffer) CompressZstd(level int) error {
+	return b.compress(CodecZstd, level)
+}
+
+// DecompressZstd reverses CompressZstd, restoring b's native buffer to
+// plain bytes in place.
+func (b *Buffer) DecompressZstd() error {
+	return b.decompress(CodecZstd)
+}
+
+func (b *Buffer) compress(codec Codec, level int) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec == codec {
+		return nil
+	}
+	if b.codec != CodecNone {
+		return ErrUnsupported("buffer: already compressed with a different codec")
+	}
+
+	if code := bufferCompress(b.handle, codecWireID(codec), level); code != 0 {
+		return ErrSystem("buffer: native compress failed", nil)
+	}
+	b.codec = codec
+	return nil
+}
+
+func (b *Buffer) decompress(codec Codec) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec == CodecNone {
+		return nil
+	}
+	if b.codec != codec {
+		return ErrArgument("buffer: codec mismatch in decompress")
+	}
+
+	if code := bufferDecompress(b.handle, codecWireID(codec)); code != 0 {
+		return ErrSystem("buffer: native decompress failed", nil)
+	}
+	b.codec = CodecNone
+	return nil
+}
+
+func codecWireID(codec Codec) int {
+	switch codec {
+	case CodecSnappy:
+		return codecSnappyID
+	case CodecZstd:
+		return codecZstdID
+	default:
+		return 0
+	}
+}
+
+// RoundTripCompress compresses b with codec, then immediately decompresses
+// it again and reports whether the result matches the original bytes. It
+// exists to exercise the native compress/decompress bridge end to end —
+// used by the benchmark and test suites to catch regressions in that
+// bridge rather than to be part of normal Buffer usage.
+func RoundTripCompress(b *Buffer, codec Codec) (ok bool, err error) {
+	original := b.Bytes()
+
+	switch codec {
+	case CodecSnappy:
+		if err := b.CompressSnappy(); err != nil {
+			return false, err
+		}
+		if err := b.DecompressSnappy(); err != nil {
+			return false, err
+		}
+	case CodecZstd:
+		if err := b.CompressZstd(0); err != nil {
+			return false, err
+		}
+		if err := b.DecompressZstd(); err != nil {
+			return false, err
+		}
+	default:
+		return false, ErrUnsupported("buffer: unknown codec in RoundTripCompress")
+	}
+
+	roundTripped := b.Bytes()
+	if len(roundTripped) != len(original) {
+		return false, nil
+	}
+	for i := range original {
+		if roundTripped[i] != original[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
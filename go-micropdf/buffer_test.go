@@ -0,0 +1,183 @@
+package micropdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	t.Run("NewBuffer", func(t *testing.T) {
+		buf := NewBuffer(1024)
+		if buf == nil {
+			t.Fatal("expected non-nil buffer")
+		}
+		defer buf.Free()
+
+		if buf.Len() != 0 {
+			t.Errorf("expected length 0, got %d", buf.Len())
+		}
+		if !buf.IsEmpty() {
+			t.Error("expected buffer to be empty")
+		}
+	})
+
+	t.Run("FromBytes", func(t *testing.T) {
+		data := []byte("Hello, World!")
+		buf := NewBufferFromBytes(data)
+		if buf == nil {
+			t.Fatal("expected non-nil buffer")
+		}
+		defer buf.Free()
+
+		if buf.Len() != len(data) {
+			t.Errorf("expected length %d, got %d", len(data), buf.Len())
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("data mismatch")
+		}
+	})
+
+	t.Run("FromString", func(t *testing.T) {
+		s := "Hello, MicroPDF!"
+		buf := NewBufferFromString(s)
+		if buf == nil {
+			t.Fatal("expected non-nil buffer")
+		}
+		defer buf.Free()
+
+		if buf.String() != s {
+			t.Errorf("expected %q, got %q", s, buf.String())
+		}
+	})
+
+	t.Run("Append", func(t *testing.T) {
+		buf := NewBuffer(0)
+		if buf == nil {
+			t.Fatal("expected non-nil buffer")
+		}
+		defer buf.Free()
+
+		if err := buf.Append([]byte("Hello")); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+		if err := buf.Append([]byte(", World!")); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+
+		if buf.String() != "Hello, World!" {
+			t.Errorf("expected %q, got %q", "Hello, World!", buf.String())
+		}
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		original := NewBufferFromString("Original")
+		defer original.Free()
+
+		cloned := original.Clone()
+		if cloned == nil {
+			t.Fatal("clone returned nil")
+		}
+		defer cloned.Free()
+
+		if cloned.String() != original.String() {
+			t.Error("clone data mismatch")
+		}
+
+		original.Append([]byte(" Modified"))
+		if cloned.String() == original.String() {
+			t.Error("clone should be independent")
+		}
+	})
+
+	t.Run("NilBuffer", func(t *testing.T) {
+		var buf *Buffer = nil
+		if buf.Len() != 0 {
+			t.Error("nil buffer should have length 0")
+		}
+		if !buf.IsEmpty() {
+			t.Error("nil buffer should be empty")
+		}
+		if buf.Bytes() != nil {
+			t.Error("nil buffer bytes should be nil")
+		}
+	})
+}
+
+func TestBufferCompression(t *testing.T) {
+	data := []byte("some reasonably compressible payload, some reasonably compressible payload")
+
+	t.Run("SnappyRoundTrip", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if buf.Codec() != CodecSnappy {
+			t.Errorf("expected codec %v, got %v", CodecSnappy, buf.Codec())
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Error("Bytes() did not transparently decompress to the original payload")
+		}
+
+		if err := buf.DecompressSnappy(); err != nil {
+			t.Fatalf("DecompressSnappy: %v", err)
+		}
+		if buf.Codec() != CodecNone {
+			t.Errorf("expected codec %v after decompress, got %v", CodecNone, buf.Codec())
+		}
+	})
+
+	t.Run("ZstdRoundTrip", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressZstd(5); err != nil {
+			t.Fatalf("CompressZstd: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Error("Bytes() did not transparently decompress to the original payload")
+		}
+		if err := buf.DecompressZstd(); err != nil {
+			t.Fatalf("DecompressZstd: %v", err)
+		}
+	})
+
+	t.Run("RoundTripCompressHelper", func(t *testing.T) {
+		for _, codec := range []Codec{CodecSnappy, CodecZstd} {
+			buf := NewBufferFromBytes(data)
+			ok, err := RoundTripCompress(buf, codec)
+			buf.Free()
+			if err != nil {
+				t.Fatalf("codec %v: RoundTripCompress error: %v", codec, err)
+			}
+			if !ok {
+				t.Errorf("codec %v: round trip did not reproduce the original bytes", codec)
+			}
+		}
+	})
+
+	t.Run("AppendToCompressedBufferFails", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if err := buf.Append([]byte("more")); err == nil {
+			t.Error("expected Append on a compressed buffer to fail")
+		}
+	})
+
+	t.Run("MismatchedDecompressFails", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if err := buf.DecompressZstd(); err == nil {
+			t.Error("expected DecompressZstd to fail on a snappy-compressed buffer")
+		}
+	})
+}
@@ -0,0 +1,498 @@
+// Package micropdf - Versioned on-disk render cache
+package micropdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+)
+
+// CacheVersion identifies the on-disk record layout a Cache file uses.
+type CacheVersion uint32
+
+const (
+	// CacheV1 stores fixed-size records capped at cacheV1RecordCap bytes
+	// of payload each, the original format.
+	CacheV1 CacheVersion = 1
+	// CacheV2 stores variable-size records with a 32-bit length prefix,
+	// allowing multi-megabyte rendered PNGs. The current default.
+	CacheV2 CacheVersion = 2
+)
+
+func (v CacheVersion) String() string {
+	switch v {
+	case CacheV1:
+		return "V1"
+	case CacheV2:
+		return "V2"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// cacheV1RecordCap is the largest payload CacheV1 can store per entry.
+const cacheV1RecordCap = 64 * 1024
+
+var cacheMagic = [4]byte{'M', 'P', 'C', 'C'}
+
+// CacheOptions configures OpenCache.
+type CacheOptions struct {
+	// Format selects the record layout used when creating a new cache
+	// file. Ignored when opening an existing file, whose format is read
+	// from its header instead. Zero means CacheV2.
+	Format CacheVersion
+}
+
+// CacheKey identifies one cached render/extract output: a document (by
+// content hash, since the same PDF bytes may be opened at different
+// paths), a page number, and the render parameters that affect the
+// output.
+type CacheKey struct {
+	DocHash    string
+	PageNumber int
+	Matrix     Matrix
+	Alpha      bool
+	DPI        int32
+}
+
+// NewCacheKey builds a CacheKey from the parameters that affect a page's
+// rendered or extracted output.
+func NewCacheKey(docHash string, pageNumber int, m Matrix, alpha bool, dpi int32) CacheKey {
+	return CacheKey{DocHash: docHash, PageNumber: pageNumber, Matrix: m, Alpha: alpha, DPI: dpi}
+}
+
+func (k CacheKey) hash() [32]byte {
+	var buf bytes.Buffer
+	buf.WriteString(k.DocHash)
+	buf.WriteByte(0)
+	binary.Write(&buf, binary.LittleEndian, int64(k.PageNumber))
+	binary.Write(&buf, binary.LittleEndian, [6]float32{k.Matrix.A, k.Matrix.B, k.Matrix.C, k.Matrix.D, k.Matrix.E, k.Matrix.F})
+	if k.Alpha {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	binary.Write(&buf, binary.LittleEndian, k.DPI)
+	return sha256.Sum256(buf.Bytes())
+}
+
+// cacheEntry locates one record's payload within the cache file.
+type cacheEntry struct {
+	offset int64
+	length uint32
+}
+
+// Cache is a versioned on-disk store for rendered pixmaps, rendered PNGs,
+// and extracted text, keyed by CacheKey. It mirrors the dual-format
+// approach mature embedded-DB back ends use to let the on-disk layout
+// evolve: CacheV1's fixed-size records are simple and mmap-friendly but
+// cap payload size; CacheV2 drops that cap at the cost of needing a
+// length prefix per record.
+type Cache struct {
+	mu     sync.Mutex
+	file   *os.File
+	path   string
+	format CacheVersion
+	index  map[[32]byte]cacheEntry
+}
+
+// OpenCache opens or creates a versioned render cache at path. Opening an
+// existing file reads its format from the header, regardless of
+// opts.Format; creating a new file uses opts.Format (CacheV2 if unset).
+func OpenCache(path string, opts CacheOptions) (*Cache, error) {
+	format := opts.Format
+	if format == 0 {
+		format = CacheV2
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, WrapError(ErrCodeSystem, "cache: failed to open file", err)
+	}
+
+	c := &Cache{file: f, path: path, index: make(map[[32]byte]cacheEntry)}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, WrapError(ErrCodeSystem, "cache: failed to stat file", err)
+	}
+
+	if info.Size() == 0 {
+		c.format = format
+		if err := c.writeHeader(); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return c, nil
+	}
+
+	if err := c.loadHeaderAndIndex(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) writeHeader() error {
+	var buf bytes.Buffer
+	buf.Write(cacheMagic[:])
+	binary.Write(&buf, binary.LittleEndian, uint32(c.format))
+	if _, err := c.file.WriteAt(buf.Bytes(), 0); err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to write header", err)
+	}
+	return nil
+}
+
+// loadHeaderAndIndex reads the header and scans every record to rebuild
+// the in-memory offset index, so Get/Put don't need a separate index file
+// to stay in sync with.
+func (c *Cache) loadHeaderAndIndex() error {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.file, header); err != nil {
+		return ErrFormat("cache: corrupt or truncated header")
+	}
+	if !bytes.Equal(header[:4], cacheMagic[:]) {
+		return ErrFormat("cache: bad magic")
+	}
+	version := CacheVersion(binary.LittleEndian.Uint32(header[4:8]))
+	if version != CacheV1 && version != CacheV2 {
+		return ErrFormat("cache: unsupported cache version")
+	}
+	c.format = version
+
+	offset := int64(8)
+	for {
+		var recHeader [36]byte
+		n, err := io.ReadFull(c.file, recHeader[:])
+		if err == io.EOF {
+			break
+		}
+		if err != nil || n != len(recHeader) {
+			return ErrFormat("cache: truncated record header")
+		}
+
+		var keyHash [32]byte
+		copy(keyHash[:], recHeader[:32])
+		length := binary.LittleEndian.Uint32(recHeader[32:36])
+		dataOffset := offset + int64(len(recHeader))
+
+		c.index[keyHash] = cacheEntry{offset: dataOffset, length: length}
+
+		skip := int64(length)
+		if version == CacheV1 {
+			skip = cacheV1RecordCap
+		}
+		if _, err := c.file.Seek(skip, io.SeekCurrent); err != nil {
+			return WrapError(ErrCodeSystem, "cache: failed to seek past record", err)
+		}
+		offset = dataOffset + skip
+	}
+	return nil
+}
+
+// Get returns the cached bytes for key, or ok=false if key isn't present.
+func (c *Cache) Get(key CacheKey) (data []byte, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.index[key.hash()]
+	if !found {
+		return nil, false, nil
+	}
+
+	data = make([]byte, entry.length)
+	if _, err := c.file.ReadAt(data, entry.offset); err != nil {
+		return nil, false, WrapError(ErrCodeSystem, "cache: failed to read record", err)
+	}
+	return data, true, nil
+}
+
+// Put stores data under key, appending a new record to the cache file.
+// With CacheV1, data larger than cacheV1RecordCap is rejected with
+// ErrCodeLimit rather than silently truncated.
+func (c *Cache) Put(key CacheKey, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.put(key, data, c.format)
+}
+
+func (c *Cache) put(key CacheKey, data []byte, format CacheVersion) error {
+	if format == CacheV1 && len(data) > cacheV1RecordCap {
+		return ErrLimit("cache: record exceeds CacheV1's 64KiB-per-entry cap")
+	}
+
+	end, err := c.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to seek to end", err)
+	}
+
+	keyHash := key.hash()
+	var recHeader [36]byte
+	copy(recHeader[:32], keyHash[:])
+	binary.LittleEndian.PutUint32(recHeader[32:36], uint32(len(data)))
+
+	if _, err := c.file.Write(recHeader[:]); err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to write record header", err)
+	}
+	if _, err := c.file.Write(data); err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to write record payload", err)
+	}
+	if format == CacheV1 {
+		padding := make([]byte, cacheV1RecordCap-len(data))
+		if _, err := c.file.Write(padding); err != nil {
+			return WrapError(ErrCodeSystem, "cache: failed to pad V1 record", err)
+		}
+	}
+
+	c.index[keyHash] = cacheEntry{offset: end + int64(len(recHeader)), length: uint32(len(data))}
+	return nil
+}
+
+// Migrate rewrites every entry into a fresh file at c's path using target's
+// record layout, then swaps it in. Used to move a cache forward from
+// CacheV1 (written by an older binary) to CacheV2, or back down.
+func (c *Cache) Migrate(target CacheVersion) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if target != CacheV1 && target != CacheV2 {
+		return ErrFormat("cache: unsupported target cache version")
+	}
+	if target == c.format {
+		return nil
+	}
+
+	tmpPath := c.path + ".migrate.tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to create migration file", err)
+	}
+
+	newCache := &Cache{file: tmp, path: tmpPath, format: target, index: make(map[[32]byte]cacheEntry)}
+	if err := newCache.writeHeader(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	for keyHash, entry := range c.index {
+		data := make([]byte, entry.length)
+		if _, err := c.file.ReadAt(data, entry.offset); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return WrapError(ErrCodeSystem, "cache: failed to read record during migration", err)
+		}
+		if target == CacheV1 && len(data) > cacheV1RecordCap {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return ErrLimit("cache: record exceeds CacheV1's 64KiB-per-entry cap during migration")
+		}
+
+		end, err := tmp.Seek(0, io.SeekEnd)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return WrapError(ErrCodeSystem, "cache: failed to seek during migration", err)
+		}
+		var recHeader [36]byte
+		copy(recHeader[:32], keyHash[:])
+		binary.LittleEndian.PutUint32(recHeader[32:36], uint32(len(data)))
+		if _, err := tmp.Write(recHeader[:]); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return WrapError(ErrCodeSystem, "cache: failed to write record header during migration", err)
+		}
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return WrapError(ErrCodeSystem, "cache: failed to write record payload during migration", err)
+		}
+		if target == CacheV1 {
+			padding := make([]byte, cacheV1RecordCap-len(data))
+			if _, err := tmp.Write(padding); err != nil {
+				tmp.Close()
+				os.Remove(tmpPath)
+				return WrapError(ErrCodeSystem, "cache: failed to pad V1 record during migration", err)
+			}
+		}
+		newCache.index[keyHash] = cacheEntry{offset: end + int64(len(recHeader)), length: entry.length}
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return WrapError(ErrCodeSystem, "cache: failed to close migration file", err)
+	}
+	if err := c.file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return WrapError(ErrCodeSystem, "cache: failed to close original file", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to replace cache file", err)
+	}
+
+	reopened, err := os.OpenFile(c.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to reopen migrated file", err)
+	}
+	c.file = reopened
+	c.format = target
+	c.index = newCache.index
+	return nil
+}
+
+// Format reports the record layout c is currently using for new writes.
+func (c *Cache) Format() CacheVersion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.format
+}
+
+// Close releases the cache's underlying file handle.
+func (c *Cache) Close() error {
+	if c == nil || c.file == nil {
+		return nil
+	}
+	if err := c.file.Close(); err != nil {
+		return WrapError(ErrCodeSystem, "cache: failed to close file", err)
+	}
+	return nil
+}
+
+// ctxCaches associates a Context's native handle with the Cache UseCache
+// registered for it, mirroring the handle-keyed storage pattern used
+// elsewhere in this package for per-context state.
+var (
+	ctxCacheMu sync.Mutex
+	ctxCaches  = make(map[uintptr]*Cache)
+)
+
+// UseCache registers c so that subsequent cache-aware render/extract calls
+// made through ctx transparently check it before doing native work, and
+// populate it afterward. Pass nil to stop using a cache.
+func (ctx *Context) UseCache(c *Cache) {
+	if ctx == nil || ctx.Handle() == 0 {
+		return
+	}
+	ctxCacheMu.Lock()
+	if c == nil {
+		delete(ctxCaches, ctx.Handle())
+	} else {
+		ctxCaches[ctx.Handle()] = c
+	}
+	ctxCacheMu.Unlock()
+}
+
+func cacheForContext(ctx *Context) *Cache {
+	if ctx == nil || ctx.Handle() == 0 {
+		return nil
+	}
+	ctxCacheMu.Lock()
+	c := ctxCaches[ctx.Handle()]
+	ctxCacheMu.Unlock()
+	return c
+}
+
+// ExtractTextCached behaves like page.ExtractText, but checks ctx's
+// registered cache first and populates it on a miss.
+func ExtractTextCached(ctx *Context, page *Page, key CacheKey) (string, error) {
+	c := cacheForContext(ctx)
+	if c == nil {
+		return page.ExtractText()
+	}
+
+	if data, ok, err := c.Get(key); err != nil {
+		return "", err
+	} else if ok {
+		return string(data), nil
+	}
+
+	text, err := page.ExtractText()
+	if err != nil {
+		return "", err
+	}
+	if err := c.Put(key, []byte(text)); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// RenderToPNGBoundedCached behaves like page.RenderToPNGBounded, but checks
+// ctx's registered cache first and populates it on a miss. The DPI actually
+// used is stored alongside the PNG bytes so a cache hit reports the same
+// value a cold render would have.
+func RenderToPNGBoundedCached(ctx *Context, page *Page, key CacheKey, opts RenderOptions) ([]byte, int32, error) {
+	c := cacheForContext(ctx)
+	if c == nil {
+		return page.RenderToPNGBounded(opts)
+	}
+
+	if data, ok, err := c.Get(key); err != nil {
+		return nil, 0, err
+	} else if ok {
+		if len(data) < 4 {
+			return nil, 0, ErrFormat("cache: corrupt PNG cache record")
+		}
+		dpi := int32(binary.LittleEndian.Uint32(data[:4]))
+		return data[4:], dpi, nil
+	}
+
+	png, dpi, err := page.RenderToPNGBounded(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	payload := make([]byte, 4+len(png))
+	binary.LittleEndian.PutUint32(payload[:4], uint32(dpi))
+	copy(payload[4:], png)
+	if err := c.Put(key, payload); err != nil {
+		return nil, 0, err
+	}
+	return png, dpi, nil
+}
+
+// RenderToPixmapCached behaves like page.RenderToPixmap, but checks ctx's
+// registered cache first and populates it on a miss. A rendered Pixmap is
+// a live native-memory handle that can't be persisted across process
+// runs, so the cache stores (and a hit returns) the PNG encoding of the
+// pixmap rendered with m, made by the same encoder RenderToPNGBounded
+// uses, rather than reconstructing a *Pixmap from disk.
+func RenderToPixmapCached(ctx *Context, page *Page, key CacheKey, m Matrix, alpha bool) ([]byte, error) {
+	c := cacheForContext(ctx)
+	if c == nil {
+		pix, err := page.RenderToPixmap(m, alpha)
+		if err != nil {
+			return nil, err
+		}
+		defer pix.Drop()
+		var buf bytes.Buffer
+		if err := encodePixmapPNG(&buf, pix); err != nil {
+			return nil, ErrRenderFailed
+		}
+		return buf.Bytes(), nil
+	}
+
+	if data, ok, err := c.Get(key); err != nil {
+		return nil, err
+	} else if ok {
+		return data, nil
+	}
+
+	pix, err := page.RenderToPixmap(m, alpha)
+	if err != nil {
+		return nil, err
+	}
+	defer pix.Drop()
+
+	var buf bytes.Buffer
+	if err := encodePixmapPNG(&buf, pix); err != nil {
+		return nil, ErrRenderFailed
+	}
+	if err := c.Put(key, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,179 @@
+package micropdf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testCacheKey(page int) CacheKey {
+	return NewCacheKey("doc-hash-abc123", page, MatrixScale(2, 2), false, 150)
+}
+
+func TestCachePutGetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	c, err := OpenCache(path, CacheOptions{})
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	key := testCacheKey(0)
+	want := []byte("rendered page bytes")
+
+	if _, ok, err := c.Get(key); err != nil || ok {
+		t.Fatalf("expected miss on empty cache, got ok=%v err=%v", ok, err)
+	}
+
+	if err := c.Put(key, want); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok, err := c.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected hit after Put")
+	}
+	if string(got) != string(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCacheDefaultsToV2(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	c, err := OpenCache(path, CacheOptions{})
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	if c.Format() != CacheV2 {
+		t.Errorf("expected default format CacheV2, got %v", c.Format())
+	}
+}
+
+func TestCacheV1RejectsOversizedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	c, err := OpenCache(path, CacheOptions{Format: CacheV1})
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	oversized := make([]byte, cacheV1RecordCap+1)
+	err = c.Put(testCacheKey(0), oversized)
+	if err == nil {
+		t.Fatal("expected an error for an oversized CacheV1 record")
+	}
+	if micropdfErr, ok := err.(*MicroPDFError); !ok || micropdfErr.Code != ErrCodeLimit {
+		t.Errorf("expected ErrCodeLimit, got %v", err)
+	}
+}
+
+func TestCacheReopenDetectsExistingFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+
+	c1, err := OpenCache(path, CacheOptions{Format: CacheV1})
+	if err != nil {
+		t.Fatalf("OpenCache (create): %v", err)
+	}
+	if err := c1.Put(testCacheKey(0), []byte("hello")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := c1.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopen with Format: CacheV2 in opts — the on-disk V1 header must win.
+	c2, err := OpenCache(path, CacheOptions{Format: CacheV2})
+	if err != nil {
+		t.Fatalf("OpenCache (reopen): %v", err)
+	}
+	defer c2.Close()
+
+	if c2.Format() != CacheV1 {
+		t.Errorf("expected reopened cache to report CacheV1 from its header, got %v", c2.Format())
+	}
+	data, ok, err := c2.Get(testCacheKey(0))
+	if err != nil || !ok {
+		t.Fatalf("expected the V1 entry written before reopening to survive, ok=%v err=%v", ok, err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("got %q, want %q", data, "hello")
+	}
+}
+
+func TestCacheRejectsCorruptHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	if err := os.WriteFile(path, []byte("not a cache file"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	_, err := OpenCache(path, CacheOptions{})
+	if err == nil {
+		t.Fatal("expected an error opening a file with a bad magic header")
+	}
+	if micropdfErr, ok := err.(*MicroPDFError); !ok || micropdfErr.Code != ErrCodeFormat {
+		t.Errorf("expected ErrCodeFormat, got %v", err)
+	}
+}
+
+func TestCacheMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	c, err := OpenCache(path, CacheOptions{Format: CacheV1})
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	keys := []CacheKey{testCacheKey(0), testCacheKey(1), testCacheKey(2)}
+	for i, key := range keys {
+		if err := c.Put(key, []byte{byte(i), byte(i + 1)}); err != nil {
+			t.Fatalf("Put %d: %v", i, err)
+		}
+	}
+
+	if err := c.Migrate(CacheV2); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if c.Format() != CacheV2 {
+		t.Errorf("expected format CacheV2 after migration, got %v", c.Format())
+	}
+
+	for i, key := range keys {
+		data, ok, err := c.Get(key)
+		if err != nil || !ok {
+			t.Fatalf("key %d missing after migration: ok=%v err=%v", i, ok, err)
+		}
+		if len(data) != 2 || data[0] != byte(i) || data[1] != byte(i+1) {
+			t.Errorf("key %d: got %v, want [%d %d]", i, data, i, i+1)
+		}
+	}
+
+	// A big payload written only after migrating to V2 should still
+	// succeed, since V2 has no 64KiB cap.
+	big := make([]byte, cacheV1RecordCap+1024)
+	if err := c.Put(testCacheKey(3), big); err != nil {
+		t.Errorf("Put of oversized-for-V1 record after migrating to V2: %v", err)
+	}
+}
+
+func TestCacheMigrateToV1RejectsOversizedExistingRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.cache")
+	c, err := OpenCache(path, CacheOptions{Format: CacheV2})
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+	defer c.Close()
+
+	big := make([]byte, cacheV1RecordCap+1024)
+	if err := c.Put(testCacheKey(0), big); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := c.Migrate(CacheV1); err == nil {
+		t.Fatal("expected migrating an oversized record down to CacheV1 to fail")
+	}
+}
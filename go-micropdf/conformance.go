@@ -0,0 +1,115 @@
+// Package micropdf - PDF 2.0 (ISO 32000-2) version and conformance reporting
+package micropdf
+
+// ConformanceFeature identifies a single PDF 2.0 (ISO 32000-2) behavior
+// that Document.Conformance checks for.
+type ConformanceFeature string
+
+const (
+	// FeatureAssociatedFiles is the AF (associated files) mechanism for
+	// attaching arbitrary files to the document, a page, or an object.
+	FeatureAssociatedFiles ConformanceFeature = "af-associated-files"
+	// FeatureUnencryptedWrapper is the unencrypted-wrapper document
+	// structure used to ship a viewable cover page alongside an
+	// encrypted payload.
+	FeatureUnencryptedWrapper ConformanceFeature = "unencrypted-wrapper"
+	// FeaturePageOutputIntents is per-page (rather than document-level
+	// only) OutputIntents for color reproduction.
+	FeaturePageOutputIntents ConformanceFeature = "page-output-intents"
+	// FeatureAnnotationTypes20 covers annotation subtypes introduced in
+	// PDF 2.0, such as Projection and RichMedia replacements.
+	FeatureAnnotationTypes20 ConformanceFeature = "annotation-types-2.0"
+	// FeatureXFARemoved indicates the document relies on XFA forms,
+	// which PDF 2.0 removed from the specification entirely.
+	FeatureXFARemoved ConformanceFeature = "xfa-removed"
+	// FeatureUnicodePasswords is the UTF-8 password encoding PDF 2.0
+	// introduced in place of PDFDocEncoding-only passwords.
+	FeatureUnicodePasswords ConformanceFeature = "unicode-passwords"
+)
+
+// conformanceFeatures enumerates every feature Document.Conformance
+// checks, in the order they appear in a returned ConformanceReport.
+var conformanceFeatures = []ConformanceFeature{
+	FeatureAssociatedFiles,
+	FeatureUnencryptedWrapper,
+	FeaturePageOutputIntents,
+	FeatureAnnotationTypes20,
+	FeatureXFARemoved,
+	FeatureUnicodePasswords,
+}
+
+// FeatureStatus reports whether a document uses, and whether this
+// library can fully process, a single PDF 2.0 feature.
+type FeatureStatus struct {
+	// Feature is the feature's stable ID.
+	Feature ConformanceFeature
+	// Present is true if the document uses this feature at all.
+	Present bool
+	// Unsupported is true if Present and the underlying engine cannot
+	// fully process it. ErrUnsupportedFeature builds the matching error
+	// for Feature; no operation in this package returns it on its own.
+	Unsupported bool
+}
+
+// ConformanceReport summarizes which PDF 2.0 (ISO 32000-2) features a
+// document uses, and which of those this library can't fully process.
+// Unsupported features are listed explicitly by feature ID rather than
+// silently ignored, so callers can decide whether to reject the
+// document or proceed with degraded handling.
+type ConformanceReport struct {
+	Features []FeatureStatus
+}
+
+// Unsupported returns the feature IDs r marked both Present and
+// Unsupported.
+func (r ConformanceReport) Unsupported() []ConformanceFeature {
+	var out []ConformanceFeature
+	for _, f := range r.Features {
+		if f.Present && f.Unsupported {
+			out = append(out, f.Feature)
+		}
+	}
+	return out
+}
+
+// Version returns doc's declared PDF version as (major, minor), e.g.
+// (2, 0) for a PDF 2.0 document or (1, 7) for PDF 1.7.
+func (doc *Document) Version() (major, minor int, err error) {
+	if doc == nil || !doc.IsValid() {
+		return 0, 0, ErrInvalidHandle
+	}
+	major, minor, ok := documentVersionNative(doc.Handle())
+	if !ok {
+		return 0, 0, ErrFailedToLoad
+	}
+	return major, minor, nil
+}
+
+// Conformance inspects doc for the PDF 2.0 (ISO 32000-2) features listed
+// in conformanceFeatures and reports which are present and which, if
+// present, this library cannot fully process.
+func (doc *Document) Conformance() (ConformanceReport, error) {
+	if doc == nil || !doc.IsValid() {
+		return ConformanceReport{}, ErrInvalidHandle
+	}
+
+	report := ConformanceReport{Features: make([]FeatureStatus, 0, len(conformanceFeatures))}
+	for _, feature := range conformanceFeatures {
+		present, unsupported := documentFeatureNative(doc.Handle(), string(feature))
+		report.Features = append(report.Features, FeatureStatus{
+			Feature:     feature,
+			Present:     present,
+			Unsupported: unsupported,
+		})
+	}
+	return report, nil
+}
+
+// ErrUnsupportedFeature returns an ErrCodeUnsupported MicroPDFError
+// tagged with featureID as its Message, for use when an operation
+// requires PDF 2.0 behavior this library can't fully process. Callers
+// can match on the feature ID for actionable diagnostics instead of a
+// generic "failed to open document" error.
+func ErrUnsupportedFeature(featureID ConformanceFeature) *MicroPDFError {
+	return NewError(ErrCodeUnsupported, string(featureID))
+}
@@ -0,0 +1,40 @@
+package micropdf
+
+import "testing"
+
+func TestConformanceReportUnsupported(t *testing.T) {
+	report := ConformanceReport{Features: []FeatureStatus{
+		{Feature: FeatureAssociatedFiles, Present: true, Unsupported: false},
+		{Feature: FeatureXFARemoved, Present: true, Unsupported: true},
+		{Feature: FeatureUnicodePasswords, Present: false, Unsupported: true},
+	}}
+
+	got := report.Unsupported()
+	if len(got) != 1 || got[0] != FeatureXFARemoved {
+		t.Errorf("Expected only %v, got %v", FeatureXFARemoved, got)
+	}
+}
+
+func TestErrUnsupportedFeature(t *testing.T) {
+	err := ErrUnsupportedFeature(FeatureXFARemoved)
+	if err.Code != ErrCodeUnsupported {
+		t.Errorf("Expected ErrCodeUnsupported, got %v", err.Code)
+	}
+	if err.Message != string(FeatureXFARemoved) {
+		t.Errorf("Expected message %q, got %q", FeatureXFARemoved, err.Message)
+	}
+}
+
+func TestDocumentVersionNilDocument(t *testing.T) {
+	var doc *Document
+	if _, _, err := doc.Version(); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
+
+func TestDocumentConformanceNilDocument(t *testing.T) {
+	var doc *Document
+	if _, err := doc.Conformance(); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
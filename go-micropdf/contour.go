@@ -0,0 +1,395 @@
+// Package micropdf - Contour: a first-class 2D vector path built on top of
+// Point/Rect/Matrix/Quad, for clipping regions, annotation shapes, vector
+// overlays, and hit-testing beyond axis-aligned rects.
+//
+// This is distinct from the Path type in path_batch_mock.go, which is a
+// command recorder bound to a native drawing context. Contour has no
+// native backing at all — it's pure geometry, so unlike Path it needs no
+// mock/cgo split to be usable under any build.
+package micropdf
+
+import "math"
+
+// FillRule selects how Contour.Contains resolves a point where multiple
+// subpaths overlap or a subpath self-intersects, mirroring the PDF "f"
+// (nonzero winding) vs "f*" (even-odd) fill operators.
+type FillRule int
+
+const (
+	// FillRuleNonZero is inside wherever the winding number is nonzero.
+	FillRuleNonZero FillRule = iota
+	// FillRuleEvenOdd is inside wherever a ray from the point crosses an
+	// odd number of edges.
+	FillRuleEvenOdd
+)
+
+func (r FillRule) String() string {
+	switch r {
+	case FillRuleNonZero:
+		return "NonZero"
+	case FillRuleEvenOdd:
+		return "EvenOdd"
+	default:
+		return "Unknown"
+	}
+}
+
+type contourSegKind int
+
+const (
+	contourSegLine contourSegKind = iota
+	contourSegQuad
+	contourSegCubic
+	contourSegClose
+)
+
+// contourSegment is one drawing operation within a subpath. Every kind
+// stores its destination point in to; contourSegQuad additionally uses
+// c1, and contourSegCubic uses both c1 and c2.
+type contourSegment struct {
+	kind   contourSegKind
+	c1, c2 Point
+	to     Point
+}
+
+// contourSubpath is one MoveTo-started run of segments.
+type contourSubpath struct {
+	start    Point
+	segments []contourSegment
+}
+
+// Contour is a 2D vector path composed of subpaths of line and Bezier
+// segments.
+type Contour struct {
+	FillRule FillRule
+	subpaths []contourSubpath
+}
+
+// NewContour returns an empty Contour using the nonzero winding rule.
+func NewContour() *Contour {
+	return &Contour{}
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (c *Contour) MoveTo(x, y float32) *Contour {
+	c.subpaths = append(c.subpaths, contourSubpath{start: Point{X: x, Y: y}})
+	return c
+}
+
+// currentSubpath returns the subpath new segments are appended to,
+// implicitly starting one at the origin if MoveTo hasn't been called yet.
+func (c *Contour) currentSubpath() *contourSubpath {
+	if len(c.subpaths) == 0 {
+		c.subpaths = append(c.subpaths, contourSubpath{})
+	}
+	return &c.subpaths[len(c.subpaths)-1]
+}
+
+// LineTo adds a straight line from the current point to (x, y).
+func (c *Contour) LineTo(x, y float32) *Contour {
+	sp := c.currentSubpath()
+	sp.segments = append(sp.segments, contourSegment{kind: contourSegLine, to: Point{X: x, Y: y}})
+	return c
+}
+
+// QuadTo adds a quadratic Bezier curve from the current point, through
+// control point (cx, cy), to (x, y).
+func (c *Contour) QuadTo(cx, cy, x, y float32) *Contour {
+	sp := c.currentSubpath()
+	sp.segments = append(sp.segments, contourSegment{kind: contourSegQuad, c1: Point{X: cx, Y: cy}, to: Point{X: x, Y: y}})
+	return c
+}
+
+// CurveTo adds a cubic Bezier curve from the current point, through
+// control points (x1, y1) and (x2, y2), to (x3, y3).
+func (c *Contour) CurveTo(x1, y1, x2, y2, x3, y3 float32) *Contour {
+	sp := c.currentSubpath()
+	sp.segments = append(sp.segments, contourSegment{
+		kind: contourSegCubic,
+		c1:   Point{X: x1, Y: y1},
+		c2:   Point{X: x2, Y: y2},
+		to:   Point{X: x3, Y: y3},
+	})
+	return c
+}
+
+// ClosePath closes the current subpath back to its starting point.
+func (c *Contour) ClosePath() *Contour {
+	sp := c.currentSubpath()
+	sp.segments = append(sp.segments, contourSegment{kind: contourSegClose})
+	return c
+}
+
+// Transform returns a new Contour with every point of c mapped through m;
+// c itself is left unmodified.
+func (c *Contour) Transform(m Matrix) *Contour {
+	out := &Contour{FillRule: c.FillRule, subpaths: make([]contourSubpath, len(c.subpaths))}
+	for i, sp := range c.subpaths {
+		nsp := contourSubpath{start: sp.start.Transform(m), segments: make([]contourSegment, len(sp.segments))}
+		for j, seg := range sp.segments {
+			nseg := seg
+			switch seg.kind {
+			case contourSegLine:
+				nseg.to = seg.to.Transform(m)
+			case contourSegQuad:
+				nseg.c1 = seg.c1.Transform(m)
+				nseg.to = seg.to.Transform(m)
+			case contourSegCubic:
+				nseg.c1 = seg.c1.Transform(m)
+				nseg.c2 = seg.c2.Transform(m)
+				nseg.to = seg.to.Transform(m)
+			}
+			nsp.segments[j] = nseg
+		}
+		out.subpaths[i] = nsp
+	}
+	return out
+}
+
+// Bounds returns the tight axis-aligned bounding box of c, solving each
+// curve segment's derivative for its extrema (via QuadraticBezier.Bounds /
+// CubicBezier.Bounds) rather than settling for the looser control-point
+// hull.
+func (c *Contour) Bounds() Rect {
+	var r Rect
+	empty := true
+	for _, sp := range c.subpaths {
+		cur := sp.start
+		if empty {
+			r = Rect{X0: cur.X, Y0: cur.Y, X1: cur.X, Y1: cur.Y}
+			empty = false
+		} else {
+			r = r.IncludePoint(cur)
+		}
+		for _, seg := range sp.segments {
+			switch seg.kind {
+			case contourSegLine:
+				r = r.IncludePoint(seg.to)
+				cur = seg.to
+			case contourSegQuad:
+				r = r.Union(QuadraticBezier{P0: cur, P1: seg.c1, P2: seg.to}.Bounds())
+				cur = seg.to
+			case contourSegCubic:
+				r = r.Union(CubicBezier{P0: cur, P1: seg.c1, P2: seg.c2, P3: seg.to}.Bounds())
+				cur = seg.to
+			case contourSegClose:
+				cur = sp.start
+			}
+		}
+	}
+	if empty {
+		return Rect{}
+	}
+	return r
+}
+
+// defaultFlattenTolerance is the tolerance Contains and Intersects flatten
+// with internally; callers wanting a coarser or finer polyline for their
+// own purposes should use FlattenTo directly.
+const defaultFlattenTolerance = 0.1
+
+// maxContourFlattenDepth bounds the de Casteljau subdivision recursion
+// FlattenTo uses, so a pathological curve (near-cusp control points)
+// can't recurse unboundedly — the same role maxArcLengthDepth plays for
+// ArcLength.
+const maxContourFlattenDepth = 16
+
+// FlattenTo approximates c as a sequence of points, recursively
+// subdividing each Bezier segment (de Casteljau) until its control
+// polygon's deviation from the chord drops below tolerance. Points from
+// every subpath are concatenated in order; the result doesn't mark where
+// one subpath ends and the next begins, so callers that need per-subpath
+// boundaries (Contains, Intersects) go through flattenSubpaths instead.
+func (c *Contour) FlattenTo(tolerance float32) []Point {
+	var out []Point
+	for _, poly := range c.flattenSubpaths(tolerance) {
+		out = append(out, poly...)
+	}
+	return out
+}
+
+// flattenSubpaths flattens every subpath independently, each as its own
+// polygon (implicitly closed, whether or not a ClosePath segment is
+// present), for Contains/Intersects' edge-crossing tests.
+func (c *Contour) flattenSubpaths(tolerance float32) [][]Point {
+	polys := make([][]Point, 0, len(c.subpaths))
+	for _, sp := range c.subpaths {
+		poly := []Point{sp.start}
+		cur := sp.start
+		for _, seg := range sp.segments {
+			switch seg.kind {
+			case contourSegLine:
+				poly = append(poly, seg.to)
+				cur = seg.to
+			case contourSegQuad:
+				poly = flattenQuadratic(QuadraticBezier{P0: cur, P1: seg.c1, P2: seg.to}, tolerance, maxContourFlattenDepth, poly)
+				cur = seg.to
+			case contourSegCubic:
+				poly = flattenCubicContour(CubicBezier{P0: cur, P1: seg.c1, P2: seg.c2, P3: seg.to}, tolerance, maxContourFlattenDepth, poly)
+				cur = seg.to
+			case contourSegClose:
+				cur = sp.start
+			}
+		}
+		polys = append(polys, poly)
+	}
+	return polys
+}
+
+func flattenQuadratic(q QuadraticBezier, tol float32, depth int, out []Point) []Point {
+	if depth <= 0 || pointLineDeviation(q.P1, q.P0, q.P2) <= tol {
+		return append(out, q.P2)
+	}
+	a, b := q.Split(0.5)
+	out = flattenQuadratic(a, tol, depth-1, out)
+	return flattenQuadratic(b, tol, depth-1, out)
+}
+
+// flattenCubicContour is contour.go's own de Casteljau flattener,
+// distinct from simd.go's flattenCubic: this one appends onto and
+// returns a []Point (the shape this package's Contour machinery threads
+// through flattenSubpaths), while simd.go's batch variant appends into
+// a caller-owned *[]Point instead.
+func flattenCubicContour(cb CubicBezier, tol float32, depth int, out []Point) []Point {
+	if depth <= 0 || (pointLineDeviation(cb.P1, cb.P0, cb.P3) <= tol && pointLineDeviation(cb.P2, cb.P0, cb.P3) <= tol) {
+		return append(out, cb.P3)
+	}
+	a, b := cb.Split(0.5)
+	out = flattenCubicContour(a, tol, depth-1, out)
+	return flattenCubicContour(b, tol, depth-1, out)
+}
+
+// pointLineDeviation returns the perpendicular distance from p to the
+// infinite line through a and b, falling back to the distance from p to a
+// when a and b coincide.
+func pointLineDeviation(p, a, b Point) float32 {
+	abx, aby := b.X-a.X, b.Y-a.Y
+	lenSq := abx*abx + aby*aby
+	if lenSq == 0 {
+		return p.Distance(a)
+	}
+	cross := abx*(p.Y-a.Y) - aby*(p.X-a.X)
+	return float32(math.Abs(float64(cross)) / math.Sqrt(float64(lenSq)))
+}
+
+// Contains reports whether p lies inside c, resolving overlapping or
+// self-intersecting subpaths per c.FillRule via the standard winding-number
+// ray cast. Every subpath is treated as implicitly closed, matching how
+// the PDF fill operators treat an open subpath.
+func (c *Contour) Contains(p Point) bool {
+	var winding, crossings int
+	for _, poly := range c.flattenSubpaths(defaultFlattenTolerance) {
+		n := len(poly)
+		for i := 0; i < n; i++ {
+			a, b := poly[i], poly[(i+1)%n]
+			if a.Y <= p.Y {
+				if b.Y > p.Y && isLeft(a, b, p) > 0 {
+					winding++
+					crossings++
+				}
+			} else if b.Y <= p.Y && isLeft(a, b, p) < 0 {
+				winding--
+				crossings++
+			}
+		}
+	}
+	if c.FillRule == FillRuleEvenOdd {
+		return crossings%2 == 1
+	}
+	return winding != 0
+}
+
+// isLeft returns >0 if p is left of the directed line a->b, <0 if right,
+// and 0 if p is on it.
+func isLeft(a, b, p Point) float32 {
+	return (b.X-a.X)*(p.Y-a.Y) - (p.X-a.X)*(b.Y-a.Y)
+}
+
+// Intersects reports whether any part of c overlaps r: a flattened vertex
+// of c inside r, a corner of r inside c, or an edge of one crossing an
+// edge of the other.
+func (c *Contour) Intersects(r Rect) bool {
+	bounds := c.Bounds()
+	if bounds.IsEmpty() || r.IsEmpty() || bounds.Intersect(r).IsEmpty() {
+		return false
+	}
+
+	polys := c.flattenSubpaths(defaultFlattenTolerance)
+	for _, poly := range polys {
+		for _, p := range poly {
+			if r.Contains(p) {
+				return true
+			}
+		}
+	}
+
+	corners := [4]Point{{X: r.X0, Y: r.Y0}, {X: r.X1, Y: r.Y0}, {X: r.X1, Y: r.Y1}, {X: r.X0, Y: r.Y1}}
+	for _, p := range corners {
+		if c.Contains(p) {
+			return true
+		}
+	}
+
+	for _, poly := range polys {
+		n := len(poly)
+		for i := 0; i < n; i++ {
+			a, b := poly[i], poly[(i+1)%n]
+			for j := 0; j < 4; j++ {
+				if segmentsIntersect(a, b, corners[j], corners[(j+1)%4]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// segmentsIntersect reports whether segments p1-p2 and p3-p4 cross, via
+// the standard orientation test.
+func segmentsIntersect(p1, p2, p3, p4 Point) bool {
+	d1, d2 := isLeft(p3, p4, p1), isLeft(p3, p4, p2)
+	d3, d4 := isLeft(p1, p2, p3), isLeft(p1, p2, p4)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// PathFromRect returns a closed Contour tracing r's four corners.
+func PathFromRect(r Rect) *Contour {
+	c := NewContour()
+	c.MoveTo(r.X0, r.Y0)
+	c.LineTo(r.X1, r.Y0)
+	c.LineTo(r.X1, r.Y1)
+	c.LineTo(r.X0, r.Y1)
+	c.ClosePath()
+	return c
+}
+
+// PathFromQuad returns a closed Contour tracing q's four corners in
+// UL, UR, LR, LL order.
+func PathFromQuad(q Quad) *Contour {
+	c := NewContour()
+	c.MoveTo(q.UL.X, q.UL.Y)
+	c.LineTo(q.UR.X, q.UR.Y)
+	c.LineTo(q.LR.X, q.LR.Y)
+	c.LineTo(q.LL.X, q.LL.Y)
+	c.ClosePath()
+	return c
+}
+
+// PathFromEllipse returns a closed Contour approximating the ellipse
+// centered at center with radii rx, ry, built from four cubic Beziers via
+// the same circular-arc-to-cubic approximation arc.go uses to express
+// arcs as PDF path curves.
+func PathFromEllipse(center Point, rx, ry float32) *Contour {
+	cubics := ArcsToCubicsBatch([]Arc{{Center: center, Rx: rx, Ry: ry, SweepDegrees: 360}})
+	if len(cubics) == 0 {
+		return NewContour()
+	}
+	c := NewContour()
+	c.MoveTo(cubics[0].P0.X, cubics[0].P0.Y)
+	for _, cb := range cubics {
+		c.CurveTo(cb.P1.X, cb.P1.Y, cb.P2.X, cb.P2.Y, cb.P3.X, cb.P3.Y)
+	}
+	c.ClosePath()
+	return c
+}
@@ -0,0 +1,183 @@
+package micropdf
+
+import "testing"
+
+func TestContourBuilder(t *testing.T) {
+	t.Run("Fluent", func(t *testing.T) {
+		c := NewContour().MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).ClosePath()
+		if len(c.subpaths) != 1 || len(c.subpaths[0].segments) != 3 {
+			t.Fatalf("unexpected subpath/segment count: %+v", c.subpaths)
+		}
+	})
+
+	t.Run("ImplicitMoveTo", func(t *testing.T) {
+		c := NewContour().LineTo(10, 0)
+		if len(c.subpaths) != 1 || c.subpaths[0].start != (Point{}) {
+			t.Fatalf("expected an implicit subpath starting at the origin, got %+v", c.subpaths)
+		}
+	})
+
+	t.Run("MoveToStartsNewSubpath", func(t *testing.T) {
+		c := NewContour().MoveTo(0, 0).LineTo(10, 0).MoveTo(20, 20).LineTo(30, 20)
+		if len(c.subpaths) != 2 {
+			t.Fatalf("expected 2 subpaths, got %d", len(c.subpaths))
+		}
+	})
+}
+
+func TestPathFromRect(t *testing.T) {
+	r := NewRect(0, 0, 10, 20)
+	c := PathFromRect(r)
+
+	b := c.Bounds()
+	if b != r {
+		t.Errorf("Bounds() = %+v, want %+v", b, r)
+	}
+	if !c.Contains(NewPoint(5, 10)) {
+		t.Error("expected center of rect to be contained")
+	}
+	if c.Contains(NewPoint(50, 50)) {
+		t.Error("expected far outside point to not be contained")
+	}
+}
+
+func TestPathFromQuad(t *testing.T) {
+	q := QuadFromRect(NewRect(0, 0, 10, 10))
+	c := PathFromQuad(q)
+
+	if !c.Contains(NewPoint(5, 5)) {
+		t.Error("expected center of quad to be contained")
+	}
+	if c.Contains(NewPoint(-5, -5)) {
+		t.Error("expected outside point to not be contained")
+	}
+}
+
+func TestPathFromEllipse(t *testing.T) {
+	c := PathFromEllipse(NewPoint(0, 0), 10, 5)
+
+	if !c.Contains(NewPoint(0, 0)) {
+		t.Error("expected ellipse center to be contained")
+	}
+	if c.Contains(NewPoint(100, 100)) {
+		t.Error("expected far outside point to not be contained")
+	}
+
+	b := c.Bounds()
+	// The cubic approximation overshoots the true ellipse radius only
+	// negligibly, so generous slack (well beyond fp error) still catches a
+	// badly wrong bounds computation.
+	if b.X0 > -9.9 || b.X1 < 9.9 || b.Y0 > -4.9 || b.Y1 < 4.9 {
+		t.Errorf("Bounds() = %+v, want roughly [-10,-5]-[10,5]", b)
+	}
+}
+
+func TestContourBounds(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		c := NewContour()
+		if b := c.Bounds(); !b.IsEmpty() {
+			t.Errorf("expected an empty Contour to have empty bounds, got %+v", b)
+		}
+	})
+
+	t.Run("CurveBulge", func(t *testing.T) {
+		// A cubic whose control points bulge well outside the endpoint box.
+		c := NewContour().MoveTo(0, 0).CurveTo(0, 200, 100, 200, 100, 0)
+		b := c.Bounds()
+		if b.Y1 <= 100 {
+			t.Errorf("expected bounds to include the curve's bulge, got %+v", b)
+		}
+	})
+}
+
+func TestContourTransform(t *testing.T) {
+	c := PathFromRect(NewRect(0, 0, 10, 10))
+	moved := c.Transform(MatrixTranslate(5, 5))
+
+	if got := moved.Bounds(); got.X0 != 5 || got.Y0 != 5 || got.X1 != 15 || got.Y1 != 15 {
+		t.Errorf("unexpected transformed bounds: %+v", got)
+	}
+	// c itself must be unmodified.
+	if got := c.Bounds(); got.X0 != 0 || got.Y0 != 0 {
+		t.Errorf("Transform mutated the receiver: %+v", got)
+	}
+}
+
+func TestContourFlattenTo(t *testing.T) {
+	c := NewContour().MoveTo(0, 0).CurveTo(0, 50, 100, 50, 100, 0)
+
+	coarse := c.FlattenTo(10)
+	fine := c.FlattenTo(0.01)
+	if len(fine) <= len(coarse) {
+		t.Errorf("expected a tighter tolerance to produce more points: coarse=%d fine=%d", len(coarse), len(fine))
+	}
+
+	// Every flattened point should lie within the curve's x-range.
+	for _, p := range fine {
+		if p.X < -1 || p.X > 101 {
+			t.Errorf("flattened point %+v outside the curve's x-range", p)
+		}
+	}
+}
+
+func TestContourContainsFillRule(t *testing.T) {
+	// Two nested squares wound in the same direction: nonzero winding
+	// treats the hole as filled (winding number 2), even-odd treats it as
+	// a hole (crossed twice).
+	outer := NewContour()
+	outer.MoveTo(0, 0).LineTo(20, 0).LineTo(20, 20).LineTo(0, 20).ClosePath()
+	outer.MoveTo(5, 5).LineTo(15, 5).LineTo(15, 15).LineTo(5, 15).ClosePath()
+
+	center := NewPoint(10, 10)
+
+	outer.FillRule = FillRuleNonZero
+	if !outer.Contains(center) {
+		t.Error("expected nonzero winding rule to fill the inner square")
+	}
+
+	outer.FillRule = FillRuleEvenOdd
+	if outer.Contains(center) {
+		t.Error("expected even-odd rule to treat the inner square as a hole")
+	}
+}
+
+func TestContourIntersects(t *testing.T) {
+	c := PathFromRect(NewRect(0, 0, 10, 10))
+
+	t.Run("Overlapping", func(t *testing.T) {
+		if !c.Intersects(NewRect(5, 5, 15, 15)) {
+			t.Error("expected overlapping rects to intersect")
+		}
+	})
+
+	t.Run("ContourInsideRect", func(t *testing.T) {
+		if !c.Intersects(NewRect(-5, -5, 15, 15)) {
+			t.Error("expected a rect fully containing the contour to intersect")
+		}
+	})
+
+	t.Run("RectInsideContour", func(t *testing.T) {
+		if !c.Intersects(NewRect(2, 2, 8, 8)) {
+			t.Error("expected a rect fully inside the contour to intersect")
+		}
+	})
+
+	t.Run("Disjoint", func(t *testing.T) {
+		if c.Intersects(NewRect(100, 100, 110, 110)) {
+			t.Error("expected far-away rects to not intersect")
+		}
+	})
+}
+
+func TestFillRuleString(t *testing.T) {
+	cases := map[FillRule]string{
+		FillRuleNonZero: "NonZero",
+		FillRuleEvenOdd: "EvenOdd",
+		FillRule(99):    "Unknown",
+	}
+	for rule, want := range cases {
+		if got := rule.String(); got != want {
+			t.Errorf("FillRule(%d).String() = %q, want %q", rule, got, want)
+		}
+	}
+}
@@ -5,8 +5,9 @@ package micropdf
 
 // Cookie represents progress tracking for long-running operations
 type Cookie struct {
-	handle uintptr
-	ctx    *Context
+	handle   uintptr
+	ctx      *Context
+	callback func(progress int)
 }
 
 // NewCookie creates a new progress tracking cookie
@@ -45,3 +46,12 @@ func (c *Cookie) IsAborted() bool {
 func (c *Cookie) Reset() {
 	resetCookie(c.ctx.Handle(), c.handle)
 }
+
+// SetCallback registers fn to be invoked with c's progress (0-100)
+// whenever a cookie-tracked operation (MergePDFsWithCookie,
+// Page.RenderToPNGWithCookie, Page.ExtractTextWithCookie) advances, so
+// callers can drive a progress bar without polling Progress() from
+// another goroutine of their own. Pass nil to stop receiving callbacks.
+func (c *Cookie) SetCallback(fn func(progress int)) {
+	c.callback = fn
+}
@@ -0,0 +1,167 @@
+package micropdf
+
+import (
+	"os"
+	"time"
+)
+
+// progressPollInterval is how often watchProgress samples a Cookie's
+// Progress() while the operation it's tracking is still running.
+const progressPollInterval = 50 * time.Millisecond
+
+// watchProgress polls cookie.Progress() every progressPollInterval and
+// invokes cookie's registered callback whenever the value changes, until
+// done is closed. This is what lets MergePDFsWithCookie and the
+// Page.*WithCookie methods drive a caller's progress bar for the
+// duration of a single blocking call, without the caller needing a
+// polling goroutine of its own.
+func watchProgress(cookie *Cookie, done <-chan struct{}) {
+	if cookie == nil || cookie.callback == nil {
+		return
+	}
+
+	ticker := time.NewTicker(progressPollInterval)
+	defer ticker.Stop()
+
+	last := -1
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if p := cookie.Progress(); p != last {
+				last = p
+				cookie.callback(p)
+			}
+		}
+	}
+}
+
+// MergePDFsWithCookie behaves like MergePDFs, but threads cookie through
+// the merge so callers can track progress and cancel mid-merge. Progress
+// is reported as a weighted percentage of bytes processed across
+// inputPaths (bytes processed / total bytes) rather than a simple file
+// count, so a batch with one huge input and several tiny ones doesn't
+// jump straight to "almost done". cookie is checked between each input
+// file, so a user hitting cancel in a GUI aborts promptly instead of
+// waiting for the whole batch to finish.
+//
+// If cookie is nil, MergePDFsWithCookie behaves exactly like MergePDFs.
+func MergePDFsWithCookie(ctx *Context, inputPaths []string, outputPath string, cookie *Cookie) (int, error) {
+	if ctx == nil || !ctx.IsValid() {
+		return -1, ErrInvalidContext
+	}
+	if len(inputPaths) == 0 {
+		return -1, ErrInvalidArgument
+	}
+	if outputPath == "" {
+		return -1, ErrInvalidArgument
+	}
+
+	if cookie == nil {
+		return MergePDFs(ctx, inputPaths, outputPath)
+	}
+
+	cookie.Reset()
+
+	done := make(chan struct{})
+	go watchProgress(cookie, done)
+	defer close(done)
+
+	weights := inputByteWeights(inputPaths)
+
+	result := mergePDFsWithCookieNative(ctx.Handle(), cookie.handle, inputPaths, weights, outputPath)
+	if result < 0 {
+		if cookie.IsAborted() {
+			return -1, ErrAborted
+		}
+		return -1, ErrFailedToOpen
+	}
+
+	return result, nil
+}
+
+// inputByteWeights returns each path's size in bytes, used to weight
+// merge progress by bytes processed rather than files processed. A path
+// that can't be stat'd gets a weight of 1 byte so it still contributes
+// to the total instead of silently skewing it to zero.
+func inputByteWeights(paths []string) []int64 {
+	weights := make([]int64, len(paths))
+	for i, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || info.Size() == 0 {
+			weights[i] = 1
+			continue
+		}
+		weights[i] = info.Size()
+	}
+	return weights
+}
+
+// RenderToPNGWithCookie behaves like Page.RenderToPNG, but threads
+// cookie's native handle into the render call itself (mirroring
+// MergePDFsWithCookie/mergePDFsWithCookieNative), so a fz_cookie abort
+// request can interrupt an in-progress render instead of only being
+// noticed after RenderToPNG already returned on its own. If cookie is
+// nil, RenderToPNGWithCookie behaves exactly like RenderToPNG.
+func (p *Page) RenderToPNGWithCookie(dpi int32, cookie *Cookie) ([]byte, error) {
+	if cookie == nil {
+		return p.RenderToPNG(dpi)
+	}
+	if p == nil || !p.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+
+	if cookie.IsAborted() {
+		return nil, ErrAborted
+	}
+
+	cookie.Reset()
+
+	done := make(chan struct{})
+	go watchProgress(cookie, done)
+	defer close(done)
+
+	png, err := renderToPNGWithCookieNative(p.Handle(), cookie.handle, dpi)
+	if err != nil {
+		if cookie.IsAborted() {
+			return nil, ErrAborted
+		}
+		return nil, err
+	}
+	return png, nil
+}
+
+// ExtractTextWithCookie behaves like Page.ExtractText, but threads
+// cookie's native handle into the extraction call itself (mirroring
+// RenderToPNGWithCookie and MergePDFsWithCookie), so a fz_cookie abort
+// request can interrupt an in-progress extraction instead of only being
+// noticed after ExtractText already returned on its own. If cookie is
+// nil, ExtractTextWithCookie behaves exactly like ExtractText.
+func (p *Page) ExtractTextWithCookie(cookie *Cookie) (string, error) {
+	if cookie == nil {
+		return p.ExtractText()
+	}
+	if p == nil || !p.IsValid() {
+		return "", ErrInvalidHandle
+	}
+
+	if cookie.IsAborted() {
+		return "", ErrAborted
+	}
+
+	cookie.Reset()
+
+	done := make(chan struct{})
+	go watchProgress(cookie, done)
+	defer close(done)
+
+	text, err := extractTextWithCookieNative(p.Handle(), cookie.handle)
+	if err != nil {
+		if cookie.IsAborted() {
+			return "", ErrAborted
+		}
+		return "", err
+	}
+	return text, nil
+}
@@ -0,0 +1,113 @@
+package micropdf
+
+import (
+	"testing"
+)
+
+func TestMergePDFsWithCookieValidation(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+
+	t.Run("EmptyInputPaths", func(t *testing.T) {
+		_, err := MergePDFsWithCookie(ctx, []string{}, "output.pdf", nil)
+		if err != ErrInvalidArgument {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("EmptyOutputPath", func(t *testing.T) {
+		_, err := MergePDFsWithCookie(ctx, []string{"doc1.pdf"}, "", nil)
+		if err != ErrInvalidArgument {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("NilContext", func(t *testing.T) {
+		_, err := MergePDFsWithCookie(nil, []string{"doc1.pdf"}, "output.pdf", nil)
+		if err != ErrInvalidContext {
+			t.Errorf("Expected ErrInvalidContext, got %v", err)
+		}
+	})
+}
+
+func TestInputByteWeights(t *testing.T) {
+	weights := inputByteWeights([]string{"does-not-exist-a.pdf", "does-not-exist-b.pdf"})
+	if len(weights) != 2 {
+		t.Fatalf("Expected 2 weights, got %d", len(weights))
+	}
+	for i, w := range weights {
+		if w != 1 {
+			t.Errorf("Expected weight 1 for unstattable path %d, got %d", i, w)
+		}
+	}
+}
+
+func TestCookieSetCallback(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+	cookie := NewCookie(ctx)
+	defer cookie.Drop()
+
+	cookie.SetCallback(func(progress int) {})
+	if cookie.callback == nil {
+		t.Fatal("Expected callback to be set")
+	}
+
+	// watchProgress must return promptly once done is closed, rather
+	// than blocking until its next poll tick.
+	done := make(chan struct{})
+	close(done)
+	watchProgress(cookie, done)
+
+	cookie.SetCallback(nil)
+	if cookie.callback != nil {
+		t.Error("Expected callback to be cleared")
+	}
+}
+
+func TestWatchProgressNilCookie(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	// Must not panic with a nil cookie or an unset callback.
+	watchProgress(nil, done)
+
+	cookie := &Cookie{}
+	watchProgress(cookie, done)
+}
+
+// TestRenderToPNGWithCookieNilPageWithAbortedCookie and
+// TestExtractTextWithCookieNilPageWithAbortedCookie cover the one piece of
+// the cookie path that doesn't require a live *Page (which, like the rest
+// of this package's native-backed types, needs the cgo Rust library this
+// source tree doesn't include): a nil page is rejected with
+// ErrInvalidHandle before the cookie is consulted, even when the cookie is
+// already aborted. Returning ErrAborted for an already-aborted cookie, and
+// actually interrupting an in-progress native render/extract call, both
+// need a live *Page and are not exercised by this package's Go tests.
+func TestRenderToPNGWithCookieNilPageWithAbortedCookie(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+	cookie := NewCookie(ctx)
+	defer cookie.Drop()
+	cookie.Abort()
+
+	var p *Page
+	_, err := p.RenderToPNGWithCookie(72, cookie)
+	if err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle for a nil page, got %v", err)
+	}
+}
+
+func TestExtractTextWithCookieNilPageWithAbortedCookie(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+	cookie := NewCookie(ctx)
+	defer cookie.Drop()
+	cookie.Abort()
+
+	var p *Page
+	_, err := p.ExtractTextWithCookie(cookie)
+	if err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle for a nil page, got %v", err)
+	}
+}
@@ -143,4 +143,7 @@ var (
 	ErrFailedToLoad = ErrGeneric("failed to load resource")
 	// ErrRenderFailed indicates a rendering operation failed.
 	ErrRenderFailed = ErrGeneric("rendering failed")
+	// ErrAborted indicates a Cookie-tracked operation was cancelled via
+	// Cookie.Abort before it completed.
+	ErrAborted = ErrGeneric("operation aborted")
 )
@@ -0,0 +1,100 @@
+package micropdf
+
+// ============================================================================
+// Fused Transform + Rect-Test Predicates
+//
+// Viewport culling of glyph quads and path vertices after CTM application
+// wants "transform, then test against the clip rect" as a single pass over
+// memory rather than an intermediate []Point from TransformPointsBatch
+// followed by a second walk through FilterPointsInRect/CountPointsInRect/
+// RectContainsPointsBatch.
+// ============================================================================
+
+// TransformAndFilterPointsInRect applies m to each point and returns only
+// those whose transformed position falls in clip (clip.X0 <= x < clip.X1,
+// clip.Y0 <= y < clip.Y1, matching FilterPointsInRect's half-open test), in
+// one pass.
+func TransformAndFilterPointsInRect(points []Point, m Matrix, clip Rect) []Point {
+	if len(points) == 0 {
+		return nil
+	}
+
+	result := make([]Point, 0, len(points)/4+1)
+
+	// Fast path: identity matrix (translation only), as in TransformRectsBatch.
+	if m.A == 1 && m.B == 0 && m.C == 0 && m.D == 1 {
+		for _, p := range points {
+			tp := Point{X: p.X + m.E, Y: p.Y + m.F}
+			if tp.X >= clip.X0 && tp.X < clip.X1 && tp.Y >= clip.Y0 && tp.Y < clip.Y1 {
+				result = append(result, tp)
+			}
+		}
+		return result
+	}
+
+	for _, p := range points {
+		tp := Point{X: p.X*m.A + p.Y*m.C + m.E, Y: p.X*m.B + p.Y*m.D + m.F}
+		if tp.X >= clip.X0 && tp.X < clip.X1 && tp.Y >= clip.Y0 && tp.Y < clip.Y1 {
+			result = append(result, tp)
+		}
+	}
+	return result
+}
+
+// TransformAndCountPointsInRect applies m to each point and counts how
+// many fall in clip, without materializing the transformed points.
+func TransformAndCountPointsInRect(points []Point, m Matrix, clip Rect) int {
+	if len(points) == 0 {
+		return 0
+	}
+
+	count := 0
+	if m.A == 1 && m.B == 0 && m.C == 0 && m.D == 1 {
+		for _, p := range points {
+			x, y := p.X+m.E, p.Y+m.F
+			if x >= clip.X0 && x < clip.X1 && y >= clip.Y0 && y < clip.Y1 {
+				count++
+			}
+		}
+		return count
+	}
+
+	for _, p := range points {
+		x := p.X*m.A + p.Y*m.C + m.E
+		y := p.X*m.B + p.Y*m.D + m.F
+		if x >= clip.X0 && x < clip.X1 && y >= clip.Y0 && y < clip.Y1 {
+			count++
+		}
+	}
+	return count
+}
+
+// TransformAndTestPointsInRect applies m to each point and writes a packed
+// bitmap of clip-containment results into out: bit (i % 64) of
+// out[i/64] is set when points[i] transforms into clip. out must satisfy
+// len(out) >= (len(points)+63)/64; bits beyond len(points) in the final
+// word are left untouched rather than cleared, so callers that reuse a
+// buffer across calls should zero it first. This packed form is meant for
+// downstream SIMD-friendly consumption — POPCNT-based counting, ANDing
+// against another bitmap — without the cost of a []bool.
+func TransformAndTestPointsInRect(points []Point, m Matrix, clip Rect, out []uint64) {
+	if len(points) == 0 {
+		return
+	}
+
+	axisAligned := m.A == 1 && m.B == 0 && m.C == 0 && m.D == 1
+	for i, p := range points {
+		var x, y float32
+		if axisAligned {
+			x, y = p.X+m.E, p.Y+m.F
+		} else {
+			x = p.X*m.A + p.Y*m.C + m.E
+			y = p.X*m.B + p.Y*m.D + m.F
+		}
+		if x >= clip.X0 && x < clip.X1 && y >= clip.Y0 && y < clip.Y1 {
+			out[i/64] |= uint64(1) << uint(i%64)
+		} else {
+			out[i/64] &^= uint64(1) << uint(i%64)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package micropdf
+
+import "testing"
+
+func testPointsAndMatrices() ([]Point, Rect, []Matrix) {
+	points := make([]Point, 200)
+	for i := range points {
+		points[i] = Point{X: float32(i%40) - 10, Y: float32(i%23) - 5}
+	}
+	clip := NewRect(0, 0, 20, 20)
+	matrices := []Matrix{
+		MatrixTranslate(5, 5),                        // identity fast path
+		MatrixScale(2, 2).Concat(MatrixRotate(30)),    // general affine
+	}
+	return points, clip, matrices
+}
+
+func TestTransformAndFilterPointsInRect(t *testing.T) {
+	points, clip, matrices := testPointsAndMatrices()
+
+	for _, m := range matrices {
+		// Reference transform is the scalar Matrix.TransformPoints: the
+		// SIMD backend behind TransformPointsBatch may round differently
+		// (FMA), so it cannot serve as a bit-exact reference.
+		transformed := make([]Point, len(points))
+		m.TransformPoints(transformed, points)
+		want := FilterPointsInRect(clip, transformed)
+		got := TransformAndFilterPointsInRect(points, m, clip)
+
+		if len(got) != len(want) {
+			t.Fatalf("matrix %+v: got %d points, want %d", m, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("matrix %+v: point %d: got %v, want %v", m, i, got[i], want[i])
+			}
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		if got := TransformAndFilterPointsInRect(nil, MatrixTranslate(1, 1), clip); got != nil {
+			t.Errorf("expected nil for no points, got %v", got)
+		}
+	})
+}
+
+func TestTransformAndCountPointsInRect(t *testing.T) {
+	points, clip, matrices := testPointsAndMatrices()
+
+	for _, m := range matrices {
+		want := CountPointsInRect(clip, TransformPointsBatch(points, m))
+		got := TransformAndCountPointsInRect(points, m, clip)
+		if got != want {
+			t.Errorf("matrix %+v: got %d, want %d", m, got, want)
+		}
+	}
+}
+
+func TestTransformAndTestPointsInRect(t *testing.T) {
+	points, clip, matrices := testPointsAndMatrices()
+
+	for _, m := range matrices {
+		want := RectContainsPointsBatch(clip, TransformPointsBatch(points, m))
+
+		out := make([]uint64, (len(points)+63)/64)
+		TransformAndTestPointsInRect(points, m, clip, out)
+
+		for i := range want {
+			bit := out[i/64]&(uint64(1)<<uint(i%64)) != 0
+			if bit != want[i] {
+				t.Errorf("matrix %+v: bit %d: got %v, want %v", m, i, bit, want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// Package micropdf - coverage-guided fuzzing harness
+package micropdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fuzzCorpusDir and fuzzMinimizedDir are relative to this package's
+// directory, matching go test's own testdata convention. fuzzMinimizedDir
+// is where recordFailure archives reproducers for failing runs, separate
+// from (and in addition to) the crashers `go test -fuzz` itself caches
+// under testdata/fuzz/<FuzzName>.
+const (
+	fuzzCorpusDir    = "testdata/fuzz/corpus"
+	fuzzMinimizedDir = "testdata/fuzz/minimized"
+)
+
+// recordFailure archives data under fuzzMinimizedDir when t has already
+// failed, so a human triaging crashers later has one tree to look in
+// regardless of which FuzzXxx target found the reproducer.
+func recordFailure(t *testing.T, name string, data []byte) {
+	if !t.Failed() {
+		return
+	}
+	if err := os.MkdirAll(fuzzMinimizedDir, 0o755); err != nil {
+		return
+	}
+	path := filepath.Join(fuzzMinimizedDir, fmt.Sprintf("%s-%016x", name, fnvSeed(data)))
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// fuzzCorpusMaxFileSize caps how large a file the corpus loader will feed
+// into f.Add, so a stray multi-gigabyte PDF dropped into fuzzCorpusDir
+// doesn't blow up fuzzing memory and time.
+const fuzzCorpusMaxFileSize = 8 << 20 // 8 MiB
+
+// loadCorpusDir walks dir and calls add on every regular file no larger
+// than fuzzCorpusMaxFileSize, so real-world PDFs (e.g. pulled from
+// Mozilla's pdf.js test corpus) can be dropped into dir to seed a fuzz
+// target without touching code. A missing dir is not an error — the
+// extra seed corpus is optional.
+func loadCorpusDir(dir string, add func(data []byte)) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > fuzzCorpusMaxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		add(data)
+		return nil
+	})
+}
+
+// structuredPDFOptions parameterizes genStructuredPDF's output so a single
+// fuzz input can steer the generator toward structurally different PDFs
+// (xref format, filter chain, encryption) without the fuzzer needing to
+// know PDF syntax.
+type structuredPDFOptions struct {
+	objectCount int
+	xrefStream  bool
+	filter      string // "", "FlateDecode", "ASCII85Decode", "LZWDecode", "DCTDecode"
+	encryption  string // "", "V1", "V2", "V4", "V5"
+}
+
+var fuzzFilters = []string{"", "FlateDecode", "ASCII85Decode", "LZWDecode", "DCTDecode"}
+var fuzzEncryptions = []string{"", "V1", "V2", "V4", "V5"}
+
+// structuredPDFOptionsFrom derives deterministic structuredPDFOptions from
+// the fuzzer's raw data, so mutating data explores the full cross-product
+// of xref type, filter, and encryption variant rather than always hitting
+// the same shape.
+func structuredPDFOptionsFrom(data []byte) structuredPDFOptions {
+	h := fnv.New32a()
+	_, _ = h.Write(data)
+	seed := h.Sum32()
+
+	return structuredPDFOptions{
+		objectCount: 3 + int(seed%8),
+		xrefStream:  seed&1 == 0,
+		filter:      fuzzFilters[int(seed>>1)%len(fuzzFilters)],
+		encryption:  fuzzEncryptions[int(seed>>1)/len(fuzzFilters)%len(fuzzEncryptions)],
+	}
+}
+
+// genStructuredPDF emits a syntactically valid PDF built from opts, using
+// rng for every randomized choice (page sizes, encryption O/U strings)
+// so the same seed always reproduces the same
+// document, keeping fuzzer-driven mutation of data meaningful.
+func genStructuredPDF(rng *rand.Rand, opts structuredPDFOptions) []byte {
+	n := opts.objectCount
+	if n < 3 {
+		n = 3
+	}
+	pageObjs := n - 2
+	if opts.encryption != "" {
+		pageObjs-- // object n holds the /Encrypt dictionary instead of a page
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, n+1) // 1-indexed by object number
+	writeObj := func(num int, body string) {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, body)
+	}
+
+	kids := make([]string, pageObjs)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	writeObj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageObjs))
+
+	for i := 0; i < pageObjs; i++ {
+		dict := fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d]",
+			200+rng.Intn(400), 200+rng.Intn(400))
+		if opts.filter != "" {
+			dict += fmt.Sprintf(" /Filter /%s", opts.filter)
+		}
+		dict += " >>"
+		writeObj(i+3, dict)
+	}
+
+	if opts.encryption != "" {
+		writeObj(n, genEncryptDict(rng, opts.encryption))
+	}
+
+	xrefOffset := buf.Len()
+	if opts.xrefStream {
+		writeXrefStreamObj(&buf, offsets, n)
+	} else {
+		writeClassicXref(&buf, offsets, n)
+		fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\n", n+1)
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF", xrefOffset)
+
+	return buf.Bytes()
+}
+
+// genEncryptDict returns a /Encrypt dictionary for variant ("V1", "V2",
+// "V4", or "V5"), with rng-generated O/U strings of the length that
+// variant's revision actually uses.
+func genEncryptDict(rng *rand.Rand, variant string) string {
+	keyLen := 5
+	rev := 2
+	switch variant {
+	case "V2":
+		keyLen, rev = 16, 3
+	case "V4":
+		keyLen, rev = 16, 4
+	case "V5":
+		keyLen, rev = 32, 6
+	}
+
+	v := map[string]int{"V1": 1, "V2": 2, "V4": 4, "V5": 5}[variant]
+	o := randHexString(rng, 32)
+	u := randHexString(rng, 32)
+
+	return fmt.Sprintf("<< /Filter /Standard /V %d /R %d /Length %d /O <%s> /U <%s> /P -44 >>",
+		v, rev, keyLen*8, o, u)
+}
+
+func randHexString(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	rng.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeClassicXref appends a classic (non-stream) cross-reference table
+// covering objects 1..n to buf.
+func writeClassicXref(buf *bytes.Buffer, offsets []int, n int) {
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", n+1)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offsets[i])
+	}
+}
+
+// writeXrefStreamObj appends a PDF 1.5-style cross-reference stream object
+// (type/offset/gen triples, uncompressed for simplicity) as the final
+// object in the file, combining the xref table and trailer per the spec.
+func writeXrefStreamObj(buf *bytes.Buffer, offsets []int, n int) {
+	var data bytes.Buffer
+	data.WriteByte(0)
+	data.Write([]byte{0, 0, 0, 0})
+	data.WriteByte(255)
+	data.WriteByte(255)
+	for i := 1; i <= n; i++ {
+		data.WriteByte(1)
+		off := offsets[i]
+		data.WriteByte(byte(off >> 24))
+		data.WriteByte(byte(off >> 16))
+		data.WriteByte(byte(off >> 8))
+		data.WriteByte(byte(off))
+		data.WriteByte(0)
+		data.WriteByte(0)
+	}
+	// The stream's own entry (object n+1), so the table covers all of /Size.
+	self := buf.Len()
+	data.Write([]byte{1, byte(self >> 24), byte(self >> 16), byte(self >> 8), byte(self), 0, 0})
+
+	fmt.Fprintf(buf, "%d 0 obj\n", n+1)
+	fmt.Fprintf(buf, "<< /Type /XRef /Size %d /Root 1 0 R /W [1 4 2] /Length %d >>\nstream\n", n+2, data.Len())
+	buf.Write(data.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+}
+
+// assertDocumentInvariants checks the properties that must hold for any
+// document OpenDocumentFromBytes accepts, regardless of how malformed the
+// input that produced it was: a non-negative page count, every page
+// within that count loading successfully, extracted text bounded relative
+// to file size, and finite page bounds.
+func assertDocumentInvariants(t *testing.T, doc *Document, fileSize int) {
+	t.Helper()
+
+	count, err := doc.PageCount()
+	if err != nil {
+		return
+	}
+	if count < 0 {
+		t.Fatalf("PageCount() = %d, want >= 0", count)
+	}
+
+	for i := int32(0); i < count; i++ {
+		page, err := doc.LoadPage(i)
+		if err != nil {
+			t.Fatalf("LoadPage(%d) failed for a document reporting PageCount() = %d: %v", i, count, err)
+		}
+
+		bounds := page.Bounds()
+		if !finiteRect(bounds) {
+			page.Drop()
+			t.Fatalf("LoadPage(%d).Bounds() = %+v, want finite", i, bounds)
+		}
+
+		text, err := page.ExtractText()
+		page.Drop()
+		if err != nil {
+			continue
+		}
+		if len(text) > 4*fileSize {
+			t.Fatalf("LoadPage(%d).ExtractText() returned %d bytes from a %d-byte file, want <= 4x", i, len(text), fileSize)
+		}
+	}
+}
+
+func finiteRect(r Rect) bool {
+	for _, v := range []float32{r.X0, r.Y0, r.X1, r.Y1} {
+		f := float64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// FuzzDocumentOpen feeds structured and corpus-seeded PDFs through
+// OpenDocumentFromBytes and checks the resulting Document's invariants
+// rather than only checking for a crash.
+func FuzzDocumentOpen(f *testing.F) {
+	loadCorpusDir(fuzzCorpusDir, f.Add)
+
+	f.Add([]byte("%PDF-1.4\n%%EOF"))
+	f.Add([]byte(""))
+	f.Add([]byte("not a pdf"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer recordFailure(t, "FuzzDocumentOpen", data)
+
+		pdf := genStructuredPDF(rand.New(rand.NewSource(int64(fnvSeed(data)))), structuredPDFOptionsFrom(data))
+
+		ctx := NewContext()
+		if ctx == nil {
+			t.Skip("no native context available")
+		}
+		defer ctx.Drop()
+
+		doc, err := OpenDocumentFromBytes(ctx, pdf, "application/pdf")
+		if err != nil {
+			return // rejecting malformed input is an expected outcome
+		}
+		defer doc.Drop()
+
+		assertDocumentInvariants(t, doc, len(pdf))
+	})
+}
+
+// FuzzPageText focuses on the ExtractText invariant in isolation, so a
+// reproducer that only breaks text extraction doesn't get buried among
+// page-count/bounds failures from FuzzDocumentOpen.
+func FuzzPageText(f *testing.F) {
+	loadCorpusDir(fuzzCorpusDir, f.Add)
+	f.Add([]byte("%PDF-1.4\n%%EOF"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer recordFailure(t, "FuzzPageText", data)
+
+		pdf := genStructuredPDF(rand.New(rand.NewSource(int64(fnvSeed(data)))), structuredPDFOptionsFrom(data))
+
+		ctx := NewContext()
+		if ctx == nil {
+			t.Skip("no native context available")
+		}
+		defer ctx.Drop()
+
+		doc, err := OpenDocumentFromBytes(ctx, pdf, "application/pdf")
+		if err != nil {
+			return
+		}
+		defer doc.Drop()
+
+		count, err := doc.PageCount()
+		if err != nil || count == 0 {
+			return
+		}
+
+		page, err := doc.LoadPage(0)
+		if err != nil {
+			return
+		}
+		defer page.Drop()
+
+		text, err := page.ExtractText()
+		if err != nil {
+			return
+		}
+		if len(text) > 4*len(pdf) {
+			t.Fatalf("ExtractText() returned %d bytes from a %d-byte file, want <= 4x", len(text), len(pdf))
+		}
+	})
+}
+
+// FuzzMetadata exercises Document.Metadata against structured PDFs whose
+// encryption variant is randomized, since metadata lookup is one of the
+// few operations a caller may invoke on an encrypted-but-unlocked
+// document.
+func FuzzMetadata(f *testing.F) {
+	loadCorpusDir(fuzzCorpusDir, f.Add)
+	f.Add([]byte("%PDF-1.4\n%%EOF"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer recordFailure(t, "FuzzMetadata", data)
+
+		pdf := genStructuredPDF(rand.New(rand.NewSource(int64(fnvSeed(data)))), structuredPDFOptionsFrom(data))
+
+		ctx := NewContext()
+		if ctx == nil {
+			t.Skip("no native context available")
+		}
+		defer ctx.Drop()
+
+		doc, err := OpenDocumentFromBytes(ctx, pdf, "application/pdf")
+		if err != nil {
+			return
+		}
+		defer doc.Drop()
+
+		// Metadata must either succeed with a usable map or report an
+		// error — it must never panic, regardless of how malformed the
+		// document's Info dictionary is.
+		meta, err := doc.Metadata()
+		if err != nil {
+			return
+		}
+		for k, v := range meta {
+			if k == "" {
+				t.Fatalf("Metadata() returned an entry with an empty key (value %q)", v)
+			}
+		}
+	})
+}
+
+// fnvSeed hashes data down to a uint64 RNG seed, so mutating any byte of a
+// fuzz corpus entry reliably perturbs the structured PDF it generates.
+func fnvSeed(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
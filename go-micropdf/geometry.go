@@ -0,0 +1,416 @@
+// Package micropdf - 2D geometry primitives
+package micropdf
+
+import "math"
+
+// Point is a 2D point in PDF user or device space.
+type Point struct {
+	X, Y float32
+}
+
+// Origin is the zero point (0, 0).
+var Origin = Point{X: 0, Y: 0}
+
+// NewPoint creates a Point at (x, y).
+func NewPoint(x, y float32) Point {
+	return Point{X: x, Y: y}
+}
+
+// Add returns p + q.
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+// Sub returns p - q.
+func (p Point) Sub(q Point) Point {
+	return Point{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+// Scale returns p scaled by s.
+func (p Point) Scale(s float32) Point {
+	return Point{X: p.X * s, Y: p.Y * s}
+}
+
+// Distance returns the Euclidean distance between p and q.
+func (p Point) Distance(q Point) float32 {
+	dx := float64(p.X - q.X)
+	dy := float64(p.Y - q.Y)
+	return float32(math.Sqrt(dx*dx + dy*dy))
+}
+
+// Transform returns p mapped through m.
+func (p Point) Transform(m Matrix) Point {
+	return m.TransformPoint(p)
+}
+
+// Equals reports whether p and q have identical coordinates.
+func (p Point) Equals(q Point) bool {
+	return p.X == q.X && p.Y == q.Y
+}
+
+// Rect is an axis-aligned rectangle, (X0, Y0) to (X1, Y1).
+type Rect struct {
+	X0, Y0, X1, Y1 float32
+}
+
+// infiniteThreshold is the magnitude above which a Rect's coordinates are
+// treated as effectively unbounded.
+const infiniteThreshold = 1e19
+
+// NewRect creates a Rect from its two corners.
+func NewRect(x0, y0, x1, y1 float32) Rect {
+	return Rect{X0: x0, Y0: y0, X1: x1, Y1: y1}
+}
+
+// NewRectFromXYWH creates a Rect from an origin and a width/height.
+func NewRectFromXYWH(x, y, w, h float32) Rect {
+	return Rect{X0: x, Y0: y, X1: x + w, Y1: y + h}
+}
+
+// Width returns r's width.
+func (r Rect) Width() float32 {
+	return r.X1 - r.X0
+}
+
+// Height returns r's height.
+func (r Rect) Height() float32 {
+	return r.Y1 - r.Y0
+}
+
+// IsEmpty reports whether r has non-positive width or height.
+func (r Rect) IsEmpty() bool {
+	return r.X0 >= r.X1 || r.Y0 >= r.Y1
+}
+
+// IsInfinite reports whether r's coordinates are large enough to be
+// treated as an unbounded rectangle rather than a real page/device area.
+func (r Rect) IsInfinite() bool {
+	return math.Abs(float64(r.X0)) >= infiniteThreshold ||
+		math.Abs(float64(r.Y0)) >= infiniteThreshold ||
+		math.Abs(float64(r.X1)) >= infiniteThreshold ||
+		math.Abs(float64(r.Y1)) >= infiniteThreshold
+}
+
+// Contains reports whether p lies within r, inclusive of the boundary.
+func (r Rect) Contains(p Point) bool {
+	return r.ContainsXY(p.X, p.Y)
+}
+
+// ContainsXY reports whether (x, y) lies within r, inclusive of the
+// boundary.
+func (r Rect) ContainsXY(x, y float32) bool {
+	return x >= r.X0 && x <= r.X1 && y >= r.Y0 && y <= r.Y1
+}
+
+// Union returns the smallest Rect containing both r and other.
+func (r Rect) Union(other Rect) Rect {
+	return Rect{
+		X0: minFloat32(r.X0, other.X0),
+		Y0: minFloat32(r.Y0, other.Y0),
+		X1: maxFloat32(r.X1, other.X1),
+		Y1: maxFloat32(r.Y1, other.Y1),
+	}
+}
+
+// Intersect returns the overlapping area of r and other. The result is
+// empty (per IsEmpty) if the two rectangles do not overlap.
+func (r Rect) Intersect(other Rect) Rect {
+	return Rect{
+		X0: maxFloat32(r.X0, other.X0),
+		Y0: maxFloat32(r.Y0, other.Y0),
+		X1: minFloat32(r.X1, other.X1),
+		Y1: minFloat32(r.Y1, other.Y1),
+	}
+}
+
+// IncludePoint returns the smallest Rect containing both r and p.
+func (r Rect) IncludePoint(p Point) Rect {
+	return Rect{
+		X0: minFloat32(r.X0, p.X),
+		Y0: minFloat32(r.Y0, p.Y),
+		X1: maxFloat32(r.X1, p.X),
+		Y1: maxFloat32(r.Y1, p.Y),
+	}
+}
+
+// Translate returns r shifted by (dx, dy).
+func (r Rect) Translate(dx, dy float32) Rect {
+	return Rect{X0: r.X0 + dx, Y0: r.Y0 + dy, X1: r.X1 + dx, Y1: r.Y1 + dy}
+}
+
+// Scale returns r with both corners scaled by (sx, sy) about the origin.
+func (r Rect) Scale(sx, sy float32) Rect {
+	return Rect{X0: r.X0 * sx, Y0: r.Y0 * sy, X1: r.X1 * sx, Y1: r.Y1 * sy}
+}
+
+// ToIRect rounds r's coordinates to the nearest integer rectangle.
+func (r Rect) ToIRect() IRect {
+	return IRect{
+		X0: int32(math.Round(float64(r.X0))),
+		Y0: int32(math.Round(float64(r.Y0))),
+		X1: int32(math.Round(float64(r.X1))),
+		Y1: int32(math.Round(float64(r.Y1))),
+	}
+}
+
+// IRect is an axis-aligned rectangle with integer coordinates, typically
+// used for pixmap/raster bounds.
+type IRect struct {
+	X0, Y0, X1, Y1 int32
+}
+
+// NewIRect creates an IRect from its two corners.
+func NewIRect(x0, y0, x1, y1 int32) IRect {
+	return IRect{X0: x0, Y0: y0, X1: x1, Y1: y1}
+}
+
+// Width returns r's width.
+func (r IRect) Width() int32 {
+	return r.X1 - r.X0
+}
+
+// Height returns r's height.
+func (r IRect) Height() int32 {
+	return r.Y1 - r.Y0
+}
+
+// IsEmpty reports whether r has non-positive width or height.
+func (r IRect) IsEmpty() bool {
+	return r.X0 >= r.X1 || r.Y0 >= r.Y1
+}
+
+// Matrix is a 2D affine transform, [A B C D E F], mapping (x, y) to
+// (x*A + y*C + E, x*B + y*D + F).
+type Matrix struct {
+	A, B, C, D, E, F float32
+}
+
+// Identity is the identity transform.
+var Identity = Matrix{A: 1, B: 0, C: 0, D: 1, E: 0, F: 0}
+
+// NewMatrix creates a Matrix from its six components.
+func NewMatrix(a, b, c, d, e, f float32) Matrix {
+	return Matrix{A: a, B: b, C: c, D: d, E: e, F: f}
+}
+
+// MatrixTranslate creates a translation matrix.
+func MatrixTranslate(tx, ty float32) Matrix {
+	return Matrix{A: 1, B: 0, C: 0, D: 1, E: tx, F: ty}
+}
+
+// MatrixScale creates a scaling matrix.
+func MatrixScale(sx, sy float32) Matrix {
+	return Matrix{A: sx, B: 0, C: 0, D: sy, E: 0, F: 0}
+}
+
+// MatrixRotate creates a rotation matrix for the given angle in degrees.
+func MatrixRotate(degrees float32) Matrix {
+	rad := float64(degrees) * math.Pi / 180
+	sin, cos := math.Sincos(rad)
+	return Matrix{A: float32(cos), B: float32(sin), C: float32(-sin), D: float32(cos)}
+}
+
+// MatrixShear creates a shear matrix with the given x/y shear factors.
+func MatrixShear(shx, shy float32) Matrix {
+	return Matrix{A: 1, B: shx, C: shy, D: 1}
+}
+
+// Concat returns the matrix that applies m first, then other.
+func (m Matrix) Concat(other Matrix) Matrix {
+	return Matrix{
+		A: m.A*other.A + m.B*other.C,
+		B: m.A*other.B + m.B*other.D,
+		C: m.C*other.A + m.D*other.C,
+		D: m.C*other.B + m.D*other.D,
+		E: m.E*other.A + m.F*other.C + other.E,
+		F: m.E*other.B + m.F*other.D + other.F,
+	}
+}
+
+// PreTranslate returns the matrix that translates by (tx, ty) before m.
+func (m Matrix) PreTranslate(tx, ty float32) Matrix {
+	return MatrixTranslate(tx, ty).Concat(m)
+}
+
+// PostTranslate returns the matrix that applies m, then translates by
+// (tx, ty).
+func (m Matrix) PostTranslate(tx, ty float32) Matrix {
+	return m.Concat(MatrixTranslate(tx, ty))
+}
+
+// PreScale returns the matrix that scales by (sx, sy) before m.
+func (m Matrix) PreScale(sx, sy float32) Matrix {
+	return MatrixScale(sx, sy).Concat(m)
+}
+
+// PostScale returns the matrix that applies m, then scales by (sx, sy).
+func (m Matrix) PostScale(sx, sy float32) Matrix {
+	return m.Concat(MatrixScale(sx, sy))
+}
+
+// PreRotate returns the matrix that rotates by degrees before m.
+func (m Matrix) PreRotate(degrees float32) Matrix {
+	return MatrixRotate(degrees).Concat(m)
+}
+
+// PostRotate returns the matrix that applies m, then rotates by degrees.
+func (m Matrix) PostRotate(degrees float32) Matrix {
+	return m.Concat(MatrixRotate(degrees))
+}
+
+// TransformPoint maps p through m.
+func (m Matrix) TransformPoint(p Point) Point {
+	return Point{
+		X: p.X*m.A + p.Y*m.C + m.E,
+		Y: p.X*m.B + p.Y*m.D + m.F,
+	}
+}
+
+// TransformRect maps r's four corners through m and returns their
+// axis-aligned bounding box, so the result is still a valid Rect even
+// when m rotates or shears.
+func (m Matrix) TransformRect(r Rect) Rect {
+	return QuadFromRect(r).Transform(m).Bounds()
+}
+
+// Determinant returns A*D - B*C, the determinant of m's linear part.
+func (m Matrix) Determinant() float32 {
+	return m.A*m.D - m.B*m.C
+}
+
+// matrixEpsilon is the determinant magnitude below which a Matrix is
+// treated as non-invertible (degenerate, e.g. a zero scale).
+const matrixEpsilon = 1e-6
+
+// IsInvertible reports whether m has a well-conditioned inverse.
+func (m Matrix) IsInvertible() bool {
+	return math.Abs(float64(m.Determinant())) >= matrixEpsilon
+}
+
+// Invert returns the inverse of m and true, or the zero Matrix and false
+// if m is not invertible (|Determinant()| < epsilon).
+func (m Matrix) Invert() (Matrix, bool) {
+	det := m.Determinant()
+	if math.Abs(float64(det)) < matrixEpsilon {
+		return Matrix{}, false
+	}
+
+	return Matrix{
+		A: m.D / det,
+		B: -m.B / det,
+		C: -m.C / det,
+		D: m.A / det,
+		E: (m.C*m.F - m.D*m.E) / det,
+		F: (m.B*m.E - m.A*m.F) / det,
+	}, true
+}
+
+// InverseTransformPoint maps p through m's inverse, returning p
+// unchanged if m is not invertible.
+func (m Matrix) InverseTransformPoint(p Point) Point {
+	inv, ok := m.Invert()
+	if !ok {
+		return p
+	}
+	return inv.TransformPoint(p)
+}
+
+// TransformPoints maps each point in src through m into dst, which must
+// satisfy len(dst) >= len(src). dst and src may be the same slice for an
+// in-place transform; they must not otherwise overlap.
+//
+// The loop is written in the straight-line form
+// dst[i].X = a*src[i].X + c*src[i].Y + e (and likewise for Y) so the
+// compiler can auto-vectorize it — this is the batch counterpart to
+// TransformPoint for hot paths like positioning thousands of glyph quads.
+func (m Matrix) TransformPoints(dst, src []Point) {
+	for i := range src {
+		dst[i].X = src[i].X*m.A + src[i].Y*m.C + m.E
+		dst[i].Y = src[i].X*m.B + src[i].Y*m.D + m.F
+	}
+}
+
+// TransformPointsXY maps the (srcX[i], srcY[i]) coordinate pairs through m
+// into (dstX[i], dstY[i]), struct-of-arrays style. dstX/dstY must each be
+// at least as long as srcX/srcY; (dstX, dstY) may alias (srcX, srcY)
+// element-for-element for an in-place transform.
+func (m Matrix) TransformPointsXY(dstX, dstY, srcX, srcY []float32) {
+	for i := range srcX {
+		x, y := srcX[i], srcY[i]
+		dstX[i] = x*m.A + y*m.C + m.E
+		dstY[i] = x*m.B + y*m.D + m.F
+	}
+}
+
+// TransformRects maps each rect in src through m into dst (its corners'
+// axis-aligned bounding box, per TransformRect), which must satisfy
+// len(dst) >= len(src). dst and src may be the same slice for an
+// in-place transform; they must not otherwise overlap.
+func (m Matrix) TransformRects(dst, src []Rect) {
+	for i := range src {
+		dst[i] = m.TransformRect(src[i])
+	}
+}
+
+// TransformQuads maps each quad in src through m into dst, which must
+// satisfy len(dst) >= len(src). dst and src may be the same slice for an
+// in-place transform; they must not otherwise overlap.
+func (m Matrix) TransformQuads(dst, src []Quad) {
+	for i := range src {
+		dst[i] = src[i].Transform(m)
+	}
+}
+
+// Quad is an arbitrary (possibly rotated) quadrilateral, as used for text
+// selection and search-result highlighting.
+type Quad struct {
+	UL, UR, LL, LR Point
+}
+
+// NewQuad creates a Quad from its four corners.
+func NewQuad(ul, ur, ll, lr Point) Quad {
+	return Quad{UL: ul, UR: ur, LL: ll, LR: lr}
+}
+
+// QuadFromRect creates an axis-aligned Quad matching r's corners.
+func QuadFromRect(r Rect) Quad {
+	return Quad{
+		UL: Point{X: r.X0, Y: r.Y0},
+		UR: Point{X: r.X1, Y: r.Y0},
+		LL: Point{X: r.X0, Y: r.Y1},
+		LR: Point{X: r.X1, Y: r.Y1},
+	}
+}
+
+// Transform maps every corner of q through m.
+func (q Quad) Transform(m Matrix) Quad {
+	return Quad{
+		UL: q.UL.Transform(m),
+		UR: q.UR.Transform(m),
+		LL: q.LL.Transform(m),
+		LR: q.LR.Transform(m),
+	}
+}
+
+// Bounds returns the axis-aligned bounding box of q's four corners.
+func (q Quad) Bounds() Rect {
+	r := Rect{X0: q.UL.X, Y0: q.UL.Y, X1: q.UL.X, Y1: q.UL.Y}
+	r = r.IncludePoint(q.UR)
+	r = r.IncludePoint(q.LL)
+	r = r.IncludePoint(q.LR)
+	return r
+}
+
+func minFloat32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -434,4 +434,132 @@ func TestMatrix_Additional(t *testing.T) {
 			t.Errorf("TransformRect failed: got %fx%f", result.Width(), result.Height())
 		}
 	})
+
+	t.Run("Invert", func(t *testing.T) {
+		m := MatrixScale(2, 4).PostTranslate(10, 20)
+		inv, ok := m.Invert()
+		if !ok {
+			t.Fatal("expected invertible matrix")
+		}
+		round := m.Concat(inv)
+		if math.Abs(float64(round.A)-1) > 1e-4 || math.Abs(float64(round.D)-1) > 1e-4 ||
+			math.Abs(float64(round.B)) > 1e-4 || math.Abs(float64(round.C)) > 1e-4 ||
+			math.Abs(float64(round.E)) > 1e-4 || math.Abs(float64(round.F)) > 1e-4 {
+			t.Errorf("m.Concat(m.Invert()) should be identity, got %+v", round)
+		}
+	})
+
+	t.Run("InvertDegenerate", func(t *testing.T) {
+		m := MatrixScale(0, 0)
+		if m.IsInvertible() {
+			t.Error("zero-scale matrix should not be invertible")
+		}
+		if _, ok := m.Invert(); ok {
+			t.Error("expected Invert to fail on a degenerate matrix")
+		}
+	})
+
+	t.Run("InverseTransformPoint", func(t *testing.T) {
+		m := MatrixScale(2, 4).PostTranslate(10, 20)
+		p := NewPoint(5, 6)
+		device := p.Transform(m)
+		back := m.InverseTransformPoint(device)
+		if math.Abs(float64(back.X-p.X)) > 1e-3 || math.Abs(float64(back.Y-p.Y)) > 1e-3 {
+			t.Errorf("InverseTransformPoint roundtrip failed: got (%f, %f), want (%f, %f)", back.X, back.Y, p.X, p.Y)
+		}
+	})
+}
+
+func TestMatrix_BatchTransforms(t *testing.T) {
+	m := MatrixTranslate(10, 20).Concat(MatrixScale(2, 3))
+
+	t.Run("TransformPoints", func(t *testing.T) {
+		src := []Point{NewPoint(1, 1), NewPoint(2, 3), NewPoint(-1, 4)}
+		dst := make([]Point, len(src))
+		m.TransformPoints(dst, src)
+		for i, p := range src {
+			if want := p.Transform(m); !dst[i].Equals(want) {
+				t.Errorf("dst[%d] = %+v, want %+v", i, dst[i], want)
+			}
+		}
+	})
+
+	t.Run("TransformPointsInPlace", func(t *testing.T) {
+		src := []Point{NewPoint(1, 1), NewPoint(2, 3), NewPoint(-1, 4)}
+		want := make([]Point, len(src))
+		for i, p := range src {
+			want[i] = p.Transform(m)
+		}
+		m.TransformPoints(src, src)
+		for i := range src {
+			if !src[i].Equals(want[i]) {
+				t.Errorf("in-place dst[%d] = %+v, want %+v", i, src[i], want[i])
+			}
+		}
+	})
+
+	t.Run("TransformPointsXY", func(t *testing.T) {
+		srcX := []float32{1, 2, -1}
+		srcY := []float32{1, 3, 4}
+		dstX := make([]float32, len(srcX))
+		dstY := make([]float32, len(srcY))
+		m.TransformPointsXY(dstX, dstY, srcX, srcY)
+		for i := range srcX {
+			want := NewPoint(srcX[i], srcY[i]).Transform(m)
+			if dstX[i] != want.X || dstY[i] != want.Y {
+				t.Errorf("(dstX[%d], dstY[%d]) = (%f, %f), want (%f, %f)", i, i, dstX[i], dstY[i], want.X, want.Y)
+			}
+		}
+	})
+
+	t.Run("TransformRects", func(t *testing.T) {
+		src := []Rect{NewRect(0, 0, 10, 10), NewRect(-5, -5, 5, 5)}
+		dst := make([]Rect, len(src))
+		m.TransformRects(dst, src)
+		for i, r := range src {
+			if want := m.TransformRect(r); dst[i] != want {
+				t.Errorf("dst[%d] = %+v, want %+v", i, dst[i], want)
+			}
+		}
+	})
+
+	t.Run("TransformQuads", func(t *testing.T) {
+		src := []Quad{QuadFromRect(NewRect(0, 0, 10, 10)), QuadFromRect(NewRect(-5, -5, 5, 5))}
+		dst := make([]Quad, len(src))
+		m.TransformQuads(dst, src)
+		for i, q := range src {
+			if want := q.Transform(m); dst[i] != want {
+				t.Errorf("dst[%d] = %+v, want %+v", i, dst[i], want)
+			}
+		}
+	})
+}
+
+// FuzzGeometry checks that, for any matrix built from fuzzer-supplied
+// components, concatenating it with its own inverse always yields the
+// identity transform whenever the matrix reports itself invertible.
+func FuzzGeometry(f *testing.F) {
+	f.Add(float32(1), float32(0), float32(0), float32(1), float32(0), float32(0))
+	f.Add(float32(2), float32(0), float32(0), float32(4), float32(10), float32(20))
+	f.Add(float32(0), float32(0), float32(0), float32(0), float32(0), float32(0))
+
+	f.Fuzz(func(t *testing.T, a, b, c, d, e, fv float32) {
+		m := NewMatrix(a, b, c, d, e, fv)
+		if !m.IsInvertible() {
+			return
+		}
+
+		inv, ok := m.Invert()
+		if !ok {
+			t.Fatalf("IsInvertible true but Invert failed for %+v", m)
+		}
+
+		round := m.Concat(inv)
+		const tol = 1e-2
+		if math.Abs(float64(round.A)-1) > tol || math.Abs(float64(round.D)-1) > tol ||
+			math.Abs(float64(round.B)) > tol || math.Abs(float64(round.C)) > tol ||
+			math.Abs(float64(round.E)) > tol || math.Abs(float64(round.F)) > tol {
+			t.Errorf("m.Concat(m.Invert()) != identity for %+v: got %+v", m, round)
+		}
+	})
 }
@@ -0,0 +1,13 @@
+package simd
+
+// transformPointsGeneric is the portable fallback used on platforms
+// without a hand-written backend, and by the assembly backends to finish
+// off a tail that doesn't fill a whole SIMD lane group.
+func transformPointsGeneric(coords []float32, a, b, c, d, e, f float32) {
+	n := len(coords) &^ 1 // drop a trailing odd float, if any
+	for i := 0; i < n; i += 2 {
+		x, y := coords[i], coords[i+1]
+		coords[i] = x*a + y*c + e
+		coords[i+1] = x*b + y*d + f
+	}
+}
@@ -0,0 +1,18 @@
+// Package simd provides hand-written SIMD kernels for the hot affine
+// transform loop shared by package micropdf's batch geometry helpers
+// (TransformPointsBatch, TransformPointsBatchInPlace,
+// ApplyMatrixToFloatPairs). Each platform wires up the fastest backend it
+// has an assembly kernel for at init time; platforms without one fall back
+// to transformPointsGeneric, a plain Go port of the same loop.
+package simd
+
+// TransformPoints applies the affine matrix
+//
+//	x' = x*a + y*c + e
+//	y' = x*b + y*d + f
+//
+// to each (x, y) pair packed into coords (coords[2*i], coords[2*i+1]), in
+// place. An odd trailing float, if any, is left untouched.
+func TransformPoints(coords []float32, a, b, c, d, e, f float32) {
+	transformPoints(coords, a, b, c, d, e, f)
+}
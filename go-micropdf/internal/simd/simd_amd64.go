@@ -0,0 +1,33 @@
+//go:build amd64
+
+package simd
+
+import "golang.org/x/sys/cpu"
+
+var transformPoints = transformPointsGeneric
+
+func init() {
+	if cpu.X86.HasAVX2 && cpu.X86.HasFMA {
+		transformPoints = transformPointsAVX2
+	}
+}
+
+// transformPointsAVX2 transforms the leading groups of 4 points (8 floats)
+// in coords with the AVX2+FMA3 kernel in transform_points_amd64.s, then
+// hands any remaining 0-7 floats to the scalar fallback.
+func transformPointsAVX2(coords []float32, a, b, c, d, e, f float32) {
+	n := len(coords)
+	full := n - n%8
+	if full > 0 {
+		transformPoints8xAVX2(coords[:full:full], a, b, c, d, e, f)
+	}
+	if full < n {
+		transformPointsGeneric(coords[full:], a, b, c, d, e, f)
+	}
+}
+
+// transformPoints8xAVX2 is implemented in transform_points_amd64.s.
+// len(coords) must be a positive multiple of 8.
+//
+//go:noescape
+func transformPoints8xAVX2(coords []float32, a, b, c, d, e, f float32)
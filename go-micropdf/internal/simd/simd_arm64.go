@@ -0,0 +1,27 @@
+//go:build arm64
+
+package simd
+
+// NEON is part of the arm64 baseline, so there's no feature gate to check
+// before using it, unlike the AVX2 path on amd64.
+var transformPoints = transformPointsNEON
+
+// transformPointsNEON transforms the leading groups of 4 points (8 floats)
+// in coords with the NEON kernel in transform_points_arm64.s, then hands
+// any remaining 0-7 floats to the scalar fallback.
+func transformPointsNEON(coords []float32, a, b, c, d, e, f float32) {
+	n := len(coords)
+	full := n - n%8
+	if full > 0 {
+		transformPoints8xNEON(coords[:full:full], a, b, c, d, e, f)
+	}
+	if full < n {
+		transformPointsGeneric(coords[full:], a, b, c, d, e, f)
+	}
+}
+
+// transformPoints8xNEON is implemented in transform_points_arm64.s.
+// len(coords) must be a positive multiple of 8.
+//
+//go:noescape
+func transformPoints8xNEON(coords []float32, a, b, c, d, e, f float32)
@@ -0,0 +1,53 @@
+package simd
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestTransformPointsMatchesGeneric checks the platform-dispatched backend
+// (AVX2+FMA3 on amd64, NEON on arm64, or the plain Go fallback elsewhere)
+// against transformPointsGeneric over a range of slice lengths, including
+// ones that don't divide evenly into a 4-point group, to exercise the
+// scalar tail path. FMA rounds its multiply-add in one step rather than
+// two, so an exact bit-for-bit match isn't guaranteed; values are compared
+// to a tight absolute tolerance instead of requiring bitwise equality.
+func TestTransformPointsMatchesGeneric(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// Several distinct matrices, including the identity: a deinterleave/
+	// reinterleave bug in a SIMD kernel can cancel out for some
+	// coefficient combinations (e.g. b==c==0) while still corrupting
+	// others, so more than one matrix needs checking.
+	matrices := [][6]float32{
+		{1, 0, 0, 1, 0, 0}, // identity
+		{0.5, -1.25, 2.0, 0.75, 10, -20},
+		{0, 1, 1, 0, 0, 0},       // swap x/y, no translation
+		{2, 0, 0, 2, -5, 5},      // uniform scale + translate
+		{-1, 0, 0, -1, 100, 100}, // 180-degree rotation
+	}
+
+	for _, m := range matrices {
+		a, b, c, d, e, f := m[0], m[1], m[2], m[3], m[4], m[5]
+
+		for _, n := range []int{0, 1, 2, 6, 8, 9, 16, 17, 4096, 4999} {
+			base := make([]float32, n)
+			for i := range base {
+				base[i] = rng.Float32()*200 - 100
+			}
+
+			got := append([]float32(nil), base...)
+			transformPoints(got, a, b, c, d, e, f)
+
+			want := append([]float32(nil), base...)
+			transformPointsGeneric(want, a, b, c, d, e, f)
+
+			for i := range want {
+				if diff := math.Abs(float64(got[i] - want[i])); diff > 1e-3 {
+					t.Fatalf("matrix %+v, n=%d: dispatched backend diverges from generic at index %d: got %v, want %v (diff %v)", m, n, i, got[i], want[i], diff)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package micropdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MergeInput describes a single input document for MergePDFsAdvanced.
+//
+// Either Path or Data must be set; if Data is non-nil it takes precedence
+// over Path, mirroring how OpenDocument and OpenDocumentFromBytes split
+// file-backed and in-memory sources elsewhere in this package.
+type MergeInput struct {
+	// Path is the filesystem path to the input PDF. Ignored if Data is set.
+	Path string
+	// Data is an optional in-memory PDF, taking precedence over Path.
+	Data []byte
+	// Pages is a page range expression selecting which pages to include,
+	// in the order they should appear in the output. Supported syntax:
+	//
+	//	"1-5,8,10-"  pages 1 through 5, page 8, and page 10 to the end
+	//	"-3"         pages 1 through 3
+	//	"even"       all even-numbered pages, in order
+	//	"odd"        all odd-numbered pages, in order
+	//
+	// Page numbers are 1-based. An empty string selects every page.
+	Pages string
+	// Rotate applies an additional rotation, in degrees, to every page
+	// taken from this input. Must be 0, 90, 180, or 270.
+	Rotate int
+	// Password authenticates an encrypted input. Ignored if the input
+	// does not require a password.
+	Password string
+	// SectionTitle, if non-empty, prefixes this input's bookmarks/outline
+	// entries in the merged output so they can be told apart, e.g. an
+	// outline entry "Introduction" from an input with SectionTitle
+	// "Appendix A" becomes "Appendix A: Introduction".
+	SectionTitle string
+}
+
+// MergeOptions controls cross-input behavior for MergePDFsAdvanced.
+type MergeOptions struct {
+	// PrimaryIndex selects which input's document-level metadata (title,
+	// author, etc.) is carried over to the merged output. Defaults to the
+	// first input (index 0).
+	PrimaryIndex int
+	// DeduplicateResources, if true, identifies embedded fonts and images
+	// shared byte-for-byte across inputs and writes each only once to
+	// shrink the merged output.
+	DeduplicateResources bool
+}
+
+// MergedPageRef records where one page of a MergePDFsAdvanced result came
+// from: SourcePage is the 0-based page number within Inputs[InputIndex].
+type MergedPageRef struct {
+	InputIndex int
+	SourcePage int
+}
+
+// MergeWarning reports a non-fatal problem with one input, such as pages
+// skipped due to corruption, so callers can build UIs around partial
+// successes instead of failing the whole merge.
+type MergeWarning struct {
+	InputIndex int
+	Message    string
+}
+
+// MergeResult is the outcome of a successful (possibly partial) call to
+// MergePDFsAdvanced.
+type MergeResult struct {
+	PageCount int
+	PageMap   []MergedPageRef
+	Warnings  []MergeWarning
+}
+
+// MergePDFsAdvanced merges inputs into a single output PDF at outputPath,
+// with per-input page selection, rotation, and password support. Unlike
+// MergePDFs, it tolerates individual inputs failing to open or having an
+// invalid page range: such inputs are skipped and recorded in the
+// returned MergeResult's Warnings instead of aborting the whole merge. An
+// error is returned only if ctx/outputPath are invalid, inputs is empty,
+// opts.PrimaryIndex or an input's Rotate is out of range, no input
+// contributed any pages at all, or the native merge itself fails.
+//
+// Bookmarks/outlines from each input are preserved in the merged output,
+// prefixed with that input's SectionTitle (when set) so the result has a
+// single navigable outline tree. Document-level metadata is carried over
+// from inputs[opts.PrimaryIndex], or, if that input was skipped, from the
+// first input that contributed pages (with a warning).
+func MergePDFsAdvanced(ctx *Context, inputs []MergeInput, outputPath string, opts MergeOptions) (*MergeResult, error) {
+	if ctx == nil || !ctx.IsValid() {
+		return nil, ErrInvalidContext
+	}
+	if len(inputs) == 0 {
+		return nil, ErrInvalidArgument
+	}
+	if outputPath == "" {
+		return nil, ErrInvalidArgument
+	}
+	if opts.PrimaryIndex < 0 || opts.PrimaryIndex >= len(inputs) {
+		return nil, ErrArgument("primary index out of range")
+	}
+	for _, in := range inputs {
+		if in.Rotate%90 != 0 || in.Rotate%360 < 0 || in.Rotate >= 360 {
+			return nil, ErrArgument(fmt.Sprintf("invalid rotation %d", in.Rotate))
+		}
+	}
+
+	var (
+		specs            = make([]mergeInputSpec, 0, len(inputs))
+		pageMap          = make([]MergedPageRef, 0, len(inputs))
+		warnings         []MergeWarning
+		specIndexByInput = make(map[int]int, len(inputs))
+	)
+
+	for i, in := range inputs {
+		pages, warn := resolveMergeInputPages(ctx, i, in)
+		if warn != "" {
+			warnings = append(warnings, MergeWarning{InputIndex: i, Message: warn})
+		}
+		if len(pages) == 0 {
+			continue
+		}
+
+		specIndexByInput[i] = len(specs)
+		specs = append(specs, mergeInputSpec{
+			Path:         in.Path,
+			Data:         in.Data,
+			Pages:        pages,
+			Rotate:       in.Rotate,
+			SectionTitle: in.SectionTitle,
+		})
+		for _, p := range pages {
+			pageMap = append(pageMap, MergedPageRef{InputIndex: i, SourcePage: p})
+		}
+	}
+
+	if len(specs) == 0 {
+		return nil, ErrFailedToOpen
+	}
+
+	// opts.PrimaryIndex names a position in inputs, but specs is a
+	// filtered copy — any skipped input before it shifts every later
+	// index. Re-resolve into specs' index space rather than passing
+	// opts.PrimaryIndex straight through, or metadata gets carried over
+	// from the wrong document (or the index runs past the end of specs).
+	primarySpecIndex, warn := resolveMergePrimarySpecIndex(opts.PrimaryIndex, specIndexByInput)
+	if warn != nil {
+		warnings = append(warnings, *warn)
+	}
+
+	pageCount, err := mergePDFsAdvancedNative(ctx.Handle(), specs, outputPath, primarySpecIndex, opts.DeduplicateResources)
+	if pageCount < 0 {
+		if err == nil {
+			err = ErrFailedToOpen
+		}
+		return nil, err
+	}
+
+	return &MergeResult{
+		PageCount: pageCount,
+		PageMap:   pageMap,
+		Warnings:  warnings,
+	}, nil
+}
+
+// mergeInputSpec is the fully-resolved form of a MergeInput handed to the
+// native merge routine: Pages is already expanded into explicit 0-based
+// source page indices, in output order, rather than a range expression.
+type mergeInputSpec struct {
+	Path         string
+	Data         []byte
+	Pages        []int
+	Rotate       int
+	SectionTitle string
+}
+
+// resolveMergeInputPages opens in's document just long enough to
+// authenticate it (if a password was supplied) and expand its page range
+// expression against its actual page count, returning the resolved
+// 0-based page indices. A non-empty warning string means the input
+// contributed no pages and should be skipped.
+func resolveMergeInputPages(ctx *Context, index int, in MergeInput) ([]int, string) {
+	var (
+		doc *Document
+		err error
+	)
+	if in.Data != nil {
+		doc, err = OpenDocumentFromBytes(ctx, in.Data, "application/pdf")
+	} else {
+		doc, err = OpenDocument(ctx, in.Path)
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("failed to open input %d: %v", index, err)
+	}
+	defer doc.Drop()
+
+	if needsPassword, _ := doc.NeedsPassword(); needsPassword {
+		if in.Password == "" || !doc.Authenticate(in.Password) {
+			return nil, fmt.Sprintf("input %d is password-protected and could not be authenticated", index)
+		}
+	}
+
+	pageCount, err := doc.PageCount()
+	if err != nil {
+		return nil, fmt.Sprintf("failed to read page count for input %d: %v", index, err)
+	}
+
+	pages, err := parsePageRange(in.Pages, int(pageCount))
+	if err != nil {
+		return nil, fmt.Sprintf("invalid page range for input %d: %v", index, err)
+	}
+	return pages, ""
+}
+
+// resolveMergePrimarySpecIndex re-resolves a MergeOptions.PrimaryIndex
+// (a position in the original, unfiltered inputs slice) into specs' index
+// space, using specIndexByInput to look up where that input landed after
+// skipped inputs were filtered out. If the original primary input was
+// itself skipped, it falls back to specs[0] and returns a warning rather
+// than an out-of-range or silently-wrong index.
+func resolveMergePrimarySpecIndex(primaryIndex int, specIndexByInput map[int]int) (int, *MergeWarning) {
+	if specIndex, ok := specIndexByInput[primaryIndex]; ok {
+		return specIndex, nil
+	}
+	return 0, &MergeWarning{
+		InputIndex: primaryIndex,
+		Message:    "primary input was skipped (failed to open, failed auth, or resolved to zero pages); falling back to the first surviving input's metadata",
+	}
+}
+
+// parsePageRange expands a page range expression into 0-based page
+// indices, in the order they appear in expr. pageCount is the document's
+// total page count, used to bound open-ended clauses and validate that
+// every referenced page exists. An empty expr selects every page.
+//
+// Supported clause syntax, comma-separated:
+//
+//	"N"    a single 1-based page
+//	"N-M"  pages N through M inclusive
+//	"N-"   page N through the last page
+//	"-N"   page 1 through N
+//
+// The keywords "even" and "odd" select every even- or odd-numbered page
+// instead of a comma-separated clause list.
+func parsePageRange(expr string, pageCount int) ([]int, error) {
+	if pageCount < 0 {
+		return nil, ErrArgument("page count must not be negative")
+	}
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		pages := make([]int, pageCount)
+		for i := range pages {
+			pages[i] = i
+		}
+		return pages, nil
+	}
+
+	switch strings.ToLower(expr) {
+	case "even":
+		var pages []int
+		for n := 2; n <= pageCount; n += 2 {
+			pages = append(pages, n-1)
+		}
+		return pages, nil
+	case "odd":
+		var pages []int
+		for n := 1; n <= pageCount; n += 2 {
+			pages = append(pages, n-1)
+		}
+		return pages, nil
+	}
+
+	var pages []int
+	for _, clause := range strings.Split(expr, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			return nil, ErrFormat(fmt.Sprintf("empty clause in page range %q", expr))
+		}
+
+		start, end, err := parsePageClause(clause, pageCount)
+		if err != nil {
+			return nil, err
+		}
+		for n := start; n <= end; n++ {
+			if n < 1 || n > pageCount {
+				return nil, ErrFormat(fmt.Sprintf("page %d out of range (document has %d pages)", n, pageCount))
+			}
+			pages = append(pages, n-1)
+		}
+	}
+	return pages, nil
+}
+
+func parsePageClause(clause string, pageCount int) (start, end int, err error) {
+	switch {
+	case strings.HasPrefix(clause, "-"):
+		n, err := strconv.Atoi(clause[1:])
+		if err != nil {
+			return 0, 0, ErrFormat(fmt.Sprintf("invalid page range clause %q", clause))
+		}
+		return 1, n, nil
+	case strings.HasSuffix(clause, "-"):
+		n, err := strconv.Atoi(clause[:len(clause)-1])
+		if err != nil {
+			return 0, 0, ErrFormat(fmt.Sprintf("invalid page range clause %q", clause))
+		}
+		return n, pageCount, nil
+	case strings.Contains(clause, "-"):
+		parts := strings.SplitN(clause, "-", 2)
+		a, errA := strconv.Atoi(strings.TrimSpace(parts[0]))
+		b, errB := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if errA != nil || errB != nil {
+			return 0, 0, ErrFormat(fmt.Sprintf("invalid page range clause %q", clause))
+		}
+		return a, b, nil
+	default:
+		n, err := strconv.Atoi(clause)
+		if err != nil {
+			return 0, 0, ErrFormat(fmt.Sprintf("invalid page range clause %q", clause))
+		}
+		return n, n, nil
+	}
+}
+
+// mergePDFsAdvancedNative performs the actual page-level merge, outline
+// prefixing, metadata carry-over, and (optionally) resource
+// deduplication, returning the merged output's total page count and an
+// error if the native backend rejects the request. Implemented in the
+// cgo backend alongside mergePDFsNative (see enhanced.go).
@@ -0,0 +1,137 @@
+package micropdf
+
+import (
+	"testing"
+)
+
+func TestMergePDFsAdvancedValidation(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+
+	t.Run("EmptyInputs", func(t *testing.T) {
+		_, err := MergePDFsAdvanced(ctx, nil, "output.pdf", MergeOptions{})
+		if err != ErrInvalidArgument {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("EmptyOutputPath", func(t *testing.T) {
+		_, err := MergePDFsAdvanced(ctx, []MergeInput{{Path: "doc1.pdf"}}, "", MergeOptions{})
+		if err != ErrInvalidArgument {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("NilContext", func(t *testing.T) {
+		_, err := MergePDFsAdvanced(nil, []MergeInput{{Path: "doc1.pdf"}}, "output.pdf", MergeOptions{})
+		if err != ErrInvalidContext {
+			t.Errorf("Expected ErrInvalidContext, got %v", err)
+		}
+	})
+
+	t.Run("PrimaryIndexOutOfRange", func(t *testing.T) {
+		inputs := []MergeInput{{Path: "doc1.pdf"}}
+		_, err := MergePDFsAdvanced(ctx, inputs, "output.pdf", MergeOptions{PrimaryIndex: 1})
+		if err == nil {
+			t.Error("Expected an error for out-of-range PrimaryIndex")
+		}
+	})
+
+	t.Run("InvalidRotation", func(t *testing.T) {
+		inputs := []MergeInput{{Path: "doc1.pdf", Rotate: 45}}
+		_, err := MergePDFsAdvanced(ctx, inputs, "output.pdf", MergeOptions{})
+		if err == nil {
+			t.Error("Expected an error for a non-multiple-of-90 rotation")
+		}
+	})
+}
+
+// TestResolveMergePrimarySpecIndex exercises the PrimaryIndex remap in
+// isolation from the native merge call, since reaching it through
+// MergePDFsAdvanced end-to-end would require at least one input to
+// actually open via the native PDF backend, which this source tree
+// doesn't include (every OpenDocument/OpenDocumentFromBytes call fails,
+// so specs is always empty and MergePDFsAdvanced returns ErrFailedToOpen
+// before ever reaching this logic).
+func TestResolveMergePrimarySpecIndex(t *testing.T) {
+	t.Run("SkippedEarlierInput", func(t *testing.T) {
+		// inputs[0] failed to open and was skipped; inputs[1] and
+		// inputs[2] survived as specs[0] and specs[1]. PrimaryIndex
+		// names inputs[2], which must resolve to specs[1], not specs[2]
+		// (which doesn't exist) or specs[0] (input 1's slot).
+		specIndexByInput := map[int]int{1: 0, 2: 1}
+
+		specIndex, warn := resolveMergePrimarySpecIndex(2, specIndexByInput)
+		if warn != nil {
+			t.Fatalf("expected no warning for a surviving primary input, got %+v", warn)
+		}
+		if specIndex != 1 {
+			t.Errorf("expected PrimaryIndex 2 to resolve to specs[1], got specs[%d]", specIndex)
+		}
+	})
+
+	t.Run("PrimaryInputItselfSkipped", func(t *testing.T) {
+		// inputs[0] (the designated primary) failed to open; only
+		// inputs[1] survived as specs[0].
+		specIndexByInput := map[int]int{1: 0}
+
+		specIndex, warn := resolveMergePrimarySpecIndex(0, specIndexByInput)
+		if warn == nil {
+			t.Fatal("expected a warning when the primary input was skipped")
+		}
+		if warn.InputIndex != 0 {
+			t.Errorf("expected warning to reference original input 0, got %d", warn.InputIndex)
+		}
+		if specIndex != 0 {
+			t.Errorf("expected fallback to specs[0], got specs[%d]", specIndex)
+		}
+	})
+}
+
+func TestParsePageRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		pageCnt  int
+		expected []int
+	}{
+		{"Empty", "", 3, []int{0, 1, 2}},
+		{"Single", "2", 5, []int{1}},
+		{"Range", "1-3", 5, []int{0, 1, 2}},
+		{"OpenEnded", "3-", 5, []int{2, 3, 4}},
+		{"LeadingDash", "-3", 5, []int{0, 1, 2}},
+		{"Mixed", "1-2,4", 5, []int{0, 1, 3}},
+		{"Even", "even", 6, []int{1, 3, 5}},
+		{"Odd", "odd", 6, []int{0, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := parsePageRange(tt.expr, tt.pageCnt)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(pages) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, pages)
+			}
+			for i, p := range pages {
+				if p != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, pages)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		if _, err := parsePageRange("10", 3); err == nil {
+			t.Error("Expected an error for an out-of-range page")
+		}
+	})
+
+	t.Run("InvalidClause", func(t *testing.T) {
+		if _, err := parsePageRange("abc", 3); err == nil {
+			t.Error("Expected an error for a malformed clause")
+		}
+	})
+}
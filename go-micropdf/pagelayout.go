@@ -0,0 +1,155 @@
+// Package micropdf - Document catalog page layout and page mode
+package micropdf
+
+// PageLayout identifies the document catalog's /PageLayout entry, which
+// tells a viewer how to lay out pages when the document is first opened.
+type PageLayout int
+
+const (
+	// SinglePage displays one page at a time.
+	SinglePage PageLayout = iota
+	// OneColumn displays pages in a single continuously scrolling column.
+	OneColumn
+	// TwoColumnLeft displays pages in two continuously scrolling
+	// columns, with odd-numbered pages on the left.
+	TwoColumnLeft
+	// TwoColumnRight displays pages in two continuously scrolling
+	// columns, with odd-numbered pages on the right.
+	TwoColumnRight
+	// TwoPageLeft displays the pages two at a time, with odd-numbered
+	// pages on the left.
+	TwoPageLeft
+	// TwoPageRight displays the pages two at a time, with odd-numbered
+	// pages on the right.
+	TwoPageRight
+)
+
+func (l PageLayout) String() string {
+	switch l {
+	case SinglePage:
+		return "SinglePage"
+	case OneColumn:
+		return "OneColumn"
+	case TwoColumnLeft:
+		return "TwoColumnLeft"
+	case TwoColumnRight:
+		return "TwoColumnRight"
+	case TwoPageLeft:
+		return "TwoPageLeft"
+	case TwoPageRight:
+		return "TwoPageRight"
+	default:
+		return "Unknown"
+	}
+}
+
+// PageMode identifies the document catalog's /PageMode entry, which
+// tells a viewer how to present UI chrome (outline pane, thumbnails,
+// full screen, etc.) when the document is first opened.
+type PageMode int
+
+const (
+	// UseNone shows neither an outline nor thumbnail panel.
+	UseNone PageMode = iota
+	// UseOutlines shows the outline (bookmarks) panel.
+	UseOutlines
+	// UseThumbs shows the thumbnail images panel.
+	UseThumbs
+	// FullScreen displays the document in full-screen mode, with no
+	// menu bar, window controls, or any other window visible.
+	FullScreen
+	// UseOC shows the optional content group (layers) panel.
+	UseOC
+	// UseAttachments shows the attachments panel.
+	UseAttachments
+)
+
+func (m PageMode) String() string {
+	switch m {
+	case UseNone:
+		return "UseNone"
+	case UseOutlines:
+		return "UseOutlines"
+	case UseThumbs:
+		return "UseThumbs"
+	case FullScreen:
+		return "FullScreen"
+	case UseOC:
+		return "UseOC"
+	case UseAttachments:
+		return "UseAttachments"
+	default:
+		return "Unknown"
+	}
+}
+
+// PageLayout returns doc's catalog /PageLayout entry.
+func (doc *Document) PageLayout() (PageLayout, error) {
+	if doc == nil || !doc.IsValid() {
+		return SinglePage, ErrInvalidHandle
+	}
+	layout, ok := documentPageLayoutNative(doc.Handle())
+	if !ok {
+		return SinglePage, ErrFailedToLoad
+	}
+	return PageLayout(layout), nil
+}
+
+// SetPageLayout sets doc's catalog /PageLayout entry to layout. The
+// change is only visible to callers that reopen or save the document;
+// call SaveAs to persist it.
+func (doc *Document) SetPageLayout(layout PageLayout) error {
+	if doc == nil || !doc.IsValid() {
+		return ErrInvalidHandle
+	}
+	if layout < SinglePage || layout > TwoPageRight {
+		return ErrArgument("invalid page layout")
+	}
+	if !documentSetPageLayoutNative(doc.Handle(), int(layout)) {
+		return ErrGeneric("failed to set page layout")
+	}
+	return nil
+}
+
+// PageMode returns doc's catalog /PageMode entry.
+func (doc *Document) PageMode() (PageMode, error) {
+	if doc == nil || !doc.IsValid() {
+		return UseNone, ErrInvalidHandle
+	}
+	mode, ok := documentPageModeNative(doc.Handle())
+	if !ok {
+		return UseNone, ErrFailedToLoad
+	}
+	return PageMode(mode), nil
+}
+
+// SetPageMode sets doc's catalog /PageMode entry to mode. The change is
+// only visible to callers that reopen or save the document; call SaveAs
+// to persist it.
+func (doc *Document) SetPageMode(mode PageMode) error {
+	if doc == nil || !doc.IsValid() {
+		return ErrInvalidHandle
+	}
+	if mode < UseNone || mode > UseAttachments {
+		return ErrArgument("invalid page mode")
+	}
+	if !documentSetPageModeNative(doc.Handle(), int(mode)) {
+		return ErrGeneric("failed to set page mode")
+	}
+	return nil
+}
+
+// SaveAs writes doc, including any pending PageLayout/PageMode changes,
+// to a new PDF file at path.
+func (doc *Document) SaveAs(path string) error {
+	if doc == nil || !doc.IsValid() {
+		return ErrInvalidHandle
+	}
+	if path == "" {
+		return ErrInvalidArgument
+	}
+	if !documentSaveAsNative(doc.Handle(), path) {
+		return ErrGeneric("failed to save document")
+	}
+	return nil
+}
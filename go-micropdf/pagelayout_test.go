@@ -0,0 +1,70 @@
+package micropdf
+
+import "testing"
+
+func TestPageLayoutString(t *testing.T) {
+	tests := []struct {
+		layout PageLayout
+		want   string
+	}{
+		{SinglePage, "SinglePage"},
+		{OneColumn, "OneColumn"},
+		{TwoColumnLeft, "TwoColumnLeft"},
+		{TwoColumnRight, "TwoColumnRight"},
+		{TwoPageLeft, "TwoPageLeft"},
+		{TwoPageRight, "TwoPageRight"},
+		{PageLayout(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.layout.String(); got != tt.want {
+			t.Errorf("PageLayout(%d).String() = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestPageModeString(t *testing.T) {
+	tests := []struct {
+		mode PageMode
+		want string
+	}{
+		{UseNone, "UseNone"},
+		{UseOutlines, "UseOutlines"},
+		{UseThumbs, "UseThumbs"},
+		{FullScreen, "FullScreen"},
+		{UseOC, "UseOC"},
+		{UseAttachments, "UseAttachments"},
+		{PageMode(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.String(); got != tt.want {
+			t.Errorf("PageMode(%d).String() = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentPageLayoutNilDocument(t *testing.T) {
+	var doc *Document
+	if _, err := doc.PageLayout(); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+	if err := doc.SetPageLayout(OneColumn); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
+
+func TestDocumentPageModeNilDocument(t *testing.T) {
+	var doc *Document
+	if _, err := doc.PageMode(); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+	if err := doc.SetPageMode(UseOutlines); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
+
+func TestDocumentSaveAsValidation(t *testing.T) {
+	var doc *Document
+	if err := doc.SaveAs("out.pdf"); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
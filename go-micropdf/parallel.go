@@ -0,0 +1,246 @@
+package micropdf
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultParallelThreshold is the minimum slice length below which the
+// *Parallel batch transforms fall back to the serial path — shorter
+// batches lose to goroutine spawn/sync overhead no matter how many cores
+// are available.
+const defaultParallelThreshold = 4096
+
+var parallelThreshold int64 = defaultParallelThreshold
+
+// SetParallelThreshold overrides the package-wide minimum slice length the
+// *Parallel batch transforms will shard across workers; shorter slices
+// always take the serial path. Safe to call concurrently with transforms
+// in flight. A ParallelOpts.MinChunkSize on an individual call overrides
+// this value for that call.
+func SetParallelThreshold(n int) {
+	if n < 0 {
+		n = 0
+	}
+	atomic.StoreInt64(&parallelThreshold, int64(n))
+}
+
+// ParallelOpts configures a *Parallel batch transform call.
+type ParallelOpts struct {
+	// Pool, if non-nil, supplies the worker goroutines instead of
+	// spawning a fresh set per call — use this in rendering loops that
+	// run many batch transforms back to back.
+	Pool *ParallelPool
+
+	// MinChunkSize overrides the sharding threshold for this call alone;
+	// zero uses the package-level default from SetParallelThreshold.
+	MinChunkSize int
+}
+
+func (o ParallelOpts) threshold() int {
+	if o.MinChunkSize > 0 {
+		return o.MinChunkSize
+	}
+	return int(atomic.LoadInt64(&parallelThreshold))
+}
+
+func (o ParallelOpts) numWorkers() int {
+	if o.Pool != nil {
+		return o.Pool.workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// ParallelPool is a reusable set of worker goroutines for driving the
+// *Parallel batch transforms without paying goroutine spawn cost on every
+// call — pass one via ParallelOpts.Pool in a rendering loop that
+// transforms many batches of glyph quads or path vertices back to back.
+type ParallelPool struct {
+	workers int
+	jobs    chan func()
+	done    chan struct{}
+}
+
+// NewParallelPool starts a ParallelPool with workers goroutines.
+// workers <= 0 defaults to runtime.GOMAXPROCS(0).
+func NewParallelPool(workers int) *ParallelPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	p := &ParallelPool{
+		workers: workers,
+		jobs:    make(chan func()),
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *ParallelPool) work() {
+	for {
+		select {
+		case job := <-p.jobs:
+			job()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// run submits jobs, one shard of a single batch transform call each, and
+// blocks until every shard has completed. Every worker pulls from the same
+// jobs channel, so a worker that finishes its shard early immediately
+// steals the next pending one rather than sitting idle behind a slower
+// worker's first shard.
+func (p *ParallelPool) run(jobs []func()) {
+	var wg sync.WaitGroup
+	wg.Add(len(jobs))
+	for _, job := range jobs {
+		job := job
+		p.jobs <- func() {
+			defer wg.Done()
+			job()
+		}
+	}
+	wg.Wait()
+}
+
+// Close stops the pool's worker goroutines. Call once the pool is no
+// longer needed; reusing it afterward blocks forever.
+func (p *ParallelPool) Close() {
+	close(p.done)
+}
+
+// runSharded divides [0, n) into up to opts' worker count contiguous
+// shards and runs fn(lo, hi) over each, in parallel via opts.Pool if one
+// was given or a fresh goroutine per shard otherwise. n is assumed to have
+// already cleared opts' threshold.
+func runSharded(n int, opts ParallelOpts, fn func(lo, hi int)) {
+	workers := opts.numWorkers()
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		fn(0, n)
+		return
+	}
+
+	chunk := (n + workers - 1) / workers
+	jobs := make([]func(), 0, workers)
+	for lo := 0; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+		lo, hi := lo, hi
+		jobs = append(jobs, func() { fn(lo, hi) })
+	}
+
+	if opts.Pool != nil {
+		opts.Pool.run(jobs)
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(jobs))
+	for _, job := range jobs {
+		job := job
+		go func() {
+			defer wg.Done()
+			job()
+		}()
+	}
+	wg.Wait()
+}
+
+// TransformPointsBatchParallel transforms points by m like
+// TransformPointsBatch, but shards the work across multiple goroutines
+// once len(points) reaches opts' threshold (4096 points by default),
+// amortizing goroutine overhead against the gain from parallel throughput.
+// Below the threshold it runs the serial path directly.
+func TransformPointsBatchParallel(points []Point, m Matrix, opts ParallelOpts) []Point {
+	n := len(points)
+	if n == 0 {
+		return points
+	}
+	result := make([]Point, n)
+	copy(result, points)
+	TransformPointsBatchInPlaceParallel(result, m, opts)
+	return result
+}
+
+// TransformPointsBatchInPlaceParallel is the in-place counterpart of
+// TransformPointsBatchParallel.
+func TransformPointsBatchInPlaceParallel(points []Point, m Matrix, opts ParallelOpts) {
+	n := len(points)
+	if n < opts.threshold() {
+		TransformPointsBatchInPlace(points, m)
+		return
+	}
+	runSharded(n, opts, func(lo, hi int) {
+		TransformPointsBatchInPlace(points[lo:hi], m)
+	})
+}
+
+// TransformRectsBatchParallel is the sharded counterpart of
+// TransformRectsBatch, used once len(rects) reaches opts' threshold.
+func TransformRectsBatchParallel(rects []Rect, m Matrix, opts ParallelOpts) []Rect {
+	n := len(rects)
+	if n == 0 {
+		return rects
+	}
+	if n < opts.threshold() {
+		return TransformRectsBatch(rects, m)
+	}
+
+	result := make([]Rect, n)
+	runSharded(n, opts, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			result[i] = m.TransformRect(rects[i])
+		}
+	})
+	return result
+}
+
+// TransformQuadsBatchParallel is the sharded counterpart of
+// TransformQuadsBatch, used once len(quads) reaches opts' threshold.
+func TransformQuadsBatchParallel(quads []Quad, m Matrix, opts ParallelOpts) []Quad {
+	n := len(quads)
+	if n == 0 {
+		return quads
+	}
+	if n < opts.threshold() {
+		return TransformQuadsBatch(quads, m)
+	}
+
+	result := make([]Quad, n)
+	runSharded(n, opts, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			result[i] = quads[i].Transform(m)
+		}
+	})
+	return result
+}
+
+// ApplyMatrixToFloatPairsParallel is the sharded counterpart of
+// ApplyMatrixToFloatPairs, used once the pair count reaches opts'
+// threshold.
+func ApplyMatrixToFloatPairsParallel(coords []float32, m Matrix, opts ParallelOpts) {
+	n := len(coords)
+	if n < 2 || n%2 != 0 {
+		return
+	}
+
+	pairs := n / 2
+	if pairs < opts.threshold() {
+		ApplyMatrixToFloatPairs(coords, m)
+		return
+	}
+	runSharded(pairs, opts, func(lo, hi int) {
+		ApplyMatrixToFloatPairs(coords[lo*2:hi*2], m)
+	})
+}
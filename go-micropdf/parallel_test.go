@@ -0,0 +1,171 @@
+package micropdf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTransformPointsBatchParallel(t *testing.T) {
+	m := MatrixScale(2, 2).Concat(MatrixTranslate(10, 20))
+
+	t.Run("MatchesSerialBelowThreshold", func(t *testing.T) {
+		points := make([]Point, 100)
+		for i := range points {
+			points[i] = Point{X: float32(i), Y: float32(-i)}
+		}
+		want := TransformPointsBatch(points, m)
+		got := TransformPointsBatchParallel(points, m, ParallelOpts{})
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("point %d: got %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("MatchesSerialAboveThresholdAdHocGoroutines", func(t *testing.T) {
+		points := make([]Point, 20000)
+		for i := range points {
+			points[i] = Point{X: float32(i % 997), Y: float32(i % 613)}
+		}
+		want := TransformPointsBatch(points, m)
+		got := TransformPointsBatchParallel(points, m, ParallelOpts{MinChunkSize: 1000})
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("point %d: got %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("MatchesSerialWithPool", func(t *testing.T) {
+		pool := NewParallelPool(4)
+		defer pool.Close()
+
+		points := make([]Point, 20000)
+		for i := range points {
+			points[i] = Point{X: float32(i % 997), Y: float32(i % 613)}
+		}
+		want := TransformPointsBatch(points, m)
+		got := TransformPointsBatchParallel(points, m, ParallelOpts{Pool: pool, MinChunkSize: 1000})
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("point %d: got %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("InPlaceMatchesNonInPlace", func(t *testing.T) {
+		points := make([]Point, 10000)
+		for i := range points {
+			points[i] = Point{X: float32(i), Y: float32(i * 2)}
+		}
+		want := TransformPointsBatchParallel(points, m, ParallelOpts{MinChunkSize: 500})
+
+		inPlace := make([]Point, len(points))
+		copy(inPlace, points)
+		TransformPointsBatchInPlaceParallel(inPlace, m, ParallelOpts{MinChunkSize: 500})
+
+		for i := range want {
+			if inPlace[i] != want[i] {
+				t.Fatalf("point %d: got %v, want %v", i, inPlace[i], want[i])
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if got := TransformPointsBatchParallel(nil, m, ParallelOpts{}); len(got) != 0 {
+			t.Errorf("expected no points, got %d", len(got))
+		}
+	})
+}
+
+func TestTransformRectsBatchParallel(t *testing.T) {
+	m := MatrixScale(2, 3)
+	rects := make([]Rect, 5000)
+	for i := range rects {
+		x := float32(i)
+		rects[i] = NewRect(x, x, x+5, x+5)
+	}
+
+	want := TransformRectsBatch(rects, m)
+	got := TransformRectsBatchParallel(rects, m, ParallelOpts{MinChunkSize: 250})
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rect %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTransformQuadsBatchParallel(t *testing.T) {
+	m := MatrixRotate(45)
+	quads := make([]Quad, 5000)
+	for i := range quads {
+		x := float32(i)
+		quads[i] = QuadFromRect(NewRect(x, x, x+5, x+5))
+	}
+
+	want := TransformQuadsBatch(quads, m)
+	got := TransformQuadsBatchParallel(quads, m, ParallelOpts{MinChunkSize: 250})
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("quad %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestApplyMatrixToFloatPairsParallel(t *testing.T) {
+	m := MatrixTranslate(1, -1)
+	coords := make([]float32, 20000)
+	for i := range coords {
+		coords[i] = float32(i)
+	}
+
+	want := append([]float32(nil), coords...)
+	ApplyMatrixToFloatPairs(want, m)
+
+	got := append([]float32(nil), coords...)
+	ApplyMatrixToFloatPairsParallel(got, m, ParallelOpts{MinChunkSize: 500})
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("coord %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// BenchmarkTransformPointsBatchParallel compares the serial batch path
+// against TransformPointsBatchParallel (both ad hoc goroutines and a
+// reused ParallelPool) at sizes crossing from clearly-not-worth-it up to
+// a full high-res page's worth of path vertices, to show where the
+// crossover against goroutine overhead actually lands.
+func BenchmarkTransformPointsBatchParallel(b *testing.B) {
+	m := MatrixScale(2, 2).Concat(MatrixRotate(45))
+
+	for _, n := range []int{10_000, 100_000, 1_000_000} {
+		points := make([]Point, n)
+		for i := range points {
+			points[i] = Point{X: float32(i), Y: float32(i)}
+		}
+
+		b.Run(fmt.Sprintf("Serial/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = TransformPointsBatch(points, m)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Parallel/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = TransformPointsBatchParallel(points, m, ParallelOpts{})
+			}
+		})
+
+		b.Run(fmt.Sprintf("ParallelPooled/%d", n), func(b *testing.B) {
+			pool := NewParallelPool(0)
+			defer pool.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = TransformPointsBatchParallel(points, m, ParallelOpts{Pool: pool})
+			}
+		})
+	}
+}
@@ -3,6 +3,13 @@
 
 package micropdf
 
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
 // ============================================================================
 // Batch Path Operations (Mock Implementation)
 //
@@ -133,20 +140,22 @@ func (p *Path) AddCommands(commands []PathCommand) *Path {
 // AddLines adds a series of connected line segments from coordinate pairs.
 // points should alternate: [x0, y0, x1, y1, x2, y2, ...]
 // The first point is a MoveTo, subsequent points are LineTo.
+//
+// Internally this builds a PackedPath and applies it in one Commit, so a
+// real cgo build crosses into C once for the whole series rather than
+// once per point.
 func (p *Path) AddLines(points []float32) *Path {
 	if len(points) < 2 {
 		return p
 	}
 
-	// First point is MoveTo
-	p.MoveTo(points[0], points[1])
-
-	// Rest are LineTo
+	pp := NewPackedPath(len(points) / 2)
+	pp.moveTo(points[0], points[1])
 	for i := 2; i+1 < len(points); i += 2 {
-		p.LineTo(points[i], points[i+1])
+		pp.lineTo(points[i], points[i+1])
 	}
 
-	return p
+	return pp.Commit(p)
 }
 
 // AddPolygon adds a closed polygon efficiently.
@@ -156,19 +165,24 @@ func (p *Path) AddPolygon(points []float32) *Path {
 		return p
 	}
 
-	p.AddLines(points)
-	p.ClosePath()
+	pp := NewPackedPath(len(points)/2 + 1)
+	pp.moveTo(points[0], points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		pp.lineTo(points[i], points[i+1])
+	}
+	pp.closePath()
 
-	return p
+	return pp.Commit(p)
 }
 
 // AddRects adds multiple rectangles efficiently.
 // rects should be: [x0, y0, w0, h0, x1, y1, w1, h1, ...]
 func (p *Path) AddRects(rects []float32) *Path {
+	pp := NewPackedPath(len(rects) / 4)
 	for i := 0; i+3 < len(rects); i += 4 {
-		p.RectTo(rects[i], rects[i+1], rects[i+2], rects[i+3])
+		pp.rectTo(rects[i], rects[i+1], rects[i+2], rects[i+3])
 	}
-	return p
+	return pp.Commit(p)
 }
 
 // AddRectsFromSlice adds rectangles from a slice of Rect structs
@@ -206,6 +220,92 @@ func (p *Path) AddClosedPolyline(points []Point) *Path {
 	return p
 }
 
+// ============================================================================
+// PackedPath - wire-efficient batch representation
+// ============================================================================
+
+// PackedPath is a flattened, parallel-slice encoding of a path: ops holds
+// one byte per operation (a PathOp value) and data holds that operation's
+// float32 arguments back to back, with no per-command padding. Unlike
+// []PathCommand (≈40 bytes per entry once the struct is padded),
+// PackedPath lets a real cgo build pass an entire path to
+// micropdf_path_apply_packed in a single call instead of one cgo
+// transition per operation.
+type PackedPath struct {
+	ops  []uint8
+	data []float32
+}
+
+// NewPackedPath returns an empty PackedPath whose slices are pre-sized
+// for roughly n operations.
+func NewPackedPath(n int) PackedPath {
+	if n < 0 {
+		n = 0
+	}
+	return PackedPath{
+		ops:  make([]uint8, 0, n),
+		data: make([]float32, 0, n*2),
+	}
+}
+
+func (pp *PackedPath) moveTo(x, y float32) {
+	pp.ops = append(pp.ops, uint8(PathOpMoveTo))
+	pp.data = append(pp.data, x, y)
+}
+
+func (pp *PackedPath) lineTo(x, y float32) {
+	pp.ops = append(pp.ops, uint8(PathOpLineTo))
+	pp.data = append(pp.data, x, y)
+}
+
+func (pp *PackedPath) curveTo(x1, y1, x2, y2, x3, y3 float32) {
+	pp.ops = append(pp.ops, uint8(PathOpCurveTo))
+	pp.data = append(pp.data, x1, y1, x2, y2, x3, y3)
+}
+
+func (pp *PackedPath) closePath() {
+	pp.ops = append(pp.ops, uint8(PathOpClosePath))
+}
+
+func (pp *PackedPath) rectTo(x, y, w, h float32) {
+	pp.ops = append(pp.ops, uint8(PathOpRectTo))
+	pp.data = append(pp.data, x, y, w, h)
+}
+
+// Commit applies every operation in pp to p. On a real cgo build this
+// performs a single micropdf_path_apply_packed(path, ops, nops, data,
+// ndata) call that dispatches internally; the mock build has no C
+// boundary to batch across, so it simply replays the operations.
+func (pp PackedPath) Commit(p *Path) *Path {
+	i := 0
+	for _, op := range pp.ops {
+		switch PathOp(op) {
+		case PathOpMoveTo:
+			p.MoveTo(pp.data[i], pp.data[i+1])
+			i += 2
+		case PathOpLineTo:
+			p.LineTo(pp.data[i], pp.data[i+1])
+			i += 2
+		case PathOpCurveTo:
+			p.CurveTo(pp.data[i], pp.data[i+1], pp.data[i+2], pp.data[i+3], pp.data[i+4], pp.data[i+5])
+			i += 6
+		case PathOpClosePath:
+			p.ClosePath()
+		case PathOpRectTo:
+			p.RectTo(pp.data[i], pp.data[i+1], pp.data[i+2], pp.data[i+3])
+			i += 4
+		}
+	}
+	return p
+}
+
+// AddPacked applies a PackedPath to p in a single batch. Prefer this over
+// AddCommands for large, generated paths (e.g. plot data) where avoiding
+// one cgo transition per point matters.
+func (p *Path) AddPacked(pp PackedPath) *Path {
+	return pp.Commit(p)
+}
+
 // ============================================================================
 // PathBuilder - Fluent API for batch path construction
 // ============================================================================
@@ -264,6 +364,327 @@ func (b *PathBuilder) BuildNew(ctx *Context) *Path {
 	return p.AddCommands(b.commands)
 }
 
+// Pack converts the accumulated commands into a PackedPath, ready for a
+// single-call Commit/AddPacked instead of one cgo transition per command.
+func (b *PathBuilder) Pack() PackedPath {
+	pp := NewPackedPath(len(b.commands))
+	for _, cmd := range b.commands {
+		switch cmd.Op {
+		case PathOpMoveTo:
+			pp.moveTo(cmd.X, cmd.Y)
+		case PathOpLineTo:
+			pp.lineTo(cmd.X, cmd.Y)
+		case PathOpCurveTo:
+			pp.curveTo(cmd.X1, cmd.Y1, cmd.X2, cmd.Y2, cmd.X3, cmd.Y3)
+		case PathOpClosePath:
+			pp.closePath()
+		case PathOpRectTo:
+			pp.rectTo(cmd.X, cmd.Y, cmd.X2, cmd.Y2)
+		}
+	}
+	return pp
+}
+
+// Transform applies m to every stored command's coordinates in place,
+// including both control points of each CurveToCmd, so a builder can be
+// built once and stamped at many locations without round-tripping through
+// a live Context. ClosePath carries no coordinates and is left alone.
+//
+// A RectTo's (X2, Y2) is a width/height displacement rather than a second
+// point, so it can't be transformed like one under an arbitrary Matrix
+// (rotation or skew would no longer describe an axis-aligned rectangle).
+// Transform instead expands each RectTo into its four transformed corners
+// as MoveTo/LineTo/ClosePath, which is exactly what RectTo means geometrically.
+func (b *PathBuilder) Transform(m Matrix) *PathBuilder {
+	transformed := make([]PathCommand, 0, len(b.commands))
+	for _, cmd := range b.commands {
+		switch cmd.Op {
+		case PathOpMoveTo, PathOpLineTo:
+			p := m.TransformPoint(Point{X: cmd.X, Y: cmd.Y})
+			cmd.X, cmd.Y = p.X, p.Y
+			transformed = append(transformed, cmd)
+		case PathOpCurveTo:
+			p1 := m.TransformPoint(Point{X: cmd.X1, Y: cmd.Y1})
+			p2 := m.TransformPoint(Point{X: cmd.X2, Y: cmd.Y2})
+			p3 := m.TransformPoint(Point{X: cmd.X3, Y: cmd.Y3})
+			cmd.X1, cmd.Y1 = p1.X, p1.Y
+			cmd.X2, cmd.Y2 = p2.X, p2.Y
+			cmd.X3, cmd.Y3 = p3.X, p3.Y
+			transformed = append(transformed, cmd)
+		case PathOpRectTo:
+			x, y, w, h := cmd.X, cmd.Y, cmd.X2, cmd.Y2
+			corners := [4]Point{
+				m.TransformPoint(Point{X: x, Y: y}),
+				m.TransformPoint(Point{X: x + w, Y: y}),
+				m.TransformPoint(Point{X: x + w, Y: y + h}),
+				m.TransformPoint(Point{X: x, Y: y + h}),
+			}
+			transformed = append(transformed,
+				NewMoveToCmd(corners[0].X, corners[0].Y),
+				NewLineToCmd(corners[1].X, corners[1].Y),
+				NewLineToCmd(corners[2].X, corners[2].Y),
+				NewLineToCmd(corners[3].X, corners[3].Y),
+				NewClosePathCmd(),
+			)
+		default:
+			transformed = append(transformed, cmd)
+		}
+	}
+	b.commands = transformed
+	return b
+}
+
+// Simplify runs Ramer-Douglas-Peucker on each contiguous run of LineToCmds
+// in place: within such a run, it recursively keeps the point with the
+// largest perpendicular distance from the chord between the run's
+// endpoints whenever that distance exceeds tolerance, and otherwise drops
+// every point in between. MoveTo, ClosePath, and CurveTo commands are left
+// untouched and act as boundaries a run can't cross.
+func (b *PathBuilder) Simplify(tolerance float32) *PathBuilder {
+	simplified := make([]PathCommand, 0, len(b.commands))
+
+	var subpathStart Point
+	i := 0
+	for i < len(b.commands) {
+		if b.commands[i].Op != PathOpLineTo {
+			if op := b.commands[i].Op; op == PathOpMoveTo || op == PathOpRectTo {
+				subpathStart = endpointOf(b.commands[i])
+			}
+			simplified = append(simplified, b.commands[i])
+			i++
+			continue
+		}
+
+		// anchor is the point the run of LineTos starts from: the
+		// preceding command's endpoint (always present, since a LineTo
+		// can't be the very first command in a well-formed path), or,
+		// after a ClosePath, the start of the subpath it closed.
+		var anchor Point
+		if n := len(simplified); n > 0 {
+			if simplified[n-1].Op == PathOpClosePath {
+				anchor = subpathStart
+			} else {
+				anchor = endpointOf(simplified[n-1])
+			}
+		}
+
+		j := i
+		for j < len(b.commands) && b.commands[j].Op == PathOpLineTo {
+			j++
+		}
+		run := b.commands[i:j]
+
+		points := make([]Point, 0, len(run)+1)
+		points = append(points, anchor)
+		for _, cmd := range run {
+			points = append(points, Point{X: cmd.X, Y: cmd.Y})
+		}
+
+		kept := rdpSimplify(points, tolerance)
+		for _, p := range kept[1:] { // [0] is anchor, already in simplified
+			simplified = append(simplified, NewLineToCmd(p.X, p.Y))
+		}
+
+		i = j
+	}
+
+	b.commands = simplified
+	return b
+}
+
+// endpointOf returns the (X, Y) a command leaves the pen at; ClosePath has
+// no coordinates of its own, so callers should only ask for the endpoint
+// of a MoveTo/LineTo/CurveTo/RectTo.
+func endpointOf(cmd PathCommand) Point {
+	switch cmd.Op {
+	case PathOpCurveTo:
+		return Point{X: cmd.X3, Y: cmd.Y3}
+	case PathOpRectTo:
+		return Point{X: cmd.X, Y: cmd.Y}
+	default:
+		return Point{X: cmd.X, Y: cmd.Y}
+	}
+}
+
+// rdpSimplify is the classic Ramer-Douglas-Peucker recursion over a
+// polyline given as endpoint-inclusive points, keeping points[0] and
+// points[len-1] plus whichever intermediate points fall outside tolerance
+// of the chord between them.
+func rdpSimplify(points []Point, tolerance float32) []Point {
+	if len(points) < 3 {
+		return points
+	}
+
+	first, last := points[0], points[len(points)-1]
+	maxDist := float32(-1)
+	maxIdx := -1
+	for i := 1; i < len(points)-1; i++ {
+		d := perpendicularDistance(points[i], first, last)
+		if d > maxDist {
+			maxDist = d
+			maxIdx = i
+		}
+	}
+
+	if maxDist <= tolerance {
+		return []Point{first, last}
+	}
+
+	left := rdpSimplify(points[:maxIdx+1], tolerance)
+	right := rdpSimplify(points[maxIdx:], tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// perpendicularDistance returns p's distance from the infinite line
+// through a and b, or from a itself when a == b (a zero-length chord).
+func perpendicularDistance(p, a, b Point) float32 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		ex, ey := p.X-a.X, p.Y-a.Y
+		return float32(math.Sqrt(float64(ex*ex + ey*ey)))
+	}
+	// |cross product| / |chord length| is the standard point-to-line
+	// distance formula, reused from the same technique contour.go's
+	// pointLineDeviation applies to curve flattening.
+	cross := dx*(a.Y-p.Y) - dy*(a.X-p.X)
+	return float32(math.Abs(float64(cross))) / float32(math.Sqrt(float64(lengthSq)))
+}
+
+// pathBinaryMagic and pathBinaryVersion identify PathBuilder's
+// MarshalBinary format, so UnmarshalBinary can reject anything else (or a
+// future incompatible version) instead of misreading garbage as commands.
+var pathBinaryMagic = [4]byte{'N', 'P', 'T', 'H'}
+
+const pathBinaryVersion uint16 = 1
+
+// pathOpArgCount is the number of float32 coordinates that follow each
+// PathOp's 1-byte opcode in the MarshalBinary encoding.
+func pathOpArgCount(op PathOp) int {
+	switch op {
+	case PathOpMoveTo, PathOpLineTo:
+		return 2
+	case PathOpCurveTo:
+		return 6
+	case PathOpRectTo:
+		return 4
+	case PathOpClosePath:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// MarshalBinary encodes b's accumulated commands as magic "NPTH" + a
+// uint16 version, followed by one opcode byte per command and that
+// command's float32 arguments (little-endian), back to back with no
+// padding. The result can be handed to UnmarshalBinary to rebuild an
+// equivalent builder without recomputation, e.g. to cache a simplified
+// path across process restarts.
+func (b *PathBuilder) MarshalBinary() ([]byte, error) {
+	size := 6 // magic + version
+	for _, cmd := range b.commands {
+		n := pathOpArgCount(cmd.Op)
+		if n < 0 {
+			return nil, fmt.Errorf("micropdf: cannot marshal path command with unknown op %d", cmd.Op)
+		}
+		size += 1 + n*4
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, pathBinaryMagic[:]...)
+	out = binary.LittleEndian.AppendUint16(out, pathBinaryVersion)
+
+	var argBuf [4]byte
+	appendFloat := func(f float32) {
+		binary.LittleEndian.PutUint32(argBuf[:], math.Float32bits(f))
+		out = append(out, argBuf[:]...)
+	}
+
+	for _, cmd := range b.commands {
+		out = append(out, uint8(cmd.Op))
+		switch cmd.Op {
+		case PathOpMoveTo, PathOpLineTo:
+			appendFloat(cmd.X)
+			appendFloat(cmd.Y)
+		case PathOpCurveTo:
+			appendFloat(cmd.X1)
+			appendFloat(cmd.Y1)
+			appendFloat(cmd.X2)
+			appendFloat(cmd.Y2)
+			appendFloat(cmd.X3)
+			appendFloat(cmd.Y3)
+		case PathOpRectTo:
+			appendFloat(cmd.X)
+			appendFloat(cmd.Y)
+			appendFloat(cmd.X2)
+			appendFloat(cmd.Y2)
+		case PathOpClosePath:
+			// no arguments
+		}
+	}
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes data produced by MarshalBinary, replacing b's
+// accumulated commands. It rejects data with the wrong magic, an
+// unsupported version, or a truncated/malformed command stream.
+func (b *PathBuilder) UnmarshalBinary(data []byte) error {
+	if len(data) < 6 {
+		return fmt.Errorf("micropdf: path binary data too short (%d bytes)", len(data))
+	}
+	if !bytes.Equal(data[:4], pathBinaryMagic[:]) {
+		return fmt.Errorf("micropdf: path binary data has wrong magic %q", data[:4])
+	}
+	version := binary.LittleEndian.Uint16(data[4:6])
+	if version != pathBinaryVersion {
+		return fmt.Errorf("micropdf: unsupported path binary version %d", version)
+	}
+
+	readFloat := func(buf []byte) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(buf))
+	}
+
+	commands := make([]PathCommand, 0)
+	r := data[6:]
+	for len(r) > 0 {
+		op := PathOp(r[0])
+		r = r[1:]
+
+		n := pathOpArgCount(op)
+		if n < 0 {
+			return fmt.Errorf("micropdf: path binary data has unknown opcode %d", op)
+		}
+		if len(r) < n*4 {
+			return fmt.Errorf("micropdf: path binary data truncated in command arguments")
+		}
+
+		var cmd PathCommand
+		switch op {
+		case PathOpMoveTo:
+			cmd = NewMoveToCmd(readFloat(r[0:4]), readFloat(r[4:8]))
+		case PathOpLineTo:
+			cmd = NewLineToCmd(readFloat(r[0:4]), readFloat(r[4:8]))
+		case PathOpCurveTo:
+			cmd = NewCurveToCmd(
+				readFloat(r[0:4]), readFloat(r[4:8]),
+				readFloat(r[8:12]), readFloat(r[12:16]),
+				readFloat(r[16:20]), readFloat(r[20:24]),
+			)
+		case PathOpRectTo:
+			cmd = NewRectToCmd(readFloat(r[0:4]), readFloat(r[4:8]), readFloat(r[8:12]), readFloat(r[12:16]))
+		case PathOpClosePath:
+			cmd = NewClosePathCmd()
+		}
+		commands = append(commands, cmd)
+		r = r[n*4:]
+	}
+
+	b.commands = commands
+	return nil
+}
+
 // Reset clears all accumulated commands
 func (b *PathBuilder) Reset() *PathBuilder {
 	b.commands = b.commands[:0]
@@ -139,6 +139,28 @@ func TestPathAddCommands(t *testing.T) {
 	path.AddCommands(commands)
 }
 
+func TestPathBuilderPack(t *testing.T) {
+	ctx := NewContext()
+	if ctx == nil {
+		t.Fatal("Failed to create context")
+	}
+	defer ctx.Drop()
+
+	builder := NewPathBuilder().
+		MoveTo(0, 0).
+		LineTo(100, 0).
+		LineTo(100, 100).
+		LineTo(0, 100).
+		Close()
+
+	packed := builder.Pack()
+
+	path := NewPath(ctx)
+	defer path.Drop()
+
+	path.AddPacked(packed)
+}
+
 func TestPathBuilderReset(t *testing.T) {
 	builder := NewPathBuilder().
 		MoveTo(0, 0).
@@ -192,6 +214,179 @@ func BenchmarkPathBatchLines(b *testing.B) {
 	}
 }
 
+func BenchmarkPathAddLines100k(b *testing.B) {
+	ctx := NewContext()
+	if ctx == nil {
+		b.Fatal("Failed to create context")
+	}
+	defer ctx.Drop()
+
+	points := make([]float32, 200000)
+	for i := range points {
+		points[i] = float32(i % 1000)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		path := NewPath(ctx)
+		path.AddLines(points)
+		path.Drop()
+	}
+}
+
+func BenchmarkPathAddPacked100k(b *testing.B) {
+	ctx := NewContext()
+	if ctx == nil {
+		b.Fatal("Failed to create context")
+	}
+	defer ctx.Drop()
+
+	points := make([]float32, 200000)
+	for i := range points {
+		points[i] = float32(i % 1000)
+	}
+
+	builder := NewPathBuilder().MoveTo(points[0], points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		builder.LineTo(points[i], points[i+1])
+	}
+	packed := builder.Pack()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		path := NewPath(ctx)
+		path.AddPacked(packed)
+		path.Drop()
+	}
+}
+
+func TestPathBuilderTransformMoveLineCurve(t *testing.T) {
+	builder := NewPathBuilder().
+		MoveTo(0, 0).
+		LineTo(10, 0).
+		CurveTo(10, 5, 10, 10, 0, 10)
+
+	builder.Transform(MatrixTranslate(100, 200))
+
+	cmds := builder.Commands()
+	if cmds[0].X != 100 || cmds[0].Y != 200 {
+		t.Errorf("MoveTo not translated: got (%v, %v)", cmds[0].X, cmds[0].Y)
+	}
+	if cmds[1].X != 110 || cmds[1].Y != 200 {
+		t.Errorf("LineTo not translated: got (%v, %v)", cmds[1].X, cmds[1].Y)
+	}
+	curve := cmds[2]
+	if curve.X1 != 110 || curve.Y1 != 205 || curve.X2 != 110 || curve.Y2 != 210 || curve.X3 != 100 || curve.Y3 != 210 {
+		t.Errorf("CurveTo control points not translated: got %+v", curve)
+	}
+}
+
+func TestPathBuilderTransformRect(t *testing.T) {
+	builder := NewPathBuilder().Rect(0, 0, 10, 20)
+	builder.Transform(MatrixTranslate(5, 5))
+
+	cmds := builder.Commands()
+	if len(cmds) != 5 {
+		t.Fatalf("expected RectTo to expand into 5 commands, got %d", len(cmds))
+	}
+	if cmds[0].Op != PathOpMoveTo || cmds[0].X != 5 || cmds[0].Y != 5 {
+		t.Errorf("unexpected first corner: %+v", cmds[0])
+	}
+	if cmds[4].Op != PathOpClosePath {
+		t.Errorf("expected trailing ClosePath, got %+v", cmds[4])
+	}
+}
+
+func TestPathBuilderSimplify(t *testing.T) {
+	builder := NewPathBuilder().
+		MoveTo(0, 0).
+		LineTo(5, 0.01).
+		LineTo(10, 0).
+		Close()
+
+	builder.Simplify(1.0)
+
+	cmds := builder.Commands()
+	if len(cmds) != 3 {
+		t.Fatalf("expected collinear-ish points to collapse to 3 commands, got %d: %+v", len(cmds), cmds)
+	}
+	if cmds[1].X != 10 || cmds[1].Y != 0 {
+		t.Errorf("expected the simplified LineTo to reach the run's endpoint, got %+v", cmds[1])
+	}
+}
+
+func TestPathBuilderSimplifyKeepsSignificantDeviation(t *testing.T) {
+	builder := NewPathBuilder().
+		MoveTo(0, 0).
+		LineTo(5, 100).
+		LineTo(10, 0)
+
+	builder.Simplify(1.0)
+
+	if builder.Len() != 3 {
+		t.Errorf("expected the deviating midpoint to survive Simplify, got %d commands", builder.Len())
+	}
+}
+
+func TestPathBuilderMarshalUnmarshalBinaryRoundTrip(t *testing.T) {
+	original := NewPathBuilder().
+		MoveTo(1, 2).
+		LineTo(3, 4).
+		CurveTo(5, 6, 7, 8, 9, 10).
+		Rect(11, 12, 13, 14).
+		Close()
+
+	data, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	restored := NewPathBuilder()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	want := original.Commands()
+	got := restored.Commands()
+	if len(got) != len(want) {
+		t.Fatalf("command count mismatch: got %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("command %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	ctx := NewContext()
+	if ctx == nil {
+		t.Fatal("Failed to create context")
+	}
+	defer ctx.Drop()
+	path := restored.BuildNew(ctx)
+	defer path.Drop()
+}
+
+func TestPathBuilderUnmarshalBinaryRejectsBadMagic(t *testing.T) {
+	builder := NewPathBuilder()
+	if err := builder.UnmarshalBinary([]byte("XXXX\x01\x00")); err == nil {
+		t.Error("expected an error for data with the wrong magic")
+	}
+}
+
+func TestPathBuilderUnmarshalBinaryRejectsTruncatedData(t *testing.T) {
+	data, err := NewPathBuilder().LineTo(1, 2).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	builder := NewPathBuilder()
+	if err := builder.UnmarshalBinary(data[:len(data)-2]); err == nil {
+		t.Error("expected an error for truncated command data")
+	}
+}
+
 func BenchmarkPathBuilder(b *testing.B) {
 	ctx := NewContext()
 	if ctx == nil {
@@ -0,0 +1,197 @@
+// Package micropdf - Concurrent batch processing
+package micropdf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// Concurrency is the number of worker contexts to run. Defaults to 1
+	// when zero; fz_context is not thread-safe, so each worker gets its
+	// own Context rather than sharing one across goroutines.
+	Concurrency int
+
+	// PerFileTimeout bounds how long a single file is allowed to process
+	// before fn is abandoned for that file and an error is recorded.
+	// Zero means no per-file timeout.
+	PerFileTimeout time.Duration
+}
+
+// Result is the outcome of processing a single file through a Pool.
+type Result[T any] struct {
+	Path  string
+	Value T
+	Err   error
+}
+
+// Stats reports Pool throughput and per-worker resource usage.
+type Stats struct {
+	FilesProcessed int64
+	FilesFailed    int64
+	Elapsed        time.Duration
+	WorkerHandles  []int
+}
+
+// Pool manages N worker Contexts for processing many documents
+// concurrently. Because fz_context is not thread-safe, each goroutine
+// gets its own Context rather than sharing one across goroutines. Each
+// worker holds only that Context and a HandleTracker; it keeps no
+// scratch-buffer pool of its own across files.
+type Pool[T any] struct {
+	opts    PoolOptions
+	workers []*poolWorker
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+type poolWorker struct {
+	ctx     *Context
+	tracker *HandleTracker
+}
+
+// NewPool creates a Pool with opts.Concurrency worker contexts.
+func NewPool[T any](opts PoolOptions) *Pool[T] {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	p := &Pool[T]{opts: opts}
+	p.workers = make([]*poolWorker, opts.Concurrency)
+	for i := range p.workers {
+		p.workers[i] = &poolWorker{
+			ctx:     NewContext(),
+			tracker: NewHandleTracker(),
+		}
+	}
+	return p
+}
+
+// Close drops every worker's Context. Call once the Pool is no longer
+// needed.
+func (p *Pool[T]) Close() {
+	for _, w := range p.workers {
+		if w.ctx != nil {
+			w.ctx.Drop()
+		}
+	}
+}
+
+// ProcessFiles opens and processes each path with fn, spreading the work
+// across the pool's workers. fn receives the worker's own Context and the
+// opened Document; the Document is dropped automatically after fn
+// returns. Results are returned in the same order as paths regardless of
+// completion order. Canceling ctx stops dispatching new files and makes
+// in-flight results return context.Canceled.
+func (p *Pool[T]) ProcessFiles(ctx context.Context, paths []string, fn func(*Context, *Document) (T, error)) ([]Result[T], error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	results := make([]Result[T], len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for _, w := range p.workers {
+		wg.Add(1)
+		go func(w *poolWorker) {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = p.processOne(ctx, w, paths[idx], fn)
+			}
+		}(w)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for i := range paths {
+			select {
+			case <-ctx.Done():
+				// Every path from here on was never handed to a worker.
+				// Mark each distinctly with ctx.Err() rather than leaving
+				// it at its Result[T] zero value, which would otherwise
+				// read as "processed successfully" to both callers and
+				// the FilesProcessed/FilesFailed stats below.
+				for j := i; j < len(paths); j++ {
+					results[j] = Result[T]{Path: paths[j], Err: ctx.Err()}
+				}
+				return
+			case jobs <- i:
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	p.mu.Lock()
+	p.stats.Elapsed += time.Since(start)
+	for _, r := range results {
+		if r.Err != nil {
+			p.stats.FilesFailed++
+		} else {
+			p.stats.FilesProcessed++
+		}
+	}
+	p.mu.Unlock()
+
+	return results, ctx.Err()
+}
+
+func (p *Pool[T]) processOne(ctx context.Context, w *poolWorker, path string, fn func(*Context, *Document) (T, error)) Result[T] {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return Result[T]{Path: path, Err: err}
+	}
+
+	callCtx := ctx
+	var cancel context.CancelFunc
+	if p.opts.PerFileTimeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, p.opts.PerFileTimeout)
+		defer cancel()
+	}
+
+	doc, err := OpenDocument(w.ctx, path)
+	if err != nil {
+		return Result[T]{Path: path, Value: zero, Err: err}
+	}
+
+	type outcome struct {
+		val T
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		val, err := fn(w.ctx, doc)
+		doc.Drop() // only once fn has returned, even if the timeout fired first
+		done <- outcome{val, err}
+	}()
+
+	select {
+	case <-callCtx.Done():
+		return Result[T]{Path: path, Value: zero, Err: callCtx.Err()}
+	case o := <-done:
+		return Result[T]{Path: path, Value: o.val, Err: o.err}
+	}
+}
+
+// Stats reports aggregate throughput and per-worker handle counts via
+// each worker's HandleTracker.
+func (p *Pool[T]) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.WorkerHandles = make([]int, len(p.workers))
+	for i, w := range p.workers {
+		stats.WorkerHandles[i] = w.tracker.HandleCount()
+	}
+	return stats
+}
@@ -0,0 +1,64 @@
+package micropdf
+
+import (
+	"context"
+	"testing"
+)
+
+// TestProcessFilesCancellationMarksUndispatchedEntries uses a Pool with
+// no workers (jobs is never drained) and an already-cancelled context, so
+// ProcessFiles' dispatcher is forced down its ctx.Done() branch for every
+// path deterministically, without needing a real Document to open.
+func TestProcessFilesCancellationMarksUndispatchedEntries(t *testing.T) {
+	pool := &Pool[int]{}
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	paths := []string{"a.pdf", "b.pdf", "c.pdf"}
+	results, err := pool.ProcessFiles(ctx, paths, func(*Context, *Document) (int, error) {
+		return 0, nil
+	})
+
+	if err != context.Canceled {
+		t.Errorf("expected ProcessFiles to return context.Canceled, got %v", err)
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	for i, r := range results {
+		if r.Err != context.Canceled {
+			t.Errorf("result %d: expected context.Canceled, got %v (path=%q)", i, r.Err, r.Path)
+		}
+		if r.Path != paths[i] {
+			t.Errorf("result %d: expected Path %q, got %q", i, paths[i], r.Path)
+		}
+	}
+
+	stats := pool.Stats()
+	if stats.FilesFailed != int64(len(paths)) {
+		t.Errorf("expected all %d undispatched entries counted as FilesFailed, got %d", len(paths), stats.FilesFailed)
+	}
+	if stats.FilesProcessed != 0 {
+		t.Errorf("expected 0 entries counted as FilesProcessed, got %d", stats.FilesProcessed)
+	}
+}
+
+// TestProcessFilesUncancelledReturnsNilError makes sure the new ctx.Err()
+// return value doesn't turn an ordinary, non-cancelled batch into an
+// error result. It uses a real (single-worker) Pool; "a.pdf" doesn't
+// exist, so OpenDocument fails before fn ever runs, but that per-file
+// error must not surface as ProcessFiles' top-level error.
+func TestProcessFilesUncancelledReturnsNilError(t *testing.T) {
+	pool := NewPool[int](PoolOptions{Concurrency: 1})
+	defer pool.Close()
+
+	paths := []string{"a.pdf"}
+	_, err := pool.ProcessFiles(context.Background(), paths, func(*Context, *Document) (int, error) {
+		return 0, nil
+	})
+	if err != nil {
+		t.Errorf("expected nil error for an uncancelled batch, got %v", err)
+	}
+}
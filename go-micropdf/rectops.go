@@ -0,0 +1,214 @@
+// Package micropdf - Batch rect/quad set operations for text highlighting
+// and hit-testing.
+package micropdf
+
+import "sort"
+
+// UnionRects returns the smallest Rect containing every rect in rs, or
+// the zero Rect if rs is empty.
+func UnionRects(rs []Rect) Rect {
+	if len(rs) == 0 {
+		return Rect{}
+	}
+	r := rs[0]
+	for _, other := range rs[1:] {
+		r = r.Union(other)
+	}
+	return r
+}
+
+// IntersectRects returns the area common to every rect in rs. The result
+// IsEmpty if rs is empty or the rects don't all overlap.
+func IntersectRects(rs []Rect) Rect {
+	if len(rs) == 0 {
+		return Rect{}
+	}
+	r := rs[0]
+	for _, other := range rs[1:] {
+		r = r.Intersect(other)
+		if r.IsEmpty() {
+			return r
+		}
+	}
+	return r
+}
+
+// MergeRects partitions rs into area-connected groups — two rects are in
+// the same group when they overlap or lie within gap pixels of each
+// other on both axes — and returns one bounding rect per group, via a
+// left-to-right sweep over the 2n x-edges with an active set of
+// still-open rects rather than an O(n^2) all-pairs comparison. This is
+// the shape text selection wants: glyph rects on the same line collapse
+// into a single highlight rect across small word-gap spacing, while
+// separate lines or columns stay distinct rects.
+func MergeRects(rs []Rect, gap float32) []Rect {
+	n := len(rs)
+	if n == 0 {
+		return nil
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return rs[order[a]].X0 < rs[order[b]].X0 })
+
+	uf := newUnionFind(n)
+	var active []int
+
+	for _, i := range order {
+		ri := rs[i]
+
+		kept := active[:0]
+		for _, j := range active {
+			if rs[j].X1+gap < ri.X0 {
+				continue // j's x-edge is behind ri.X0-gap; it can no longer touch anything from here on
+			}
+			kept = append(kept, j)
+			if rectsAdjacent(ri, rs[j], gap) {
+				uf.union(i, j)
+			}
+		}
+		active = append(kept, i)
+	}
+
+	groups := make(map[int][]Rect, n)
+	for i, r := range rs {
+		root := uf.find(i)
+		groups[root] = append(groups[root], r)
+	}
+
+	out := make([]Rect, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, UnionRects(g))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].X0 != out[j].X0 {
+			return out[i].X0 < out[j].X0
+		}
+		return out[i].Y0 < out[j].Y0
+	})
+	return out
+}
+
+// rectsAdjacent reports whether a and b overlap or lie within gap pixels
+// of each other on both axes.
+func rectsAdjacent(a, b Rect, gap float32) bool {
+	return a.X0 <= b.X1+gap && b.X0 <= a.X1+gap &&
+		a.Y0 <= b.Y1+gap && b.Y0 <= a.Y1+gap
+}
+
+// unionFind is a disjoint-set structure over the indices of a []Rect
+// slice, used by MergeRects to track which rects have already been
+// joined into the same group without re-comparing every pair.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+	}
+	return uf
+}
+
+func (uf *unionFind) find(x int) int {
+	for uf.parent[x] != x {
+		uf.parent[x] = uf.parent[uf.parent[x]]
+		x = uf.parent[x]
+	}
+	return x
+}
+
+func (uf *unionFind) union(a, b int) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra != rb {
+		uf.parent[ra] = rb
+	}
+}
+
+// SubtractRect returns the portion of a not covered by b, as up to four
+// non-overlapping rects (the top/bottom full-width strips and the
+// left/right strips flanking the intersection), for computing redaction
+// masks that must cover a region except for one removed sub-rect.
+// Returns []Rect{a} unchanged if a and b don't overlap.
+func SubtractRect(a, b Rect) []Rect {
+	inter := a.Intersect(b)
+	if inter.IsEmpty() {
+		return []Rect{a}
+	}
+
+	var out []Rect
+	if inter.Y0 > a.Y0 {
+		out = append(out, NewRect(a.X0, a.Y0, a.X1, inter.Y0)) // top strip
+	}
+	if inter.Y1 < a.Y1 {
+		out = append(out, NewRect(a.X0, inter.Y1, a.X1, a.Y1)) // bottom strip
+	}
+	if inter.X0 > a.X0 {
+		out = append(out, NewRect(a.X0, inter.Y0, inter.X0, inter.Y1)) // left strip
+	}
+	if inter.X1 < a.X1 {
+		out = append(out, NewRect(inter.X1, inter.Y0, a.X1, inter.Y1)) // right strip
+	}
+	return out
+}
+
+// Intersects reports whether q and other overlap, via the separating
+// axis theorem over both quads' four edge normals — the general test for
+// two convex (possibly rotated) quadrilaterals, needed because rotated
+// text-selection quads can't be compared with axis-aligned Rect logic.
+func (q Quad) Intersects(other Quad) bool {
+	for _, axis := range q.edgeNormals() {
+		if !overlapOnAxis(q.corners(), other.corners(), axis) {
+			return false
+		}
+	}
+	for _, axis := range other.edgeNormals() {
+		if !overlapOnAxis(q.corners(), other.corners(), axis) {
+			return false
+		}
+	}
+	return true
+}
+
+// corners returns q's four corners in edge order (UL -> UR -> LR -> LL).
+func (q Quad) corners() [4]Point {
+	return [4]Point{q.UL, q.UR, q.LR, q.LL}
+}
+
+// edgeNormals returns the outward normal of each of q's four edges, the
+// candidate separating axes SAT must check for q.
+func (q Quad) edgeNormals() [4]Point {
+	c := q.corners()
+	var normals [4]Point
+	for i := range c {
+		edge := c[(i+1)%4].Sub(c[i])
+		normals[i] = Point{X: -edge.Y, Y: edge.X}
+	}
+	return normals
+}
+
+// overlapOnAxis reports whether a's and b's corners, projected onto
+// axis, have overlapping ranges.
+func overlapOnAxis(a, b [4]Point, axis Point) bool {
+	aMin, aMax := projectOntoAxis(a, axis)
+	bMin, bMax := projectOntoAxis(b, axis)
+	return aMin <= bMax && bMin <= aMax
+}
+
+func projectOntoAxis(pts [4]Point, axis Point) (min, max float32) {
+	min = pts[0].X*axis.X + pts[0].Y*axis.Y
+	max = min
+	for _, p := range pts[1:] {
+		d := p.X*axis.X + p.Y*axis.Y
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return min, max
+}
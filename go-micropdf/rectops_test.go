@@ -0,0 +1,171 @@
+package micropdf
+
+import (
+	"testing"
+)
+
+func TestUnionRects(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		if r := UnionRects(nil); r != (Rect{}) {
+			t.Errorf("UnionRects(nil) = %+v, want zero Rect", r)
+		}
+	})
+
+	t.Run("Several", func(t *testing.T) {
+		rs := []Rect{NewRect(0, 0, 10, 10), NewRect(5, 5, 20, 8), NewRect(-5, 2, 3, 30)}
+		got := UnionRects(rs)
+		want := NewRect(-5, 0, 20, 30)
+		if got != want {
+			t.Errorf("UnionRects() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestIntersectRects(t *testing.T) {
+	t.Run("Overlapping", func(t *testing.T) {
+		rs := []Rect{NewRect(0, 0, 10, 10), NewRect(5, 5, 15, 15), NewRect(2, 2, 8, 8)}
+		got := IntersectRects(rs)
+		want := NewRect(5, 5, 8, 8)
+		if got != want {
+			t.Errorf("IntersectRects() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Disjoint", func(t *testing.T) {
+		rs := []Rect{NewRect(0, 0, 1, 1), NewRect(5, 5, 6, 6)}
+		if got := IntersectRects(rs); !got.IsEmpty() {
+			t.Errorf("IntersectRects() = %+v, want empty", got)
+		}
+	})
+}
+
+func TestMergeRects(t *testing.T) {
+	t.Run("AdjacentGlyphsCollapseToOneLine", func(t *testing.T) {
+		// Four glyph rects on one line, each separated by a 1pt word gap.
+		rs := []Rect{
+			NewRect(0, 0, 10, 12),
+			NewRect(11, 0, 20, 12),
+			NewRect(21, 0, 30, 12),
+			NewRect(31, 0, 40, 12),
+		}
+		got := MergeRects(rs, 1.5)
+		if len(got) != 1 {
+			t.Fatalf("MergeRects() = %d rects, want 1: %+v", len(got), got)
+		}
+		if want := NewRect(0, 0, 40, 12); got[0] != want {
+			t.Errorf("MergeRects()[0] = %+v, want %+v", got[0], want)
+		}
+	})
+
+	t.Run("SeparateLinesStayDistinct", func(t *testing.T) {
+		rs := []Rect{
+			NewRect(0, 0, 10, 12),
+			NewRect(0, 50, 10, 62),
+		}
+		got := MergeRects(rs, 1.5)
+		if len(got) != 2 {
+			t.Fatalf("MergeRects() = %d rects, want 2: %+v", len(got), got)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if got := MergeRects(nil, 1); got != nil {
+			t.Errorf("MergeRects(nil) = %+v, want nil", got)
+		}
+	})
+}
+
+func TestSubtractRect(t *testing.T) {
+	t.Run("NoOverlap", func(t *testing.T) {
+		a := NewRect(0, 0, 10, 10)
+		b := NewRect(20, 20, 30, 30)
+		got := SubtractRect(a, b)
+		if len(got) != 1 || got[0] != a {
+			t.Errorf("SubtractRect() = %+v, want []Rect{a}", got)
+		}
+	})
+
+	t.Run("CenterHole", func(t *testing.T) {
+		a := NewRect(0, 0, 10, 10)
+		b := NewRect(3, 3, 7, 7)
+		got := SubtractRect(a, b)
+		if len(got) != 4 {
+			t.Fatalf("SubtractRect() = %d rects, want 4: %+v", len(got), got)
+		}
+
+		// The union of a's remainder plus b's intersection with a should
+		// reconstruct a's full area; check no returned piece overlaps b.
+		for _, r := range got {
+			if !r.Intersect(b).IsEmpty() {
+				t.Errorf("SubtractRect() piece %+v overlaps the subtracted rect %+v", r, b)
+			}
+		}
+	})
+
+	t.Run("FullyCovered", func(t *testing.T) {
+		a := NewRect(0, 0, 10, 10)
+		b := NewRect(-5, -5, 15, 15)
+		if got := SubtractRect(a, b); len(got) != 0 {
+			t.Errorf("SubtractRect() = %+v, want empty when b fully covers a", got)
+		}
+	})
+}
+
+func TestQuadIntersects(t *testing.T) {
+	t.Run("AxisAlignedOverlap", func(t *testing.T) {
+		q1 := QuadFromRect(NewRect(0, 0, 10, 10))
+		q2 := QuadFromRect(NewRect(5, 5, 15, 15))
+		if !q1.Intersects(q2) {
+			t.Error("expected overlapping quads to intersect")
+		}
+	})
+
+	t.Run("AxisAlignedDisjoint", func(t *testing.T) {
+		q1 := QuadFromRect(NewRect(0, 0, 10, 10))
+		q2 := QuadFromRect(NewRect(20, 20, 30, 30))
+		if q1.Intersects(q2) {
+			t.Error("expected disjoint quads not to intersect")
+		}
+	})
+
+	t.Run("RotatedOverlap", func(t *testing.T) {
+		base := QuadFromRect(NewRect(-5, -5, 5, 5))
+		m := MatrixRotate(45)
+		rotated := base.Transform(m)
+		if !rotated.Intersects(base) {
+			t.Error("expected a quad rotated about a shared center to intersect its unrotated original")
+		}
+	})
+
+	t.Run("RotatedSeparated", func(t *testing.T) {
+		q1 := QuadFromRect(NewRect(0, 0, 10, 10)).Transform(MatrixRotate(45))
+		q2 := QuadFromRect(NewRect(100, 100, 110, 110)).Transform(MatrixRotate(45))
+		if q1.Intersects(q2) {
+			t.Error("expected far-apart rotated quads not to intersect")
+		}
+	})
+}
+
+// BenchmarkMergeRects10000Glyphs simulates merging glyph rects across
+// roughly 200 lines of 50 glyphs each, the shape a full-page text
+// selection produces, to demonstrate MergeRects' sweep stays near
+// O(n log n) rather than degrading to O(n^2) as the active set grows.
+func BenchmarkMergeRects10000Glyphs(b *testing.B) {
+	const lines = 200
+	const glyphsPerLine = 50
+
+	rs := make([]Rect, 0, lines*glyphsPerLine)
+	for line := 0; line < lines; line++ {
+		y0 := float32(line) * 14
+		for g := 0; g < glyphsPerLine; g++ {
+			x0 := float32(g) * 9
+			rs = append(rs, NewRect(x0, y0, x0+8, y0+12))
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = MergeRects(rs, 1.5)
+	}
+}
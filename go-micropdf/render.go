@@ -0,0 +1,264 @@
+// Package micropdf - Bounded rendering
+package micropdf
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"math"
+)
+
+// RenderOptions controls how a page is rasterized.
+//
+// The zero value renders at 72 DPI with no pixel budget. Set MaxPixels
+// and/or MaxDPI to cap the size of the output; when both are set, the
+// effective DPI is whichever produces the smaller image.
+type RenderOptions struct {
+	// DPI is the requested resolution. Reduced automatically when it would
+	// exceed the budget computed from MaxPixels/MaxDPI.
+	DPI int32
+
+	// MaxPixels bounds the total pixel count (width * height) of the
+	// rendered pixmap. Zero means unbounded.
+	MaxPixels int64
+
+	// MaxDPI bounds the resolution regardless of page size. Zero means
+	// unbounded.
+	MaxDPI int32
+
+	// Alpha renders with an alpha channel when true.
+	Alpha bool
+
+	// PageRange selects which pages RenderPagesTo renders, using the same
+	// expression language as MergePDFsAdvanced ("1-5,8,10-", "even", ...).
+	// Empty means every page. Ignored by RenderToPNGBounded and RenderAll,
+	// which always render the single page or document they're called on.
+	PageRange string
+
+	// ColorMode converts each rendered page to the given color space
+	// before encoding. The zero value is ColorRGB.
+	ColorMode ColorMode
+
+	// Format selects RenderPagesTo's output container. The zero value is
+	// FormatPNG.
+	Format OutputFormat
+}
+
+// ColorMode selects the color space RenderPagesTo converts rendered pages
+// into before encoding.
+type ColorMode int
+
+const (
+	// ColorRGB keeps the rendered page in its native RGB(A) form.
+	ColorRGB ColorMode = iota
+	// ColorGray converts the rendered page to 8-bit grayscale.
+	ColorGray
+	// ColorCMYK converts the rendered page to 8-bit CMYK.
+	ColorCMYK
+)
+
+func (m ColorMode) String() string {
+	switch m {
+	case ColorRGB:
+		return "ColorRGB"
+	case ColorGray:
+		return "ColorGray"
+	case ColorCMYK:
+		return "ColorCMYK"
+	default:
+		return "Unknown"
+	}
+}
+
+// OutputFormat selects the container RenderPagesTo streams rendered pages
+// into.
+type OutputFormat int
+
+const (
+	// FormatPNG writes a single page as a bare PNG. RenderPagesTo rejects
+	// a page range resolving to anything but exactly one page.
+	FormatPNG OutputFormat = iota
+	// FormatTar streams pages as an uncompressed tar archive.
+	FormatTar
+	// FormatZip streams pages as a zip archive.
+	FormatZip
+	// FormatTIFF writes all resolved pages as a single multi-page TIFF.
+	FormatTIFF
+)
+
+func (f OutputFormat) String() string {
+	switch f {
+	case FormatPNG:
+		return "FormatPNG"
+	case FormatTar:
+		return "FormatTar"
+	case FormatZip:
+		return "FormatZip"
+	case FormatTIFF:
+		return "FormatTIFF"
+	default:
+		return "Unknown"
+	}
+}
+
+// defaultRenderDPI is used when RenderOptions.DPI is unset.
+const defaultRenderDPI = 72
+
+// effectiveDPI picks the largest DPI that keeps the rendered page within
+// opts.MaxPixels and opts.MaxDPI, given the page's unscaled size in points.
+func effectiveDPI(pageWidth, pageHeight float32, opts RenderOptions) int32 {
+	dpi := opts.DPI
+	if dpi <= 0 {
+		dpi = defaultRenderDPI
+	}
+
+	if opts.MaxDPI > 0 && dpi > opts.MaxDPI {
+		dpi = opts.MaxDPI
+	}
+
+	if opts.MaxPixels > 0 && pageWidth > 0 && pageHeight > 0 {
+		pixelsAt := func(d int32) float64 {
+			w := float64(pageWidth) * float64(d) / 72.0
+			h := float64(pageHeight) * float64(d) / 72.0
+			return w * h
+		}
+
+		if pixelsAt(dpi) > float64(opts.MaxPixels) {
+			// (w*d/72)*(h*d/72) = MaxPixels  =>  d = 72*sqrt(MaxPixels/(w*h))
+			maxD := 72.0 * math.Sqrt(float64(opts.MaxPixels)/(float64(pageWidth)*float64(pageHeight)))
+			if maxD < float64(dpi) {
+				dpi = int32(maxD)
+			}
+		}
+	}
+
+	if dpi < 1 {
+		dpi = 1
+	}
+	return dpi
+}
+
+// RenderToPNGBounded renders the page to PNG at the largest DPI that fits
+// within opts.MaxPixels/opts.MaxDPI rather than always honoring opts.DPI
+// verbatim, and reports the DPI it actually used.
+//
+// This is the batch-OCR-pipeline pattern of only generating full-size
+// output when the caller can afford it: a fixed worst-case MaxPixels lets
+// a pool of workers process pages of wildly different sizes without any
+// one oversized page blowing the memory budget.
+func (p *Page) RenderToPNGBounded(opts RenderOptions) ([]byte, int32, error) {
+	var buf bytes.Buffer
+	dpi, err := p.renderToPNGBounded(&buf, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), dpi, nil
+}
+
+// renderToPNGBounded does the work for RenderToPNGBounded, writing directly
+// to w so a caller streaming to disk or a network connection never needs
+// the whole encoded PNG resident at once.
+func (p *Page) renderToPNGBounded(w io.Writer, opts RenderOptions) (int32, error) {
+	if p == nil || !p.IsValid() {
+		return 0, ErrInvalidHandle
+	}
+
+	bounds := p.Bounds()
+	dpi := effectiveDPI(bounds.Width(), bounds.Height(), opts)
+
+	scale := float32(dpi) / 72.0
+	pix, err := p.RenderToPixmap(MatrixScale(scale, scale), opts.Alpha)
+	if err != nil {
+		return 0, err
+	}
+	defer pix.Drop()
+
+	if err := encodePixmapPNG(w, pix); err != nil {
+		return 0, ErrRenderFailed
+	}
+	return dpi, nil
+}
+
+// UserToDevice returns the matrix that maps p's user-space coordinates
+// (points, origin top-left) to device-space pixels at the given DPI,
+// the same scale RenderToPNGBounded derives internally.
+func (p *Page) UserToDevice(dpi int32) Matrix {
+	scale := float32(dpi) / 72.0
+	return MatrixScale(scale, scale)
+}
+
+// DeviceToUser returns the matrix that maps device-space pixels at the
+// given DPI back to p's user-space coordinates, along with false if that
+// DPI produces a degenerate (non-invertible) transform.
+func (p *Page) DeviceToUser(dpi int32) (Matrix, bool) {
+	return p.UserToDevice(dpi).Invert()
+}
+
+// encodePixmapPNG converts a rendered Pixmap's raw samples into a Go image
+// and streams it out as PNG, so bounded rendering does not depend on the
+// native WritePNG path materializing the full-resolution encoding.
+func encodePixmapPNG(w io.Writer, pix *Pixmap) error {
+	width, err := pix.Width()
+	if err != nil {
+		return err
+	}
+	height, err := pix.Height()
+	if err != nil {
+		return err
+	}
+	n, err := pix.N()
+	if err != nil {
+		return err
+	}
+	samples, err := pix.Samples()
+	if err != nil {
+		return err
+	}
+
+	img, err := pixmapToImage(width, height, n, samples)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// pixmapToImage wraps raw interleaved pixel samples in the stdlib image.Image
+// that matches their component count. 1- and 4-component samples are used
+// without copying; 2- and 3-component samples are copied into a new
+// 4-component NRGBA buffer.
+func pixmapToImage(width, height, n int, samples []byte) (image.Image, error) {
+	switch n {
+	case 1:
+		img := &image.Gray{Pix: samples, Stride: width, Rect: image.Rect(0, 0, width, height)}
+		return img, nil
+	case 2:
+		img := &image.NRGBA{Pix: expandGrayAlpha(samples), Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+		return img, nil
+	case 3:
+		img := &image.NRGBA{Pix: expandRGB(samples), Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+		return img, nil
+	case 4:
+		img := &image.NRGBA{Pix: samples, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+		return img, nil
+	default:
+		return nil, ErrUnsupported("unsupported pixmap component count for PNG encoding")
+	}
+}
+
+func expandGrayAlpha(samples []byte) []byte {
+	out := make([]byte, 0, len(samples)*2)
+	for i := 0; i+1 < len(samples); i += 2 {
+		g, a := samples[i], samples[i+1]
+		out = append(out, g, g, g, a)
+	}
+	return out
+}
+
+func expandRGB(samples []byte) []byte {
+	out := make([]byte, 0, len(samples)/3*4)
+	for i := 0; i+2 < len(samples); i += 3 {
+		out = append(out, samples[i], samples[i+1], samples[i+2], 0xff)
+	}
+	return out
+}
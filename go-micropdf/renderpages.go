@@ -0,0 +1,173 @@
+// Package micropdf - Multi-format page rendering output
+package micropdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// RenderPagesTo renders the pages opts.PageRange selects (default: all)
+// and streams them to w in opts.Format, converting to opts.ColorMode
+// first. This is the "-" tar-to-stdout convention build tools use for
+// streaming output into another process (an OCR pipeline, for example)
+// without an intermediate directory of files.
+func (doc *Document) RenderPagesTo(w io.Writer, opts RenderOptions) error {
+	if doc == nil || !doc.IsValid() {
+		return ErrInvalidHandle
+	}
+	if w == nil {
+		return ErrInvalidArgument
+	}
+
+	pageCount, err := doc.PageCount()
+	if err != nil {
+		return err
+	}
+
+	indices, err := parsePageRange(opts.PageRange, int(pageCount))
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == FormatPNG && len(indices) != 1 {
+		return ErrArgument("FormatPNG requires a page range resolving to exactly one page")
+	}
+
+	pages, err := renderPagesConverted(doc, indices, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case FormatPNG:
+		_, err := w.Write(pages[0].png)
+		if err != nil {
+			return WrapError(ErrCodeSystem, "failed to write png", err)
+		}
+		return nil
+	case FormatTar:
+		return writePagesToSink(NewTarSink(w), pages)
+	case FormatZip:
+		return writePagesToSink(NewZipSink(w), pages)
+	case FormatTIFF:
+		tiffPages := make([]tiffPage, len(pages))
+		for i, p := range pages {
+			tiffPages[i] = p.tiff
+		}
+		return writeTIFF(w, tiffPages)
+	default:
+		return ErrArgument("unsupported output format")
+	}
+}
+
+// renderedPage holds one resolved page's output in whichever shapes its
+// eventual container needs: encoded PNG bytes for FormatPNG/Tar/Zip, and
+// raw samples for FormatTIFF.
+type renderedPage struct {
+	index int
+	png   []byte
+	tiff  tiffPage
+}
+
+// renderPagesConverted renders each of indices (0-based) through
+// RenderToPNGBounded, applies opts.ColorMode, and returns both PNG and
+// raw-sample encodings so callers don't re-render per output format.
+func renderPagesConverted(doc *Document, indices []int, opts RenderOptions) ([]renderedPage, error) {
+	out := make([]renderedPage, len(indices))
+	for i, idx := range indices {
+		page, err := doc.LoadPage(int32(idx))
+		if err != nil {
+			return nil, WrapError(ErrCodeSystem, fmt.Sprintf("failed to load page %d", idx), err)
+		}
+
+		data, _, err := page.RenderToPNGBounded(opts)
+		page.Drop()
+		if err != nil {
+			return nil, WrapError(ErrCodeSystem, fmt.Sprintf("failed to render page %d", idx), err)
+		}
+
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, WrapError(ErrCodeSystem, fmt.Sprintf("failed to decode rendered page %d", idx), err)
+		}
+
+		converted, tp := convertColorMode(img, opts.ColorMode)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, converted); err != nil {
+			return nil, WrapError(ErrCodeSystem, fmt.Sprintf("failed to encode page %d", idx), err)
+		}
+
+		out[i] = renderedPage{index: idx, png: buf.Bytes(), tiff: tp}
+	}
+	return out, nil
+}
+
+// convertColorMode converts img to mode, returning both a Go image (for
+// PNG re-encoding) and the equivalent raw-sample tiffPage (for FormatTIFF),
+// so both output paths agree on what was rendered.
+func convertColorMode(img image.Image, mode ColorMode) (image.Image, tiffPage) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	switch mode {
+	case ColorGray:
+		gray := image.NewGray(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				gray.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+		return gray, tiffPage{width: width, height: height, samples: 1, photometric: tiffPhotometricBlackIsZero, pix: gray.Pix}
+	case ColorCMYK:
+		cmyk := image.NewCMYK(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				cmyk.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+		// image/png has no CMYK encoder, so the PNG copy is kept in RGB;
+		// only the TIFF path preserves the actual CMYK samples.
+		rgb := cmykToRGBA(cmyk, width, height)
+		return rgb, tiffPage{width: width, height: height, samples: 4, photometric: tiffPhotometricSeparated, pix: cmyk.Pix}
+	default:
+		rgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				rgba.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+		pix := make([]byte, 0, width*height*3)
+		for i := 0; i+3 < len(rgba.Pix); i += 4 {
+			pix = append(pix, rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2])
+		}
+		return rgba, tiffPage{width: width, height: height, samples: 3, photometric: tiffPhotometricRGB, pix: pix}
+	}
+}
+
+func cmykToRGBA(cmyk *image.CMYK, width, height int) image.Image {
+	rgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := color.NRGBAModel.Convert(cmyk.At(x, y)).RGBA()
+			rgba.Set(x, y, color.NRGBA{R: byte(r >> 8), G: byte(g >> 8), B: byte(b >> 8), A: byte(a >> 8)})
+		}
+	}
+	return rgba
+}
+
+// writePagesToSink streams pages into sink under their canonical
+// "page-%04d.png" names, matching Document.RenderAll's naming convention.
+func writePagesToSink(sink OutputSink, pages []renderedPage) error {
+	for _, p := range pages {
+		name := fmt.Sprintf("page-%04d.png", p.index+1)
+		if err := sink.WritePage(p.index, name, p.png); err != nil {
+			return err
+		}
+	}
+	return sink.Close()
+}
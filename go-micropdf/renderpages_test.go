@@ -0,0 +1,68 @@
+package micropdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestColorModeString(t *testing.T) {
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{ColorRGB, "ColorRGB"},
+		{ColorGray, "ColorGray"},
+		{ColorCMYK, "ColorCMYK"},
+		{ColorMode(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.String(); got != tt.want {
+			t.Errorf("ColorMode(%d).String() = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestOutputFormatString(t *testing.T) {
+	tests := []struct {
+		format OutputFormat
+		want   string
+	}{
+		{FormatPNG, "FormatPNG"},
+		{FormatTar, "FormatTar"},
+		{FormatZip, "FormatZip"},
+		{FormatTIFF, "FormatTIFF"},
+		{OutputFormat(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.String(); got != tt.want {
+			t.Errorf("OutputFormat(%d).String() = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPagesToNilDocument(t *testing.T) {
+	var doc *Document
+	var buf bytes.Buffer
+	if err := doc.RenderPagesTo(&buf, RenderOptions{}); err != ErrInvalidHandle {
+		t.Errorf("Expected ErrInvalidHandle, got %v", err)
+	}
+}
+
+func TestWriteTIFFEmptyPages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTIFF(&buf, nil); err != ErrInvalidArgument {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestWriteTIFFHeader(t *testing.T) {
+	var buf bytes.Buffer
+	page := tiffPage{width: 2, height: 1, samples: 1, photometric: tiffPhotometricBlackIsZero, pix: []byte{0x11, 0x22}}
+	if err := writeTIFF(&buf, []tiffPage{page}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := buf.Bytes()
+	if len(out) < 8 || out[0] != 'I' || out[1] != 'I' || out[2] != 42 {
+		t.Fatalf("Unexpected TIFF header: %v", out[:8])
+	}
+}
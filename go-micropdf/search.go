@@ -0,0 +1,48 @@
+// Package micropdf - text search, returning match locations as Quads
+// suitable for highlighting.
+package micropdf
+
+// SearchText finds every occurrence of query on p, returning one Quad per
+// match in page space. The actual text layout/search work happens in the
+// native backend via searchTextNative, which — like the rest of this
+// package's native bridge functions — has no implementation in this
+// source tree.
+func (p *Page) SearchText(query string) ([]Quad, error) {
+	if p == nil || !p.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+	if query == "" {
+		return nil, ErrInvalidArgument
+	}
+	return searchTextNative(p.Handle(), query)
+}
+
+// SearchInPath behaves like SearchText, but discards every match whose
+// quad center doesn't lie inside path (per path's FillRule). This lets a
+// caller restrict a search to a clipping region, an annotation's shape, or
+// any other Contour-bounded area of interest instead of the whole page.
+func (p *Page) SearchInPath(query string, path *Contour) ([]Quad, error) {
+	quads, err := p.SearchText(query)
+	if err != nil {
+		return nil, err
+	}
+	if path == nil {
+		return quads, nil
+	}
+
+	filtered := make([]Quad, 0, len(quads))
+	for _, q := range quads {
+		if path.Contains(quadCenter(q)) {
+			filtered = append(filtered, q)
+		}
+	}
+	return filtered, nil
+}
+
+// quadCenter returns the average of q's four corners.
+func quadCenter(q Quad) Point {
+	return Point{
+		X: (q.UL.X + q.UR.X + q.LL.X + q.LR.X) / 4,
+		Y: (q.UL.Y + q.UR.Y + q.LL.Y + q.LR.Y) / 4,
+	}
+}
@@ -3,18 +3,23 @@ package micropdf
 // ============================================================================
 // SIMD Batch Transforms
 //
-// This module provides optimized batch operations for geometry types using
-// SIMD instructions where available. Falls back to scalar operations on
-// unsupported platforms.
+// This module provides optimized batch operations for geometry types. The
+// point transform kernels dispatch to a hand-written AVX2+FMA3 or NEON
+// assembly backend in internal/simd when the CPU supports one, falling
+// back to a scalar loop otherwise; the remaining batch helpers below still
+// rely on Go's auto-vectorization via manual loop unrolling.
 //
 // Design goals:
 // - Minimize function call overhead by processing arrays in batch
-// - Use Go's auto-vectorization where possible
-// - Provide explicit SIMD hints for the compiler
+// - Use a real SIMD backend for the hottest kernel (point transforms)
+// - Use Go's auto-vectorization where a dedicated backend isn't worth it
 // ============================================================================
 
 import (
 	"math"
+	"unsafe"
+
+	"github.com/lexmata/micropdf/go-micropdf/internal/simd"
 )
 
 // ============================================================================
@@ -22,89 +27,35 @@ import (
 // ============================================================================
 
 // TransformPointsBatch transforms multiple points by a single matrix.
-// Optimized for batch processing with manual loop unrolling.
 func TransformPointsBatch(points []Point, m Matrix) []Point {
-	n := len(points)
-	if n == 0 {
+	if len(points) == 0 {
 		return points
 	}
 
-	result := make([]Point, n)
-
-	// Process 4 points at a time for better cache locality and potential SIMD
-	i := 0
-	for ; i+4 <= n; i += 4 {
-		// Unrolled loop - allows compiler to use SIMD
-		p0, p1, p2, p3 := points[i], points[i+1], points[i+2], points[i+3]
-
-		result[i] = Point{
-			X: p0.X*m.A + p0.Y*m.C + m.E,
-			Y: p0.X*m.B + p0.Y*m.D + m.F,
-		}
-		result[i+1] = Point{
-			X: p1.X*m.A + p1.Y*m.C + m.E,
-			Y: p1.X*m.B + p1.Y*m.D + m.F,
-		}
-		result[i+2] = Point{
-			X: p2.X*m.A + p2.Y*m.C + m.E,
-			Y: p2.X*m.B + p2.Y*m.D + m.F,
-		}
-		result[i+3] = Point{
-			X: p3.X*m.A + p3.Y*m.C + m.E,
-			Y: p3.X*m.B + p3.Y*m.D + m.F,
-		}
-	}
-
-	// Handle remaining points
-	for ; i < n; i++ {
-		p := points[i]
-		result[i] = Point{
-			X: p.X*m.A + p.Y*m.C + m.E,
-			Y: p.X*m.B + p.Y*m.D + m.F,
-		}
-	}
-
+	result := make([]Point, len(points))
+	copy(result, points)
+	TransformPointsBatchInPlace(result, m)
 	return result
 }
 
 // TransformPointsBatchInPlace transforms points in-place by a matrix.
 // More memory efficient than TransformPointsBatch for large arrays.
 func TransformPointsBatchInPlace(points []Point, m Matrix) {
-	n := len(points)
-	if n == 0 {
+	if len(points) == 0 {
 		return
 	}
+	simd.TransformPoints(pointsAsFloat32(points), m.A, m.B, m.C, m.D, m.E, m.F)
+}
 
-	// Process 4 points at a time
-	i := 0
-	for ; i+4 <= n; i += 4 {
-		p0, p1, p2, p3 := points[i], points[i+1], points[i+2], points[i+3]
-
-		points[i] = Point{
-			X: p0.X*m.A + p0.Y*m.C + m.E,
-			Y: p0.X*m.B + p0.Y*m.D + m.F,
-		}
-		points[i+1] = Point{
-			X: p1.X*m.A + p1.Y*m.C + m.E,
-			Y: p1.X*m.B + p1.Y*m.D + m.F,
-		}
-		points[i+2] = Point{
-			X: p2.X*m.A + p2.Y*m.C + m.E,
-			Y: p2.X*m.B + p2.Y*m.D + m.F,
-		}
-		points[i+3] = Point{
-			X: p3.X*m.A + p3.Y*m.C + m.E,
-			Y: p3.X*m.B + p3.Y*m.D + m.F,
-		}
-	}
-
-	for ; i < n; i++ {
-		p := points[i]
-		points[i] = Point{
-			X: p.X*m.A + p.Y*m.C + m.E,
-			Y: p.X*m.B + p.Y*m.D + m.F,
-		}
+// pointsAsFloat32 reinterprets points as a flat slice of interleaved
+// (x, y) pairs without copying. This is safe because Point is exactly two
+// contiguous float32 fields with no padding, so its memory layout is
+// identical to [2]float32.
+func pointsAsFloat32(points []Point) []float32 {
+	if len(points) == 0 {
+		return nil
 	}
+	return unsafe.Slice((*float32)(unsafe.Pointer(&points[0])), len(points)*2)
 }
 
 // ============================================================================
@@ -391,6 +342,105 @@ func FilterPointsInRect(r Rect, points []Point) []Point {
 	return result
 }
 
+// ============================================================================
+// Batch Bezier Operations
+// ============================================================================
+
+// TransformCubicsBatch transforms every control point of each curve in
+// curves through m, reusing the same per-point arithmetic as
+// TransformPointsBatch since a cubic's four control points transform
+// exactly like any other batch of points under an affine matrix.
+func TransformCubicsBatch(curves []CubicBezier, m Matrix) []CubicBezier {
+	n := len(curves)
+	if n == 0 {
+		return curves
+	}
+
+	result := make([]CubicBezier, n)
+	for i, c := range curves {
+		result[i] = CubicBezier{
+			P0: Point{X: c.P0.X*m.A + c.P0.Y*m.C + m.E, Y: c.P0.X*m.B + c.P0.Y*m.D + m.F},
+			P1: Point{X: c.P1.X*m.A + c.P1.Y*m.C + m.E, Y: c.P1.X*m.B + c.P1.Y*m.D + m.F},
+			P2: Point{X: c.P2.X*m.A + c.P2.Y*m.C + m.E, Y: c.P2.X*m.B + c.P2.Y*m.D + m.F},
+			P3: Point{X: c.P3.X*m.A + c.P3.Y*m.C + m.E, Y: c.P3.X*m.B + c.P3.Y*m.D + m.F},
+		}
+	}
+	return result
+}
+
+// CubicBoundsBatch computes each curve's tight bounding box (via
+// CubicBezier.Bounds' analytic derivative-root method, not just the
+// control-point hull) in batch.
+func CubicBoundsBatch(curves []CubicBezier) []Rect {
+	if len(curves) == 0 {
+		return nil
+	}
+
+	result := make([]Rect, len(curves))
+	for i, c := range curves {
+		result[i] = c.Bounds()
+	}
+	return result
+}
+
+// maxFlattenDepth bounds FlattenCubicsBatch's recursive subdivision so a
+// pathological curve (near-cusp control points) can't recurse
+// unboundedly before the flatness test is satisfied.
+const maxFlattenDepth = 24
+
+// FlattenCubicsBatch flattens every curve in curves into a polyline
+// within tolerance via recursive de Casteljau subdivision, and
+// concatenates each curve's points (starting with its own P0) into a
+// single slice in curve order — the shape path rasterization wants: one
+// contiguous chain of line segments approximating every curve segment of
+// a path.
+func FlattenCubicsBatch(curves []CubicBezier, tolerance float32) []Point {
+	var out []Point
+	for _, c := range curves {
+		out = append(out, c.P0)
+		flattenCubic(c, tolerance, maxFlattenDepth, &out)
+	}
+	return out
+}
+
+// flattenCubic recursively subdivides c until each piece is flat (per
+// cubicIsFlat) or depth runs out, appending each piece's endpoint to out.
+func flattenCubic(c CubicBezier, tolerance float32, depth int, out *[]Point) {
+	if depth <= 0 || cubicIsFlat(c, tolerance) {
+		*out = append(*out, c.P3)
+		return
+	}
+
+	left, right := c.Split(0.5)
+	flattenCubic(left, tolerance, depth-1, out)
+	flattenCubic(right, tolerance, depth-1, out)
+}
+
+// cubicIsFlat reports whether c is close enough to its chord P0->P3 to
+// approximate with a single line segment, by testing the perpendicular
+// distance from both interior control points to that chord against
+// tolerance.
+func cubicIsFlat(c CubicBezier, tolerance float32) bool {
+	return perpDistance(c.P1, c.P0, c.P3) <= tolerance && perpDistance(c.P2, c.P0, c.P3) <= tolerance
+}
+
+// perpDistance returns the perpendicular distance from p to the line
+// through a and b, or the distance to a if a and b coincide.
+func perpDistance(p, a, b Point) float32 {
+	abx, aby := b.X-a.X, b.Y-a.Y
+	length := float32(math.Hypot(float64(abx), float64(aby)))
+	if length == 0 {
+		return p.Distance(a)
+	}
+
+	apx, apy := p.X-a.X, p.Y-a.Y
+	cross := abx*apy - aby*apx
+	if cross < 0 {
+		cross = -cross
+	}
+	return cross / length
+}
+
 // ============================================================================
 // Matrix Batch Operations
 // ============================================================================
@@ -412,33 +462,81 @@ func ConcatMatricesBatch(matrices []Matrix) Matrix {
 // ApplyMatrixToFloatPairs transforms x,y coordinate pairs stored in a flat array.
 // Useful for path data where coordinates are stored as [x0, y0, x1, y1, ...]
 func ApplyMatrixToFloatPairs(coords []float32, m Matrix) {
-	n := len(coords)
-	if n < 2 || n%2 != 0 {
+	if len(coords) < 2 || len(coords)%2 != 0 {
 		return
 	}
+	simd.TransformPoints(coords, m.A, m.B, m.C, m.D, m.E, m.F)
+}
 
-	// Process 4 coordinate pairs (8 floats) at a time
-	i := 0
-	for ; i+8 <= n; i += 8 {
-		x0, y0 := coords[i], coords[i+1]
-		x1, y1 := coords[i+2], coords[i+3]
-		x2, y2 := coords[i+4], coords[i+5]
-		x3, y3 := coords[i+6], coords[i+7]
-
-		coords[i] = x0*m.A + y0*m.C + m.E
-		coords[i+1] = x0*m.B + y0*m.D + m.F
-		coords[i+2] = x1*m.A + y1*m.C + m.E
-		coords[i+3] = x1*m.B + y1*m.D + m.F
-		coords[i+4] = x2*m.A + y2*m.C + m.E
-		coords[i+5] = x2*m.B + y2*m.D + m.F
-		coords[i+6] = x3*m.A + y3*m.C + m.E
-		coords[i+7] = x3*m.B + y3*m.D + m.F
-	}
-
-	// Handle remaining pairs
-	for ; i < n; i += 2 {
-		x, y := coords[i], coords[i+1]
-		coords[i] = x*m.A + y*m.C + m.E
-		coords[i+1] = x*m.B + y*m.D + m.F
+// ============================================================================
+// Strided/Offset Batch Transforms (BLAS level-1 style)
+// ============================================================================
+//
+// These mirror the incX/offsetX parameters of BLAS routines like saxpy:
+// callers pass a packed buffer plus an offset and element stride rather
+// than a dense []Point, so a vertex buffer interleaving position with
+// other per-vertex fields (z, pressure, color, tag, ...) can be
+// transformed in place without first copying the x/y fields out.
+
+// TransformPointsStrided transforms n (x, y) pairs packed in coords: the
+// i-th pair's x is coords[offset+i*stride] and its y is the float that
+// follows it. Requires stride >= 2. Writes the transformed coordinates
+// back in place.
+func TransformPointsStrided(coords []float32, n, offset, stride int, m Matrix) {
+	for i := 0; i < n; i++ {
+		idx := offset + i*stride
+		x, y := coords[idx], coords[idx+1]
+		coords[idx] = x*m.A + y*m.C + m.E
+		coords[idx+1] = x*m.B + y*m.D + m.F
+	}
+}
+
+// TransformPointsStridedAlphaBeta computes, for each of n strided (x, y)
+// pairs read from src, dst[i] = alpha*(M*src[i]) + beta*dst[i] — the
+// axpy/gemv fused multiply-add pattern from BLAS — writing into dst at
+// its own offset/stride. src and dst may be the same buffer (with the
+// same offset/stride) for an in-place blend, or entirely separate
+// buffers. This is the shape an animation/warp pipeline wants for
+// blending a freshly transformed control point with its value from the
+// previous frame: alpha=0.3, beta=0.7 for a simple exponential smooth.
+func TransformPointsStridedAlphaBeta(dst []float32, dstOffset, dstStride int, src []float32, srcOffset, srcStride, n int, m Matrix, alpha, beta float32) {
+	for i := 0; i < n; i++ {
+		si := srcOffset + i*srcStride
+		di := dstOffset + i*dstStride
+
+		x, y := src[si], src[si+1]
+		tx := x*m.A + y*m.C + m.E
+		ty := x*m.B + y*m.D + m.F
+
+		dst[di] = alpha*tx + beta*dst[di]
+		dst[di+1] = alpha*ty + beta*dst[di+1]
+	}
+}
+
+// TransformRectsStrided transforms n rects packed in coords as
+// consecutive (x0, y0, x1, y1) quadruples at the given offset/stride
+// (stride >= 4), writing each rect's transformed axis-aligned bounding
+// box back in place.
+func TransformRectsStrided(coords []float32, n, offset, stride int, m Matrix) {
+	for i := 0; i < n; i++ {
+		idx := offset + i*stride
+		r := Rect{X0: coords[idx], Y0: coords[idx+1], X1: coords[idx+2], Y1: coords[idx+3]}
+		out := m.TransformRect(r)
+		coords[idx], coords[idx+1], coords[idx+2], coords[idx+3] = out.X0, out.Y0, out.X1, out.Y1
+	}
+}
+
+// TransformQuadsStrided transforms n quads packed in coords as eight
+// consecutive floats per quad — (UL.x, UL.y, UR.x, UR.y, LL.x, LL.y,
+// LR.x, LR.y), matching Quad's field order — at the given offset/stride
+// (stride >= 8), writing each transformed corner back in place.
+func TransformQuadsStrided(coords []float32, n, offset, stride int, m Matrix) {
+	for i := 0; i < n; i++ {
+		idx := offset + i*stride
+		for c := 0; c < 8; c += 2 {
+			x, y := coords[idx+c], coords[idx+c+1]
+			coords[idx+c] = x*m.A + y*m.C + m.E
+			coords[idx+c+1] = x*m.B + y*m.D + m.F
+		}
 	}
 }
@@ -314,3 +314,179 @@ func BenchmarkApplyMatrixToFloatPairs_1000(b *testing.B) {
 		ApplyMatrixToFloatPairs(coords, m)
 	}
 }
+
+func TestTransformPointsStrided(t *testing.T) {
+	m := MatrixScale(2, 2).Concat(MatrixTranslate(10, 20))
+
+	// Packed vertex buffer: x, y, z, pressure per vertex, starting at
+	// offset 2 (two leading header floats) to exercise offset handling.
+	coords := []float32{
+		-1, -1, // header, must be left untouched
+		0, 0, 0, 0.5,
+		1, 1, 5, 0.25,
+		10, 20, 9, 0.75,
+	}
+
+	TransformPointsStrided(coords, 3, 2, 4, m)
+
+	if coords[0] != -1 || coords[1] != -1 {
+		t.Errorf("header was clobbered: got (%v, %v)", coords[0], coords[1])
+	}
+
+	want := []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 10, Y: 20}}
+	zBefore := []float32{0, 5, 9}
+	pressureBefore := []float32{0.5, 0.25, 0.75}
+	for i, p := range want {
+		idx := 2 + i*4
+		expected := p.Transform(m)
+		if coords[idx] != expected.X || coords[idx+1] != expected.Y {
+			t.Errorf("vertex %d: got (%v, %v), want (%v, %v)", i, coords[idx], coords[idx+1], expected.X, expected.Y)
+		}
+		if coords[idx+2] != zBefore[i] || coords[idx+3] != pressureBefore[i] {
+			t.Errorf("vertex %d: non-position fields were clobbered: got (%v, %v)", i, coords[idx+2], coords[idx+3])
+		}
+	}
+}
+
+func TestTransformPointsStridedAlphaBeta(t *testing.T) {
+	m := MatrixScale(2, 2)
+	src := []float32{1, 1, 2, 2}
+	dst := []float32{100, 200, 300, 400}
+
+	TransformPointsStridedAlphaBeta(dst, 0, 2, src, 0, 2, 2, m, 0.5, 0.5)
+
+	// point 0: M*(1,1) = (2,2); 0.5*2 + 0.5*100 = 51
+	if !floatEquals(dst[0], 51, 1e-6) || !floatEquals(dst[1], 51, 1e-6) {
+		t.Errorf("dst[0:2] = (%v, %v), want (51, 51)", dst[0], dst[1])
+	}
+	// point 1: M*(2,2) = (4,4); 0.5*4 + 0.5*300 = 152
+	if !floatEquals(dst[2], 152, 1e-6) || !floatEquals(dst[3], 152, 1e-6) {
+		t.Errorf("dst[2:4] = (%v, %v), want (152, 152)", dst[2], dst[3])
+	}
+}
+
+func TestTransformRectsStrided(t *testing.T) {
+	m := MatrixScale(2, 3)
+	coords := []float32{0, 0, 10, 10, 5, 5, 15, 15}
+
+	TransformRectsStrided(coords, 2, 0, 4, m)
+
+	if coords[0] != 0 || coords[1] != 0 || coords[2] != 20 || coords[3] != 30 {
+		t.Errorf("rect 0: got %v, want (0,0,20,30)", coords[0:4])
+	}
+	if coords[4] != 10 || coords[5] != 15 || coords[6] != 30 || coords[7] != 45 {
+		t.Errorf("rect 1: got %v, want (10,15,30,45)", coords[4:8])
+	}
+}
+
+func TestTransformQuadsStrided(t *testing.T) {
+	m := MatrixTranslate(10, 20)
+	r := NewRect(0, 0, 1, 1)
+	q := QuadFromRect(r)
+	coords := []float32{
+		q.UL.X, q.UL.Y, q.UR.X, q.UR.Y, q.LL.X, q.LL.Y, q.LR.X, q.LR.Y,
+	}
+
+	TransformQuadsStrided(coords, 1, 0, 8, m)
+
+	want := q.Transform(m)
+	got := Quad{
+		UL: Point{X: coords[0], Y: coords[1]},
+		UR: Point{X: coords[2], Y: coords[3]},
+		LL: Point{X: coords[4], Y: coords[5]},
+		LR: Point{X: coords[6], Y: coords[7]},
+	}
+	if got != want {
+		t.Errorf("TransformQuadsStrided() = %+v, want %+v", got, want)
+	}
+}
+
+func BenchmarkTransformPointsStrided_1000(b *testing.B) {
+	m := MatrixScale(2, 2).Concat(MatrixRotate(45))
+	const stride = 4
+	coords := make([]float32, 1000*stride)
+	for i := 0; i < 1000; i++ {
+		coords[i*stride] = float32(i)
+		coords[i*stride+1] = float32(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TransformPointsStrided(coords, 1000, 0, stride, m)
+	}
+}
+
+func TestTransformCubicsBatch(t *testing.T) {
+	m := MatrixScale(2, 2).Concat(MatrixTranslate(10, 20))
+	curves := []CubicBezier{
+		{P0: NewPoint(0, 0), P1: NewPoint(0, 10), P2: NewPoint(10, 10), P3: NewPoint(10, 0)},
+		{P0: NewPoint(5, 5), P1: NewPoint(5, 15), P2: NewPoint(15, 15), P3: NewPoint(15, 5)},
+	}
+
+	result := TransformCubicsBatch(curves, m)
+	if len(result) != len(curves) {
+		t.Fatalf("expected %d curves, got %d", len(curves), len(result))
+	}
+
+	for i, c := range curves {
+		want := c.Transform(m)
+		if result[i] != want {
+			t.Errorf("curve %d: got %+v, want %+v", i, result[i], want)
+		}
+	}
+}
+
+func TestCubicBoundsBatch(t *testing.T) {
+	curves := []CubicBezier{
+		{P0: NewPoint(0, 0), P1: NewPoint(0, 200), P2: NewPoint(100, 200), P3: NewPoint(100, 0)},
+	}
+	result := CubicBoundsBatch(curves)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 rect, got %d", len(result))
+	}
+	if want := curves[0].Bounds(); result[0] != want {
+		t.Errorf("CubicBoundsBatch()[0] = %+v, want %+v", result[0], want)
+	}
+}
+
+func TestFlattenCubicsBatch(t *testing.T) {
+	t.Run("StraightLineYieldsTwoPoints", func(t *testing.T) {
+		// A degenerate cubic whose control points sit on the P0-P3 chord
+		// is already flat, so it should flatten to just its endpoints.
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(25, 25), P2: NewPoint(75, 75), P3: NewPoint(100, 100)}
+		points := FlattenCubicsBatch([]CubicBezier{c}, 0.1)
+		if len(points) != 2 {
+			t.Fatalf("expected 2 points for a straight line, got %d: %+v", len(points), points)
+		}
+		if !points[0].Equals(c.P0) || !points[len(points)-1].Equals(c.P3) {
+			t.Errorf("flattened endpoints = %+v, %+v, want %+v, %+v", points[0], points[len(points)-1], c.P0, c.P3)
+		}
+	})
+
+	t.Run("CurvedSegmentStaysWithinTolerance", func(t *testing.T) {
+		c := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(0, 100), P2: NewPoint(100, 100), P3: NewPoint(100, 0)}
+		const tol = float32(0.5)
+		points := FlattenCubicsBatch([]CubicBezier{c}, tol)
+		if len(points) < 3 {
+			t.Fatalf("expected a bulging curve to need more than 2 points at tol=%v, got %d", tol, len(points))
+		}
+		for i := 1; i < len(points)-1; i++ {
+			d := perpDistance(points[i], points[i-1], points[i+1])
+			if d > tol*4 {
+				t.Errorf("point %d deviates from its neighbors by %v, want roughly <= %v", i, d, tol)
+			}
+		}
+	})
+
+	t.Run("ConcatenatesMultipleCurves", func(t *testing.T) {
+		c1 := CubicBezier{P0: NewPoint(0, 0), P1: NewPoint(10, 10), P2: NewPoint(20, 10), P3: NewPoint(30, 0)}
+		c2 := CubicBezier{P0: NewPoint(30, 0), P1: NewPoint(40, -10), P2: NewPoint(50, -10), P3: NewPoint(60, 0)}
+		points := FlattenCubicsBatch([]CubicBezier{c1, c2}, 0.1)
+		if !points[0].Equals(c1.P0) {
+			t.Errorf("first point = %+v, want c1.P0 %+v", points[0], c1.P0)
+		}
+		if last := points[len(points)-1]; !last.Equals(c2.P3) {
+			t.Errorf("last point = %+v, want c2.P3 %+v", last, c2.P3)
+		}
+	})
+}
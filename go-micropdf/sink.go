@@ -0,0 +1,171 @@
+// Package micropdf - Streaming page output sinks
+package micropdf
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// OutputSink receives rendered page images one at a time so a caller can
+// stream output without buffering every page in memory first.
+type OutputSink interface {
+	// WritePage writes the bytes for page index (0-based) under name.
+	WritePage(index int, name string, data []byte) error
+	// Close finalizes the sink (e.g. flushing archive trailers).
+	Close() error
+}
+
+// DirSink writes each page as its own file inside dir.
+type DirSink struct {
+	dir string
+}
+
+// NewDirSink creates a DirSink writing into dir, creating it if necessary.
+func NewDirSink(dir string) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, WrapError(ErrCodeSystem, "failed to create output directory", err)
+	}
+	return &DirSink{dir: dir}, nil
+}
+
+// WritePage implements OutputSink.
+func (s *DirSink) WritePage(_ int, name string, data []byte) error {
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return WrapError(ErrCodeSystem, "failed to write page file", err)
+	}
+	return nil
+}
+
+// Close implements OutputSink. DirSink has nothing to finalize.
+func (s *DirSink) Close() error {
+	return nil
+}
+
+// TarSink writes each page as an entry in a tar stream, gzip-compressed
+// when created by NewTarGzSink.
+type TarSink struct {
+	w  *tar.Writer
+	gz *gzip.Writer
+}
+
+// NewTarSink writes an uncompressed tar stream to w.
+func NewTarSink(w io.Writer) *TarSink {
+	return &TarSink{w: tar.NewWriter(w)}
+}
+
+// NewTarGzSink writes a gzip-compressed tar stream to w.
+func NewTarGzSink(w io.Writer) *TarSink {
+	gz := gzip.NewWriter(w)
+	return &TarSink{w: tar.NewWriter(gz), gz: gz}
+}
+
+// WritePage implements OutputSink.
+func (s *TarSink) WritePage(_ int, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(data)),
+	}
+	if err := s.w.WriteHeader(hdr); err != nil {
+		return WrapError(ErrCodeSystem, "failed to write tar header", err)
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return WrapError(ErrCodeSystem, "failed to write tar entry", err)
+	}
+	return nil
+}
+
+// Close implements OutputSink, flushing the tar (and gzip, if used) trailer.
+func (s *TarSink) Close() error {
+	if err := s.w.Close(); err != nil {
+		return WrapError(ErrCodeSystem, "failed to close tar writer", err)
+	}
+	if s.gz != nil {
+		if err := s.gz.Close(); err != nil {
+			return WrapError(ErrCodeSystem, "failed to close gzip writer", err)
+		}
+	}
+	return nil
+}
+
+// ZipSink writes each page as an entry in a zip archive.
+type ZipSink struct {
+	w *zip.Writer
+}
+
+// NewZipSink writes a zip archive to w.
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{w: zip.NewWriter(w)}
+}
+
+// WritePage implements OutputSink.
+func (s *ZipSink) WritePage(_ int, name string, data []byte) error {
+	fw, err := s.w.Create(name)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "failed to create zip entry", err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return WrapError(ErrCodeSystem, "failed to write zip entry", err)
+	}
+	return nil
+}
+
+// Close implements OutputSink, flushing the zip central directory.
+func (s *ZipSink) Close() error {
+	if err := s.w.Close(); err != nil {
+		return WrapError(ErrCodeSystem, "failed to close zip writer", err)
+	}
+	return nil
+}
+
+// NewStdoutSink returns an OutputSink writing an uncompressed tar stream
+// to os.Stdout, mirroring the `type=tar dest=-` convention used by
+// container build tooling for piping output into another process.
+func NewStdoutSink() *TarSink {
+	return NewTarSink(os.Stdout)
+}
+
+// RenderAll renders every page of the document with Page.RenderToPNGBounded and
+// streams each result into sink as it completes, never holding more than
+// one rendered page in memory at a time. Page files are named
+// "page-%04d.png" (1-based) to sort naturally in a directory listing or
+// tar/zip archive.
+func (doc *Document) RenderAll(sink OutputSink, opts RenderOptions) error {
+	if doc == nil || !doc.IsValid() {
+		return ErrInvalidHandle
+	}
+	if sink == nil {
+		return ErrInvalidArgument
+	}
+
+	pageCount, err := doc.PageCount()
+	if err != nil {
+		return err
+	}
+
+	for i := int32(0); i < pageCount; i++ {
+		page, err := doc.LoadPage(i)
+		if err != nil {
+			return WrapError(ErrCodeSystem, fmt.Sprintf("failed to load page %d", i), err)
+		}
+
+		data, _, err := page.RenderToPNGBounded(opts)
+		page.Drop()
+		if err != nil {
+			return WrapError(ErrCodeSystem, fmt.Sprintf("failed to render page %d", i), err)
+		}
+
+		name := fmt.Sprintf("page-%04d.png", i+1)
+		if err := sink.WritePage(int(i), name, data); err != nil {
+			return err
+		}
+	}
+
+	return sink.Close()
+}
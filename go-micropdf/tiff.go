@@ -0,0 +1,127 @@
+// Package micropdf - Minimal multi-page TIFF encoder for RenderPagesTo
+//
+// This is a baseline, single-strip-per-page, uncompressed TIFF writer:
+// no compression, no tiling, no support for private/metadata tags. That
+// tradeoff favors the streaming-pipeline use case RenderPagesTo targets
+// (pipe pages straight into an OCR tool) over archival file size.
+package micropdf
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+type tiffTag uint16
+
+const (
+	tagImageWidth                tiffTag = 256
+	tagImageLength               tiffTag = 257
+	tagBitsPerSample             tiffTag = 258
+	tagCompression               tiffTag = 259
+	tagPhotometricInterpretation tiffTag = 262
+	tagStripOffsets              tiffTag = 273
+	tagSamplesPerPixel           tiffTag = 277
+	tagRowsPerStrip              tiffTag = 278
+	tagStripByteCounts           tiffTag = 279
+	tagPlanarConfiguration       tiffTag = 284
+)
+
+const (
+	tiffTypeShort = 3
+	tiffTypeLong  = 4
+)
+
+// tiffPhotometric values used by writeTIFF's callers.
+const (
+	tiffPhotometricWhiteIsZero = 0
+	tiffPhotometricBlackIsZero = 1
+	tiffPhotometricRGB         = 2
+	tiffPhotometricSeparated   = 5 // CMYK
+)
+
+// tiffPage is one page's worth of 8-bit-per-sample, single-strip,
+// uncompressed, chunky (interleaved) pixel data for writeTIFF.
+type tiffPage struct {
+	width, height int
+	samples       int // samples per pixel: 1 = gray, 3 = RGB, 4 = CMYK
+	photometric   uint32
+	pix           []byte // width*height*samples bytes, row-major, interleaved
+}
+
+type tiffIFDEntry struct {
+	tag   tiffTag
+	typ   uint16
+	count uint32
+	value uint32
+}
+
+// writeTIFF encodes pages as a multi-page, little-endian, baseline TIFF
+// to w, one IFD per page chained via each IFD's "next" offset.
+func writeTIFF(w io.Writer, pages []tiffPage) error {
+	if len(pages) == 0 {
+		return ErrInvalidArgument
+	}
+
+	// Header: byte order, magic 42, and a placeholder for the first
+	// IFD's offset (patched in below once it's known).
+	buf := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+
+	var ifdOffsets []uint32
+	var nextFieldPos []int
+
+	for _, p := range pages {
+		stripOffset := uint32(len(buf))
+		buf = append(buf, p.pix...)
+
+		// A BitsPerSample array of more than 2 SHORTs doesn't fit in
+		// the IFD entry's 4-byte inline value slot, so it needs its
+		// own external storage; for <=2 samples it's stored inline,
+		// left-justified, same as any other single-SHORT value.
+		bitsPerSampleValue := uint32(8)
+		if p.samples == 2 {
+			bitsPerSampleValue = 8 | 8<<16
+		}
+		if p.samples > 2 {
+			bitsPerSampleValue = uint32(len(buf))
+			for s := 0; s < p.samples; s++ {
+				buf = binary.LittleEndian.AppendUint16(buf, 8)
+			}
+		}
+
+		// Tags must appear in ascending numeric order for strict readers.
+		entries := []tiffIFDEntry{
+			{tagImageWidth, tiffTypeLong, 1, uint32(p.width)},
+			{tagImageLength, tiffTypeLong, 1, uint32(p.height)},
+			{tagBitsPerSample, tiffTypeShort, uint32(p.samples), bitsPerSampleValue},
+			{tagCompression, tiffTypeShort, 1, 1},
+			{tagPhotometricInterpretation, tiffTypeShort, 1, p.photometric},
+			{tagStripOffsets, tiffTypeLong, 1, stripOffset},
+			{tagSamplesPerPixel, tiffTypeShort, 1, uint32(p.samples)},
+			{tagRowsPerStrip, tiffTypeLong, 1, uint32(p.height)},
+			{tagStripByteCounts, tiffTypeLong, 1, uint32(len(p.pix))},
+			{tagPlanarConfiguration, tiffTypeShort, 1, 1},
+		}
+
+		ifdOffsets = append(ifdOffsets, uint32(len(buf)))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(e.tag))
+			buf = binary.LittleEndian.AppendUint16(buf, e.typ)
+			buf = binary.LittleEndian.AppendUint32(buf, e.count)
+			buf = binary.LittleEndian.AppendUint32(buf, e.value)
+		}
+		nextFieldPos = append(nextFieldPos, len(buf))
+		buf = append(buf, 0, 0, 0, 0) // patched below once the following IFD's offset is known
+	}
+
+	binary.LittleEndian.PutUint32(buf[4:8], ifdOffsets[0])
+	for i := 0; i < len(ifdOffsets)-1; i++ {
+		binary.LittleEndian.PutUint32(buf[nextFieldPos[i]:], ifdOffsets[i+1])
+	}
+
+	_, err := w.Write(buf)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "failed to write tiff", err)
+	}
+	return nil
+}
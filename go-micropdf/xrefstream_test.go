@@ -0,0 +1,219 @@
+package micropdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempPDF(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.pdf")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write temp PDF: %v", err)
+	}
+	return path
+}
+
+func TestParseXRefChainClassicalTable(t *testing.T) {
+	body := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	xrefSection := "xref\n0 2\n0000000000 65535 f \n0000000009 00000 n \ntrailer\n<< /Size 2 /Root 1 0 R >>\n"
+
+	xrefOffset := len(body)
+	content := body + xrefSection + fmt.Sprintf("startxref\n%d\n%%%%EOF", xrefOffset)
+
+	path := writeTempPDF(t, content)
+	entries, err := parseXRefChain(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e, ok := entries[0]; !ok || e.Type != XRefFree {
+		t.Errorf("Expected object 0 to be free, got %+v (ok=%v)", e, ok)
+	}
+	e1, ok := entries[1]
+	if !ok || e1.Type != XRefInUse || e1.Field2 != 9 {
+		t.Errorf("Expected object 1 in-use at offset 9, got %+v (ok=%v)", e1, ok)
+	}
+}
+
+func TestParseXRefChainPrevChain(t *testing.T) {
+	base := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	baseXref := "xref\n0 2\n0000000000 65535 f \n0000000009 00000 n \ntrailer\n<< /Size 2 /Root 1 0 R >>\n"
+	baseXrefOffset := len(base)
+	base += baseXref + fmt.Sprintf("startxref\n%d\n%%%%EOF\n", baseXrefOffset)
+
+	update := "2 0 obj\n<< /Type /Catalog /Extra true >>\nendobj\n"
+	updateStart := len(base)
+	updateXrefOffset := updateStart + len(update)
+	updateXref := fmt.Sprintf("xref\n2 1\n%010d 00000 n \ntrailer\n<< /Size 3 /Root 1 0 R /Prev %d >>\n", updateStart, baseXrefOffset)
+
+	content := base + update + updateXref + fmt.Sprintf("startxref\n%d\n%%%%EOF", updateXrefOffset)
+
+	path := writeTempPDF(t, content)
+	entries, err := parseXRefChain(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := entries[0]; !ok {
+		t.Error("Expected object 0 to be carried over from the Prev section")
+	}
+	if _, ok := entries[1]; !ok {
+		t.Error("Expected object 1 to be carried over from the Prev section")
+	}
+	e2, ok := entries[2]
+	if !ok || e2.Field2 != int64(updateStart) {
+		t.Errorf("Expected object 2 in the incremental update at offset %d, got %+v (ok=%v)", updateStart, e2, ok)
+	}
+}
+
+func TestParseXRefChainXRefStream(t *testing.T) {
+	// Build three records (objects 0, 1, 2) with /W [1 2 1]:
+	//   type(1) offset/objstm(2) gen/index(1)
+	records := []byte{
+		0, 0, 0, 65, // obj 0: free, generation 65 (unused convention for test)
+		1, 0, 9, 0, // obj 1: in-use at offset 9
+		2, 0, 4, 0, // obj 2: compressed in obj stream 4, index 0
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(records); err != nil {
+		t.Fatalf("failed to compress test xref stream: %v", err)
+	}
+	zw.Close()
+
+	body := "%PDF-1.5\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	xrefObjOffset := len(body)
+	xrefObj := fmt.Sprintf(
+		"3 0 obj\n<< /Type /XRef /Size 3 /W [1 2 1] /Filter /FlateDecode /Length %d >>\nstream\n",
+		compressed.Len(),
+	) + compressed.String() + "\nendstream\nendobj\n"
+
+	content := body + xrefObj + fmt.Sprintf("startxref\n%d\n%%%%EOF", xrefObjOffset)
+
+	path := writeTempPDF(t, content)
+	entries, err := parseXRefChain(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e, ok := entries[0]; !ok || e.Type != XRefFree {
+		t.Errorf("Expected object 0 to be free, got %+v (ok=%v)", e, ok)
+	}
+	if e, ok := entries[1]; !ok || e.Type != XRefInUse || e.Field2 != 9 {
+		t.Errorf("Expected object 1 in-use at offset 9, got %+v (ok=%v)", e, ok)
+	}
+	if e, ok := entries[2]; !ok || e.Type != XRefCompressed || e.Field2 != 4 {
+		t.Errorf("Expected object 2 compressed in stream 4, got %+v (ok=%v)", e, ok)
+	}
+}
+
+func TestParseXRefChainNegativeWidthRejected(t *testing.T) {
+	// A crafted /W [-1 4 2] would make recordLen negative and panic on
+	// the decoded[pos:pos+recordLen] slice expression if not validated;
+	// this must come back as a clean ErrFormat instead.
+	records := []byte{0, 0, 0, 0, 0, 0, 0} // contents don't matter; parsing /W fails first
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(records); err != nil {
+		t.Fatalf("failed to compress test xref stream: %v", err)
+	}
+	zw.Close()
+
+	body := "%PDF-1.5\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	xrefObjOffset := len(body)
+	xrefObj := fmt.Sprintf(
+		"3 0 obj\n<< /Type /XRef /Size 3 /W [-1 4 2] /Filter /FlateDecode /Length %d >>\nstream\n",
+		compressed.Len(),
+	) + compressed.String() + "\nendstream\nendobj\n"
+
+	content := body + xrefObj + fmt.Sprintf("startxref\n%d\n%%%%EOF", xrefObjOffset)
+
+	path := writeTempPDF(t, content)
+	if _, err := parseXRefChain(path); err == nil {
+		t.Error("Expected an error for a negative /W entry, not a crash")
+	}
+}
+
+func TestUndoPNGPredictorUpFilter(t *testing.T) {
+	// Two rows of 2 columns, both filter type 2 (Up): the first row's
+	// "previous row" is all zero, so it decodes unchanged; the second
+	// row's raw bytes are deltas added to the first row's output.
+	data := []byte{
+		2, 10, 20,
+		2, 1, 1,
+	}
+	out, err := undoPNGPredictor(data, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []byte{10, 20, 11, 21}
+	if !bytes.Equal(out, want) {
+		t.Errorf("Expected %v, got %v", want, out)
+	}
+}
+
+func TestParseIntSlice(t *testing.T) {
+	got, err := parseIntSlice("[1 2 1]")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []int{1, 2, 1}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestParseXRefChainErrors(t *testing.T) {
+	t.Run("NoStartXref", func(t *testing.T) {
+		path := writeTempPDF(t, "%PDF-1.4\nnothing to see here")
+		if _, err := parseXRefChain(path); err == nil {
+			t.Error("Expected an error for a file with no startxref")
+		}
+	})
+
+	t.Run("DanglingStartXref", func(t *testing.T) {
+		path := writeTempPDF(t, "%PDF-1.4\nstartxref\n99999\n%%EOF")
+		if _, err := parseXRefChain(path); err == nil {
+			t.Error("Expected an error for an out-of-bounds startxref offset")
+		}
+	})
+}
+
+func TestOpenDocumentStrictValidation(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Drop()
+
+	t.Run("NilContext", func(t *testing.T) {
+		_, err := OpenDocumentStrict(nil, "doc.pdf", OpenOptions{})
+		if err != ErrInvalidContext {
+			t.Errorf("Expected ErrInvalidContext, got %v", err)
+		}
+	})
+
+	t.Run("EmptyPath", func(t *testing.T) {
+		_, err := OpenDocumentStrict(ctx, "", OpenOptions{})
+		if err != ErrInvalidArgument {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("MalformedXRef", func(t *testing.T) {
+		path := writeTempPDF(t, "%PDF-1.4\n"+strings.Repeat("garbage\n", 3))
+		if _, err := OpenDocumentStrict(ctx, path, OpenOptions{}); err == nil {
+			t.Error("Expected an error for a file with no reconstructable xref")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package nanopdf
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// numProfileBuckets mirrors the table size Go's own memprofile
+// implementation uses for call-stack buckets.
+const numProfileBuckets = 179999
+
+// maxBucketStackDepth bounds how many PCs are captured per allocation.
+const maxBucketStackDepth = 32
+
+// bucketEntry aggregates every allocation/free that shares a call stack.
+// Only raw PCs are stored; symbolizing them into file/line/function is
+// deferred until a report is actually requested.
+type bucketEntry struct {
+	mu           sync.Mutex
+	pcs          []uintptr
+	resourceType ResourceType
+	nalloc       int64
+	nfree        int64
+	bytesAlloc   int64
+	bytesFree    int64
+}
+
+// BucketProfileRecord is the symbolization-free view of one call-stack
+// bucket, mirroring the shape of Go's own runtime.MemProfileRecord.
+type BucketProfileRecord struct {
+	Stack        []uintptr
+	ResourceType ResourceType
+	AllocObjects int64
+	FreeObjects  int64
+	AllocBytes   int64
+	FreeBytes    int64
+}
+
+// hashPCs combines a call stack into a single bucket-table key using an
+// xor/rotate hash, so two allocations from the same call site always land
+// in the same bucket regardless of capture order.
+func hashPCs(pcs []uintptr) uint64 {
+	var h uint64 = 0xcbf29ce484222325
+	for _, pc := range pcs {
+		h ^= uint64(pc)
+		h = bits.RotateLeft64(h, 5)
+	}
+	return h
+}
+
+// recordAllocationBucketed captures only the raw call stack (via
+// runtime.Callers, never runtime.Stack/FuncForPC) and aggregates it into
+// the profiler's bucket table. countDelta/bytesDelta are the (possibly
+// sampling-weighted) contributions this allocation makes, not necessarily
+// 1/sizeBytes. It returns the bucket so the caller can remember it for
+// the matching free, letting RecordDeallocation update the right bucket
+// without re-walking the stack.
+func (p *MemoryProfiler) recordAllocationBucketed(resourceType ResourceType, bytesDelta, countDelta int64, skip int) *bucketEntry {
+	var pcs [maxBucketStackDepth]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+
+	key := hashPCs(pcs[:n]) % numProfileBuckets
+
+	p.bucketsOnce.Do(func() {
+		p.buckets = &sync.Map{}
+	})
+
+	value, _ := p.buckets.LoadOrStore(key, &bucketEntry{
+		pcs:          append([]uintptr(nil), pcs[:n]...),
+		resourceType: resourceType,
+	})
+	entry := value.(*bucketEntry)
+
+	entry.mu.Lock()
+	entry.nalloc += countDelta
+	entry.bytesAlloc += bytesDelta
+	entry.mu.Unlock()
+
+	return entry
+}
+
+// recordDeallocationBucketed updates the bucket a matching allocation was
+// recorded into, given the bucket pointer captured at allocation time and
+// the same (weighted) deltas that were added for it.
+func recordDeallocationBucketed(entry *bucketEntry, bytesDelta, countDelta int64) {
+	if entry == nil {
+		return
+	}
+	entry.mu.Lock()
+	entry.nfree += countDelta
+	entry.bytesFree += bytesDelta
+	entry.mu.Unlock()
+}
+
+// MemProfile writes up to len(p) live bucket records into p and returns
+// the number written and whether p was large enough, mirroring
+// runtime.MemProfile's calling convention: pass a nil/zero-length slice
+// first to get the required count back in n.
+//
+// When inuseZero is false, buckets whose allocations have all been freed
+// (nalloc == nfree) are skipped.
+func (p *MemoryProfiler) MemProfile(records []BucketProfileRecord, inuseZero bool) (n int, ok bool) {
+	if p.buckets == nil {
+		return 0, true
+	}
+
+	var all []BucketProfileRecord
+	p.buckets.Range(func(_, value interface{}) bool {
+		entry := value.(*bucketEntry)
+		entry.mu.Lock()
+		rec := BucketProfileRecord{
+			Stack:        append([]uintptr(nil), entry.pcs...),
+			ResourceType: entry.resourceType,
+			AllocObjects: entry.nalloc,
+			FreeObjects:  entry.nfree,
+			AllocBytes:   entry.bytesAlloc,
+			FreeBytes:    entry.bytesFree,
+		}
+		entry.mu.Unlock()
+
+		if !inuseZero && rec.AllocObjects == rec.FreeObjects {
+			return true
+		}
+		all = append(all, rec)
+		return true
+	})
+
+	if len(records) < len(all) {
+		return len(all), false
+	}
+	copy(records, all)
+	return len(all), true
+}
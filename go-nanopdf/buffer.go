@@ -0,0 +1,489 @@
+package nanopdf
+
+import "io"
+
+// Codec identifies the compression format a Buffer's native storage is
+// currently holding.
+type Codec int
+
+const (
+	// CodecNone means the buffer holds uncompressed bytes.
+	CodecNone Codec = iota
+	// CodecSnappy means the buffer holds snappy-compressed bytes.
+	CodecSnappy
+	// CodecZstd means the buffer holds zstd-compressed bytes.
+	CodecZstd
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecNone:
+		return "none"
+	case CodecSnappy:
+		return "snappy"
+	case CodecZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+// codecSnappyID and codecZstdID are the wire values passed down to the
+// native bufferCompress/bufferDecompress functions; kept distinct from
+// Codec itself so the native layer doesn't need to import this file's enum.
+const (
+	codecSnappyID = 1
+	codecZstdID   = 2
+)
+
+// Buffer wraps a native growable byte buffer — the type PDF content
+// streams, extracted text, and rendered page images are all handed back
+// through. Call Free when done; TrackedResource's finalizer will warn if
+// that's skipped while leak detection is enabled.
+//
+// Buffer implements io.Reader, io.Writer, io.ByteReader, io.ByteWriter,
+// io.WriterTo, and io.ReaderFrom with bytes.Buffer's read/write semantics:
+// Write (and Append) always add to the end, Read (and friends) consume
+// from a cursor that starts at the beginning, and the two can be
+// interleaved freely.
+type Buffer struct {
+	TrackedResource
+	handle uintptr
+	codec  Codec
+	off    int // read cursor into the unread portion; bytes before off have already been Read/Next'd away
+}
+
+// NewBuffer creates an empty Buffer with capacity bytes pre-reserved.
+func NewBuffer(capacity int) *Buffer {
+	handle := bufferNew(capacity)
+	b := &Buffer{handle: handle}
+	b.InitTracking(handle, ResourceBuffer)
+	return b
+}
+
+// NewBufferFromBytes creates a Buffer holding a copy of data.
+func NewBufferFromBytes(data []byte) *Buffer {
+	handle := bufferFromData(data)
+	b := &Buffer{handle: handle}
+	b.InitTracking(handle, ResourceBuffer)
+	return b
+}
+
+// NewBufferFromString creates a Buffer holding a copy of s.
+func NewBufferFromString(s string) *Buffer {
+	return NewBufferFromBytes([]byte(s))
+}
+
+// Free releases b's native buffer. Safe to call on a nil Buffer or one
+// that's already been freed.
+func (b *Buffer) Free() {
+	if b == nil || b.handle == 0 {
+		return
+	}
+	bufferFree(b.handle)
+	b.MarkDropped()
+	b.handle = 0
+}
+
+// Len returns the number of unread bytes in b — its total contents minus
+// whatever Read/Next/ReadByte/WriteTo have already consumed. If b holds
+// compressed data, the total is the decompressed length, matching
+// Bytes/String. A nil Buffer has length 0.
+func (b *Buffer) Len() int {
+	if b == nil || b.handle == 0 {
+		return 0
+	}
+	n := b.fullLen() - b.off
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// IsEmpty reports whether b has no unread data.
+func (b *Buffer) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+// empty reports whether b has no unread data; used internally by the
+// io.Reader-family methods so they read consistently with Len/IsEmpty.
+func (b *Buffer) empty() bool {
+	return b.Len() <= 0
+}
+
+// fullLen returns b's total content length, ignoring the read cursor.
+func (b *Buffer) fullLen() int {
+	if b.codec == CodecNone {
+		return bufferLen(b.handle)
+	}
+	return len(b.fullBytes())
+}
+
+// fullBytes returns a copy of all of b's contents, ignoring the read
+// cursor, transparently decompressing first if b currently holds a
+// compressed payload. Returns nil if b's stored codec fails to decode.
+func (b *Buffer) fullBytes() []byte {
+	if b.codec == CodecNone {
+		return bufferData(b.handle)
+	}
+
+	// Decompress a scratch copy rather than the live native buffer, so a
+	// read-only Bytes() call can't leave b's storage decoded underneath a
+	// concurrent caller still expecting it compressed.
+	scratch := NewBufferFromBytes(bufferData(b.handle))
+	defer scratch.Free()
+	if err := scratch.decompress(b.codec); err != nil {
+		return nil
+	}
+	return bufferData(scratch.handle)
+}
+
+// Bytes returns b's unread portion — the same bytes.Buffer.Bytes
+// contract, minus whatever Read/Next/ReadByte/WriteTo have already
+// consumed — transparently decompressing first if b currently holds a
+// compressed payload. A nil or freed Buffer, or one whose stored codec
+// fails to decode, returns nil. The native backing store still requires
+// one copy out of the handle; Bytes avoids a second copy on top of that
+// by slicing the fetched result in place rather than reallocating.
+func (b *Buffer) Bytes() []byte {
+	if b == nil || b.handle == 0 {
+		return nil
+	}
+	full := b.fullBytes()
+	if b.off >= len(full) {
+		return full[len(full):]
+	}
+	return full[b.off:]
+}
+
+// String returns b's unread contents as a string, transparently
+// decompressing first if needed.
+func (b *Buffer) String() string {
+	return string(b.Bytes())
+}
+
+// AppendString appends s to b.
+func (b *Buffer) AppendString(s string) error {
+	return b.Append([]byte(s))
+}
+
+// AppendByte appends a single byte to b.
+func (b *Buffer) AppendByte(c byte) error {
+	return b.Append([]byte{c})
+}
+
+// Append appends data to b. Appending to a compressed Buffer is not
+// supported — decompress it first.
+func (b *Buffer) Append(data []byte) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec != CodecNone {
+		return ErrUnsupported("buffer: Append on a compressed buffer; DecompressSnappy/DecompressZstd first")
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if code := bufferAppend(b.handle, data); code != 0 {
+		return ErrSystem("buffer: native append failed", nil)
+	}
+	return nil
+}
+
+// Clear empties b without releasing its native allocation.
+func (b *Buffer) Clear() {
+	if b == nil || b.handle == 0 {
+		return
+	}
+	bufferClear(b.handle)
+	b.codec = CodecNone
+	b.off = 0
+}
+
+// Reset is Clear's bytes.Buffer-compatible name.
+func (b *Buffer) Reset() {
+	b.Clear()
+}
+
+// Grow grows b's native capacity, if necessary, to guarantee space for
+// another n bytes without reallocating. It panics if n is negative,
+// matching bytes.Buffer.Grow.
+func (b *Buffer) Grow(n int) {
+	if n < 0 {
+		panic("nanopdf.Buffer.Grow: negative count")
+	}
+	if b == nil || b.handle == 0 {
+		return
+	}
+	bufferGrow(b.handle, n)
+}
+
+// Next returns a slice of the next n unread bytes, advancing the read
+// cursor by the same amount. If b has fewer than n unread bytes, Next
+// returns all of them, matching bytes.Buffer.Next.
+func (b *Buffer) Next(n int) []byte {
+	if b == nil || b.handle == 0 || n <= 0 {
+		return nil
+	}
+	data := b.Bytes()
+	if n > len(data) {
+		n = len(data)
+	}
+	out := data[:n]
+	b.off += n
+	if b.empty() {
+		b.Reset()
+	}
+	return out
+}
+
+// Truncate discards all but the first n unread bytes. It panics if n is
+// negative or greater than b.Len(), matching bytes.Buffer.Truncate.
+func (b *Buffer) Truncate(n int) {
+	if b == nil || b.handle == 0 {
+		return
+	}
+	if n == 0 {
+		b.Reset()
+		return
+	}
+	if n < 0 || n > b.Len() {
+		panic("nanopdf.Buffer.Truncate: out of range")
+	}
+	kept := append([]byte(nil), b.Bytes()[:n]...)
+	bufferClear(b.handle)
+	b.codec = CodecNone
+	b.off = 0
+	bufferAppend(b.handle, kept)
+}
+
+// Read implements io.Reader, consuming from the front of b's unread
+// portion. It returns io.EOF once b is fully drained.
+func (b *Buffer) Read(p []byte) (n int, err error) {
+	if b == nil || b.handle == 0 {
+		return 0, ErrInvalidHandle
+	}
+	if b.empty() {
+		b.Reset()
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	if b.codec != CodecNone {
+		n = copy(p, b.Bytes())
+	} else {
+		n = bufferRead(b.handle, b.off, p)
+	}
+	b.off += n
+	if b.empty() {
+		b.Reset()
+	}
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader.
+func (b *Buffer) ReadByte() (byte, error) {
+	if b == nil || b.handle == 0 {
+		return 0, ErrInvalidHandle
+	}
+	if b.empty() {
+		b.Reset()
+		return 0, io.EOF
+	}
+	c := b.Bytes()[0]
+	b.off++
+	if b.empty() {
+		b.Reset()
+	}
+	return c, nil
+}
+
+// WriteTo implements io.WriterTo, writing b's unread portion to w and
+// consuming whatever was successfully written.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	if b == nil || b.handle == 0 {
+		return 0, ErrInvalidHandle
+	}
+	data := b.Bytes()
+	n, err := w.Write(data)
+	if n < 0 || n > len(data) {
+		panic("nanopdf.Buffer.WriteTo: invalid Write count")
+	}
+	b.off += n
+	if err == nil && n != len(data) {
+		err = io.ErrShortWrite
+	}
+	if b.empty() {
+		b.Reset()
+	}
+	return int64(n), err
+}
+
+// ReadFrom implements io.ReaderFrom, appending everything read from r
+// until it returns io.EOF.
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	if b == nil || b.handle == 0 {
+		return 0, ErrInvalidHandle
+	}
+	var total int64
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			if werr := b.Append(chunk[:n]); werr != nil {
+				return total, werr
+			}
+			total += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Write implements io.Writer, appending p to b.
+func (b *Buffer) Write(p []byte) (int, error) {
+	if err := b.Append(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteByte implements io.ByteWriter.
+func (b *Buffer) WriteByte(c byte) error {
+	return b.AppendByte(c)
+}
+
+// Clone returns an independent Buffer holding a copy of b's current
+// (decompressed) contents.
+func (b *Buffer) Clone() *Buffer {
+	if b == nil || b.handle == 0 {
+		return NewBuffer(0)
+	}
+	return NewBufferFromBytes(b.Bytes())
+}
+
+// Codec returns the compression format b's native storage currently holds.
+func (b *Buffer) Codec() Codec {
+	if b == nil {
+		return CodecNone
+	}
+	return b.codec
+}
+
+// CompressSnappy compresses b's contents in place with snappy, operating
+// directly on the native buffer to avoid a Go<->C copy round trip.
+// A no-op if b is already snappy-compressed; returns an error if b holds
+// another codec, so call the matching Decompress first to switch codecs.
+func (b *Buffer) CompressSnappy() error {
+	return b.compress(CodecSnappy, 0)
+}
+
+// DecompressSnappy reverses CompressSnappy, restoring b's native buffer to
+// plain bytes in place.
+func (b *Buffer) DecompressSnappy() error {
+	return b.decompress(CodecSnappy)
+}
+
+// CompressZstd compresses b's contents in place with zstd at the given
+// level, operating directly on the native buffer to avoid a Go<->C copy
+// round trip.
+func (b *Buffer) CompressZstd(level int) error {
+	return b.compress(CodecZstd, level)
+}
+
+// DecompressZstd reverses CompressZstd, restoring b's native buffer to
+// plain bytes in place.
+func (b *Buffer) DecompressZstd() error {
+	return b.decompress(CodecZstd)
+}
+
+func (b *Buffer) compress(codec Codec, level int) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec == codec {
+		return nil
+	}
+	if b.codec != CodecNone {
+		return ErrUnsupported("buffer: already compressed with a different codec")
+	}
+
+	if code := bufferCompress(b.handle, codecWireID(codec), level); code != 0 {
+		return ErrSystem("buffer: native compress failed", nil)
+	}
+	b.codec = codec
+	return nil
+}
+
+func (b *Buffer) decompress(codec Codec) error {
+	if b == nil || b.handle == 0 {
+		return ErrInvalidHandle
+	}
+	if b.codec == CodecNone {
+		return nil
+	}
+	if b.codec != codec {
+		return ErrArgument("buffer: codec mismatch in decompress")
+	}
+
+	if code := bufferDecompress(b.handle, codecWireID(codec)); code != 0 {
+		return ErrSystem("buffer: native decompress failed", nil)
+	}
+	b.codec = CodecNone
+	return nil
+}
+
+func codecWireID(codec Codec) int {
+	switch codec {
+	case CodecSnappy:
+		return codecSnappyID
+	case CodecZstd:
+		return codecZstdID
+	default:
+		return 0
+	}
+}
+
+// RoundTripCompress compresses b with codec, then immediately decompresses
+// it again and reports whether the result matches the original bytes. It
+// exists to exercise the native compress/decompress bridge end to end —
+// used by the benchmark and test suites to catch regressions in that
+// bridge rather than to be part of normal Buffer usage.
+func RoundTripCompress(b *Buffer, codec Codec) (ok bool, err error) {
+	original := b.Bytes()
+
+	switch codec {
+	case CodecSnappy:
+		if err := b.CompressSnappy(); err != nil {
+			return false, err
+		}
+		if err := b.DecompressSnappy(); err != nil {
+			return false, err
+		}
+	case CodecZstd:
+		if err := b.CompressZstd(0); err != nil {
+			return false, err
+		}
+		if err := b.DecompressZstd(); err != nil {
+			return false, err
+		}
+	default:
+		return false, ErrUnsupported("buffer: unknown codec in RoundTripCompress")
+	}
+
+	roundTripped := b.Bytes()
+	if len(roundTripped) != len(original) {
+		return false, nil
+	}
+	for i := range original {
+		if roundTripped[i] != original[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -2,6 +2,8 @@ package nanopdf
 
 import (
 	"bytes"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -132,3 +134,236 @@ func TestBuffer(t *testing.T) {
 	})
 }
 
+func TestBufferReadWrite(t *testing.T) {
+	t.Run("WriteThenRead", func(t *testing.T) {
+		buf := NewBuffer(0)
+		defer buf.Free()
+
+		n, err := buf.Write([]byte("Hello, "))
+		if err != nil || n != 7 {
+			t.Fatalf("Write: n=%d err=%v", n, err)
+		}
+		if _, err := buf.Write([]byte("World!")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+
+		p := make([]byte, 5)
+		n, err = buf.Read(p)
+		if err != nil || string(p[:n]) != "Hello" {
+			t.Fatalf("Read: n=%d err=%v p=%q", n, err, p[:n])
+		}
+
+		rest, err := io.ReadAll(buf)
+		if err != nil || string(rest) != ", World!" {
+			t.Fatalf("ReadAll: %q, %v", rest, err)
+		}
+
+		if _, err := buf.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("expected io.EOF on a drained buffer, got %v", err)
+		}
+	})
+
+	t.Run("Interleaved", func(t *testing.T) {
+		buf := NewBuffer(0)
+		defer buf.Free()
+
+		buf.Write([]byte("abc"))
+		p := make([]byte, 1)
+		buf.Read(p) // consume "a"
+		buf.Write([]byte("def"))
+
+		if got := buf.String(); got != "bcdef" {
+			t.Errorf("expected %q, got %q", "bcdef", got)
+		}
+	})
+
+	t.Run("ByteReaderWriter", func(t *testing.T) {
+		buf := NewBuffer(0)
+		defer buf.Free()
+
+		for _, c := range []byte("ABC") {
+			if err := buf.WriteByte(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, want := range []byte("ABC") {
+			got, err := buf.ReadByte()
+			if err != nil || got != want {
+				t.Fatalf("ReadByte: got %v, %v; want %v", got, err, want)
+			}
+		}
+		if _, err := buf.ReadByte(); err != io.EOF {
+			t.Errorf("expected io.EOF, got %v", err)
+		}
+	})
+
+	t.Run("WriteTo", func(t *testing.T) {
+		buf := NewBufferFromString("streamed output")
+		defer buf.Free()
+
+		var dst bytes.Buffer
+		n, err := buf.WriteTo(&dst)
+		if err != nil || n != 15 {
+			t.Fatalf("WriteTo: n=%d err=%v", n, err)
+		}
+		if dst.String() != "streamed output" {
+			t.Errorf("expected %q, got %q", "streamed output", dst.String())
+		}
+		if !buf.IsEmpty() {
+			t.Error("expected buffer to be drained after WriteTo")
+		}
+	})
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		buf := NewBuffer(0)
+		defer buf.Free()
+
+		n, err := buf.ReadFrom(strings.NewReader("piped in"))
+		if err != nil || n != 8 {
+			t.Fatalf("ReadFrom: n=%d err=%v", n, err)
+		}
+		if buf.String() != "piped in" {
+			t.Errorf("expected %q, got %q", "piped in", buf.String())
+		}
+	})
+
+	t.Run("Next", func(t *testing.T) {
+		buf := NewBufferFromString("0123456789")
+		defer buf.Free()
+
+		if got := string(buf.Next(4)); got != "0123" {
+			t.Errorf("expected %q, got %q", "0123", got)
+		}
+		if got := string(buf.Next(100)); got != "456789" {
+			t.Errorf("expected %q, got %q", "456789", got)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		buf := NewBufferFromString("0123456789")
+		defer buf.Free()
+
+		buf.Truncate(4)
+		if buf.String() != "0123" {
+			t.Errorf("expected %q, got %q", "0123", buf.String())
+		}
+	})
+
+	t.Run("TruncatePanicsOutOfRange", func(t *testing.T) {
+		buf := NewBufferFromString("abc")
+		defer buf.Free()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Truncate(4) on a 3-byte buffer to panic")
+			}
+		}()
+		buf.Truncate(4)
+	})
+
+	t.Run("Grow", func(t *testing.T) {
+		buf := NewBuffer(0)
+		defer buf.Free()
+
+		buf.Grow(64)
+		if err := buf.AppendString("fits without reallocating"); err != nil {
+			t.Fatalf("Append after Grow: %v", err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		buf := NewBufferFromString("data")
+		defer buf.Free()
+
+		buf.Reset()
+		if buf.Len() != 0 {
+			t.Errorf("expected length 0 after Reset, got %d", buf.Len())
+		}
+		if err := buf.AppendString("more"); err != nil {
+			t.Fatalf("Append after Reset: %v", err)
+		}
+		if buf.String() != "more" {
+			t.Errorf("expected %q, got %q", "more", buf.String())
+		}
+	})
+}
+
+func TestBufferCompression(t *testing.T) {
+	data := []byte("some reasonably compressible payload, some reasonably compressible payload")
+
+	t.Run("SnappyRoundTrip", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if buf.Codec() != CodecSnappy {
+			t.Errorf("expected codec %v, got %v", CodecSnappy, buf.Codec())
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Error("Bytes() did not transparently decompress to the original payload")
+		}
+
+		if err := buf.DecompressSnappy(); err != nil {
+			t.Fatalf("DecompressSnappy: %v", err)
+		}
+		if buf.Codec() != CodecNone {
+			t.Errorf("expected codec %v after decompress, got %v", CodecNone, buf.Codec())
+		}
+	})
+
+	t.Run("ZstdRoundTrip", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressZstd(5); err != nil {
+			t.Fatalf("CompressZstd: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Error("Bytes() did not transparently decompress to the original payload")
+		}
+		if err := buf.DecompressZstd(); err != nil {
+			t.Fatalf("DecompressZstd: %v", err)
+		}
+	})
+
+	t.Run("RoundTripCompressHelper", func(t *testing.T) {
+		for _, codec := range []Codec{CodecSnappy, CodecZstd} {
+			buf := NewBufferFromBytes(data)
+			ok, err := RoundTripCompress(buf, codec)
+			buf.Free()
+			if err != nil {
+				t.Fatalf("codec %v: RoundTripCompress error: %v", codec, err)
+			}
+			if !ok {
+				t.Errorf("codec %v: round trip did not reproduce the original bytes", codec)
+			}
+		}
+	})
+
+	t.Run("AppendToCompressedBufferFails", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if err := buf.AppendString("more"); err == nil {
+			t.Error("expected Append on a compressed buffer to fail")
+		}
+	})
+
+	t.Run("MismatchedDecompressFails", func(t *testing.T) {
+		buf := NewBufferFromBytes(data)
+		defer buf.Free()
+
+		if err := buf.CompressSnappy(); err != nil {
+			t.Fatalf("CompressSnappy: %v", err)
+		}
+		if err := buf.DecompressZstd(); err == nil {
+			t.Error("expected DecompressZstd to fail on a snappy-compressed buffer")
+		}
+	})
+}
+
@@ -75,3 +75,23 @@ func (cs *Colorspace) IsCMYK() bool {
 	return cs.Components() == 4
 }
 
+// NewICCColorspace creates a colorspace from an embedded ICC profile, for
+// callers that need print-accurate color (e.g. CMYK output tied to a
+// specific press profile) rather than one of the generic Device*
+// colorspaces.
+func NewICCColorspace(ctx *Context, iccProfile []byte) (*Colorspace, error) {
+	if ctx == nil || !ctx.IsValid() {
+		return nil, ErrInvalidContext
+	}
+	if len(iccProfile) == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	handle := iccColorspaceNew(ctx.Handle(), iccProfile)
+	if handle == 0 {
+		return nil, ErrFailedToLoad
+	}
+
+	return &Colorspace{handle: handle, ctx: ctx}, nil
+}
+
@@ -0,0 +1,110 @@
+// Package nanopdf provides error types matching the Rust nanopdf library.
+package nanopdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode represents the type of error that occurred.
+type ErrorCode int
+
+const (
+	// ErrCodeGeneric is a generic error.
+	ErrCodeGeneric ErrorCode = iota
+	// ErrCodeSystem is a system error (I/O, memory, etc.).
+	ErrCodeSystem
+	// ErrCodeFormat is a format/parsing error.
+	ErrCodeFormat
+	// ErrCodeArgument is an invalid argument error.
+	ErrCodeArgument
+	// ErrCodeUnsupported indicates an unsupported feature.
+	ErrCodeUnsupported
+)
+
+func (c ErrorCode) String() string {
+	switch c {
+	case ErrCodeGeneric:
+		return "GENERIC"
+	case ErrCodeSystem:
+		return "SYSTEM"
+	case ErrCodeFormat:
+		return "FORMAT"
+	case ErrCodeArgument:
+		return "ARGUMENT"
+	case ErrCodeUnsupported:
+		return "UNSUPPORTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// NanoPDFError represents an error from the nanopdf library.
+type NanoPDFError struct {
+	Code    ErrorCode
+	Message string
+	Cause   error
+}
+
+// Error implements the error interface.
+func (e *NanoPDFError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("[%s] %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *NanoPDFError) Unwrap() error {
+	return e.Cause
+}
+
+// Is checks if target error matches this error's code.
+func (e *NanoPDFError) Is(target error) bool {
+	var t *NanoPDFError
+	if errors.As(target, &t) {
+		return e.Code == t.Code
+	}
+	return false
+}
+
+// NewError creates a new NanoPDFError.
+func NewError(code ErrorCode, message string) *NanoPDFError {
+	return &NanoPDFError{Code: code, Message: message}
+}
+
+// WrapError wraps an existing error with a NanoPDFError.
+func WrapError(code ErrorCode, message string, cause error) *NanoPDFError {
+	return &NanoPDFError{Code: code, Message: message, Cause: cause}
+}
+
+// ErrGeneric creates a generic error.
+func ErrGeneric(message string) *NanoPDFError {
+	return NewError(ErrCodeGeneric, message)
+}
+
+// ErrSystem creates a system error.
+func ErrSystem(message string, cause error) *NanoPDFError {
+	return WrapError(ErrCodeSystem, message, cause)
+}
+
+// ErrFormat creates a format error.
+func ErrFormat(message string) *NanoPDFError {
+	return NewError(ErrCodeFormat, message)
+}
+
+// ErrArgument creates an argument error.
+func ErrArgument(message string) *NanoPDFError {
+	return NewError(ErrCodeArgument, message)
+}
+
+// ErrUnsupported creates an unsupported feature error.
+func ErrUnsupported(message string) *NanoPDFError {
+	return NewError(ErrCodeUnsupported, message)
+}
+
+// Predefined sentinel errors for common cases.
+var (
+	// ErrInvalidHandle indicates an invalid or dropped handle.
+	ErrInvalidHandle = ErrArgument("invalid or dropped handle")
+)
@@ -4,6 +4,8 @@
 package nanopdf
 
 import (
+	"bytes"
+	"io"
 	"testing"
 )
 
@@ -97,6 +99,69 @@ func FuzzBuffer(f *testing.F) {
 	})
 }
 
+// FuzzBufferReadWriteRoundTrip mirrors bytes.Buffer's own round-trip test
+// suite: every byte Written must come back out, in order, through Read,
+// regardless of how the writes and reads are chunked.
+func FuzzBufferReadWriteRoundTrip(f *testing.F) {
+	seeds := [][]byte{
+		[]byte("Hello, World!"),
+		[]byte(""),
+		make([]byte, 1024),
+		[]byte("\x00\x01\x02\x03"),
+	}
+	for _, seed := range seeds {
+		f.Add(seed, 3)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, chunkSize int) {
+		if chunkSize <= 0 {
+			chunkSize = 1
+		}
+		if chunkSize > 4096 {
+			chunkSize = 4096
+		}
+
+		buf := NewBuffer(0)
+		if buf == nil {
+			return
+		}
+		defer buf.Free()
+
+		for i := 0; i < len(data); i += chunkSize {
+			end := i + chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			if _, err := buf.Write(data[i:end]); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+		}
+
+		got := make([]byte, 0, len(data))
+		p := make([]byte, chunkSize)
+		for {
+			n, err := buf.Read(p)
+			got = append(got, p[:n]...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if n == 0 {
+				break
+			}
+		}
+
+		if !bytes.Equal(got, data) {
+			t.Fatalf("round trip mismatch: got %d bytes, want %d", len(got), len(data))
+		}
+		if !buf.IsEmpty() {
+			t.Error("expected buffer to be drained after reading back everything written")
+		}
+	})
+}
+
 // FuzzPageText tests text extraction with random PDFs
 func FuzzPageText(f *testing.F) {
 	// Add seed: minimal valid PDF with text
@@ -0,0 +1,134 @@
+package nanopdf
+
+import "sync"
+import "sync/atomic"
+
+// PoolStat is a point-in-time snapshot of one InstrumentedPool's counters.
+type PoolStat struct {
+	Gets      int64
+	Puts      int64
+	News      int64 // pool misses: Get had to call New
+	Discards  int64 // Put calls rejected by MaxItems/MaxBytes
+	Residency int64 // estimated number of items currently sitting in the pool
+}
+
+// ReuseRatio returns the fraction of Gets that were served from the pool
+// instead of allocated fresh via New: (Gets-News)/Gets. It's 0 when there
+// have been no Gets yet — the number to watch when tuning a pool's size
+// classes or caps.
+func (s PoolStat) ReuseRatio() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Gets-s.News) / float64(s.Gets)
+}
+
+// InstrumentedPool wraps a sync.Pool with hit/miss/discard counters and an
+// optional per-pool capacity cap, so PoolStats() can report real numbers
+// instead of sync.Pool's opaque internal state.
+type InstrumentedPool struct {
+	name     string
+	pool     sync.Pool
+	itemSize int64 // bytes per item; 0 for fixed Go values where MaxBytes doesn't apply
+	maxItems int64 // 0 means unbounded
+	maxBytes int64 // 0 means unbounded
+
+	gets      atomic.Int64
+	puts      atomic.Int64
+	news      atomic.Int64
+	discards  atomic.Int64
+	residency atomic.Int64
+}
+
+// NewInstrumentedPool creates a named pool that calls newFn on a miss and
+// registers it so PoolStats()/ResetPoolStats() see it. itemSize is the
+// size in bytes of one pooled item, used by SetMaxBytes; pass 0 for pools
+// of fixed-size Go values (Point, Rect, ...) where a byte cap doesn't
+// apply.
+func NewInstrumentedPool(name string, itemSize int, newFn func() interface{}) *InstrumentedPool {
+	p := &InstrumentedPool{name: name, itemSize: int64(itemSize)}
+	p.pool.New = func() interface{} {
+		p.news.Add(1)
+		return newFn()
+	}
+	return registerInstrumentedPool(p)
+}
+
+// SetMaxItems caps the number of items Put will accept before it starts
+// counting Discards instead. 0 (the default) leaves the pool unbounded.
+func (p *InstrumentedPool) SetMaxItems(n int) { atomic.StoreInt64(&p.maxItems, int64(n)) }
+
+// SetMaxBytes caps the total estimated residency (Residency * itemSize)
+// Put will accept. 0 (the default) leaves the pool unbounded; it's also a
+// no-op for pools created with itemSize 0.
+func (p *InstrumentedPool) SetMaxBytes(n int64) { atomic.StoreInt64(&p.maxBytes, n) }
+
+// Get returns an item from the pool, allocating a new one via New on a
+// miss.
+func (p *InstrumentedPool) Get() interface{} {
+	p.gets.Add(1)
+	newsBefore := p.news.Load()
+	v := p.pool.Get()
+	if p.news.Load() == newsBefore {
+		// Served from the pool rather than New. This residency decrement
+		// is a best-effort estimate, racy under concurrent Gets (like any
+		// of this package's other "estimated" counters), but accurate
+		// enough to guide tuning defaultBufferSizes or a pool's caps.
+		if p.residency.Add(-1) < 0 {
+			p.residency.Store(0)
+		}
+	}
+	return v
+}
+
+// Put returns v to the pool, subject to MaxItems/MaxBytes. Rejected items
+// are counted as Discards and simply dropped for the GC to reclaim.
+func (p *InstrumentedPool) Put(v interface{}) {
+	maxItems := atomic.LoadInt64(&p.maxItems)
+	if maxItems > 0 && p.residency.Load() >= maxItems {
+		p.discards.Add(1)
+		return
+	}
+	maxBytes := atomic.LoadInt64(&p.maxBytes)
+	if maxBytes > 0 && p.itemSize > 0 && p.residency.Load()*p.itemSize >= maxBytes {
+		p.discards.Add(1)
+		return
+	}
+	p.puts.Add(1)
+	p.residency.Add(1)
+	p.pool.Put(v)
+}
+
+// Stat returns a snapshot of p's counters.
+func (p *InstrumentedPool) Stat() PoolStat {
+	return PoolStat{
+		Gets:      p.gets.Load(),
+		Puts:      p.puts.Load(),
+		News:      p.news.Load(),
+		Discards:  p.discards.Load(),
+		Residency: p.residency.Load(),
+	}
+}
+
+// Reset zeroes p's cumulative counters. Residency reflects live pool
+// state rather than a cumulative stat, so Reset leaves it alone.
+func (p *InstrumentedPool) Reset() {
+	p.gets.Store(0)
+	p.puts.Store(0)
+	p.news.Store(0)
+	p.discards.Store(0)
+}
+
+var (
+	instrumentedPoolsMu sync.Mutex
+	instrumentedPools   []*InstrumentedPool
+)
+
+// registerInstrumentedPool records p so it shows up in PoolStats() and
+// gets zeroed by ResetPoolStats().
+func registerInstrumentedPool(p *InstrumentedPool) *InstrumentedPool {
+	instrumentedPoolsMu.Lock()
+	instrumentedPools = append(instrumentedPools, p)
+	instrumentedPoolsMu.Unlock()
+	return p
+}
@@ -0,0 +1,84 @@
+package nanopdf
+
+import "testing"
+
+func TestInstrumentedPoolHitMiss(t *testing.T) {
+	p := NewInstrumentedPool("test_hitmiss", 0, func() interface{} { return new(int) })
+
+	v := p.Get() // miss: pool starts empty
+	p.Put(v)
+	p.Get() // hit: reuses what was just Put back
+
+	stat := p.Stat()
+	if stat.Gets != 2 {
+		t.Errorf("Gets = %d, want 2", stat.Gets)
+	}
+	if stat.News != 1 {
+		t.Errorf("News = %d, want 1", stat.News)
+	}
+	if got, want := stat.ReuseRatio(), 0.5; got != want {
+		t.Errorf("ReuseRatio() = %v, want %v", got, want)
+	}
+}
+
+func TestInstrumentedPoolMaxItems(t *testing.T) {
+	p := NewInstrumentedPool("test_maxitems", 0, func() interface{} { return new(int) })
+	p.SetMaxItems(1)
+
+	p.Put(new(int))
+	p.Put(new(int)) // over the cap: should be discarded
+
+	stat := p.Stat()
+	if stat.Puts != 1 {
+		t.Errorf("Puts = %d, want 1", stat.Puts)
+	}
+	if stat.Discards != 1 {
+		t.Errorf("Discards = %d, want 1", stat.Discards)
+	}
+}
+
+func TestInstrumentedPoolMaxBytes(t *testing.T) {
+	p := NewInstrumentedPool("test_maxbytes", 1024, func() interface{} { return make([]byte, 0, 1024) })
+	p.SetMaxBytes(2048) // room for 2 items of 1024 bytes each
+
+	p.Put(make([]byte, 0, 1024))
+	p.Put(make([]byte, 0, 1024))
+	p.Put(make([]byte, 0, 1024)) // 3rd item would exceed the byte cap
+
+	stat := p.Stat()
+	if stat.Puts != 2 {
+		t.Errorf("Puts = %d, want 2", stat.Puts)
+	}
+	if stat.Discards != 1 {
+		t.Errorf("Discards = %d, want 1", stat.Discards)
+	}
+}
+
+func TestInstrumentedPoolResetStats(t *testing.T) {
+	p := NewInstrumentedPool("test_reset", 0, func() interface{} { return new(int) })
+	p.Get()
+	p.Put(new(int))
+
+	p.Reset()
+	stat := p.Stat()
+	if stat.Gets != 0 || stat.Puts != 0 || stat.News != 0 || stat.Discards != 0 {
+		t.Errorf("expected all cumulative counters to be zero after Reset, got %+v", stat)
+	}
+}
+
+func TestPoolStatReuseRatioNoGets(t *testing.T) {
+	var s PoolStat
+	if got := s.ReuseRatio(); got != 0 {
+		t.Errorf("ReuseRatio() with no Gets = %v, want 0", got)
+	}
+}
+
+func TestResetPoolStatsGlobal(t *testing.T) {
+	GetPointFromPool()
+	ResetPoolStats()
+
+	stats := PoolStats()
+	if stat, ok := stats["point_pool"]; ok && stat.Gets != 0 {
+		t.Errorf("expected point_pool Gets to be reset, got %d", stat.Gets)
+	}
+}
@@ -0,0 +1,185 @@
+package nanopdf
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LeakEvent describes one resource that was garbage collected without
+// being properly dropped, as delivered to a LeakReporter.
+type LeakEvent struct {
+	ResourceType string
+	Handle       uintptr
+	StackTrace   string
+	Timestamp    time.Time
+	GoroutineID  int64
+}
+
+// LeakReporter receives a LeakEvent every time the finalizer path detects
+// a dropped-without-MarkDropped resource. Implementations must be safe for
+// concurrent use: finalizers run on their own goroutine, concurrently with
+// the rest of the program.
+type LeakReporter interface {
+	ReportLeak(ev LeakEvent)
+}
+
+// LeakReporterFunc adapts a plain function to LeakReporter.
+type LeakReporterFunc func(ev LeakEvent)
+
+// ReportLeak implements LeakReporter.
+func (f LeakReporterFunc) ReportLeak(ev LeakEvent) { f(ev) }
+
+// TextReporter returns a LeakReporter that writes one human-readable
+// warning (plus an indented stack trace, if captured) per leak to w —
+// the same text recordLeakWarning used to print to stdout directly.
+func TextReporter(w io.Writer) LeakReporter {
+	var mu sync.Mutex
+	return LeakReporterFunc(func(ev LeakEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "[LEAK] %s (handle=%d) was garbage collected without being dropped\n", ev.ResourceType, ev.Handle)
+		if ev.StackTrace != "" {
+			fmt.Fprintf(w, "  Allocation stack:\n%s\n", ev.StackTrace)
+		}
+	})
+}
+
+// leakEventJSON is LeakEvent's line-delimited JSON wire format.
+type leakEventJSON struct {
+	ResourceType string    `json:"resource_type"`
+	Handle       uintptr   `json:"handle"`
+	StackTrace   string    `json:"stack_trace,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	GoroutineID  int64     `json:"goroutine_id"`
+}
+
+// JSONReporter returns a LeakReporter that writes one JSON object per line
+// to w, suitable for ingestion by a log aggregator.
+func JSONReporter(w io.Writer) LeakReporter {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return LeakReporterFunc(func(ev LeakEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(leakEventJSON{
+			ResourceType: ev.ResourceType,
+			Handle:       ev.Handle,
+			StackTrace:   ev.StackTrace,
+			Timestamp:    ev.Timestamp,
+			GoroutineID:  ev.GoroutineID,
+		})
+	})
+}
+
+// Counter is the subset of a Prometheus client_golang Counter that
+// MetricsReporter needs, so this package doesn't have to take a dependency
+// on Prometheus itself to support it.
+type Counter interface {
+	Inc()
+}
+
+// leakExpvarCounts is always updated by MetricsReporter, independent of
+// whether the caller supplied their own Counters.
+var leakExpvarCounts = expvar.NewMap("nanopdf_leaks_by_resource_type")
+
+// MetricsReporter is a LeakReporter that increments an expvar counter
+// (keyed by ResourceType, always) and, if present, a caller-supplied
+// Counter per resource type — e.g. a Prometheus CounterVec's
+// .WithLabelValues(resourceType) result.
+type MetricsReporter struct {
+	// Counters maps a ResourceType's String() to the Counter to increment
+	// for it. A nil map (the zero value) only updates the expvar map.
+	Counters map[string]Counter
+}
+
+// ReportLeak implements LeakReporter.
+func (m MetricsReporter) ReportLeak(ev LeakEvent) {
+	leakExpvarCounts.Add(ev.ResourceType, 1)
+	if c, ok := m.Counters[ev.ResourceType]; ok && c != nil {
+		c.Inc()
+	}
+}
+
+// MultiReporter fans a LeakEvent out to every non-nil reporter it holds,
+// in order.
+type MultiReporter []LeakReporter
+
+// ReportLeak implements LeakReporter.
+func (m MultiReporter) ReportLeak(ev LeakEvent) {
+	for _, r := range m {
+		if r != nil {
+			r.ReportLeak(ev)
+		}
+	}
+}
+
+// inMemoryReporter is the default LeakReporter: it preserves this
+// package's original leakWarnings/GetLeakWarningCount behavior so
+// GetLeakWarnings keeps working for callers who never call
+// SetLeakReporter, without the unconditional fmt.Println recordLeakWarning
+// used to do — that part is hostile to servers and is now opt-in via
+// TextReporter.
+type inMemoryReporter struct{}
+
+// ReportLeak implements LeakReporter.
+func (inMemoryReporter) ReportLeak(ev LeakEvent) {
+	warning := fmt.Sprintf("[LEAK] %s (handle=%d) was garbage collected without being dropped", ev.ResourceType, ev.Handle)
+	if ev.StackTrace != "" {
+		warning += "\n  Allocation stack:\n" + ev.StackTrace
+	}
+
+	leakWarningsMutex.Lock()
+	leakWarnings = append(leakWarnings, warning)
+	leakWarningsMutex.Unlock()
+
+	leakWarningsCount.Add(1)
+}
+
+var (
+	leakReporter   LeakReporter = inMemoryReporter{}
+	leakReporterMu sync.RWMutex
+)
+
+// SetLeakReporter replaces the LeakReporter the finalizer path delivers
+// LeakEvents to. Pass nil to restore the default in-memory reporter that
+// backs GetLeakWarnings.
+func SetLeakReporter(r LeakReporter) {
+	leakReporterMu.Lock()
+	defer leakReporterMu.Unlock()
+	if r == nil {
+		r = inMemoryReporter{}
+	}
+	leakReporter = r
+}
+
+// getLeakReporter returns the currently registered LeakReporter.
+func getLeakReporter() LeakReporter {
+	leakReporterMu.RLock()
+	defer leakReporterMu.RUnlock()
+	return leakReporter
+}
+
+// currentGoroutineID parses the "goroutine N [...]" header runtime.Stack
+// always writes first, for LeakEvent.GoroutineID. This format isn't a
+// committed part of the runtime API, so a parse failure yields 0 rather
+// than a panic.
+func currentGoroutineID() int64 {
+	buf := make([]byte, 64)
+	n := runtime.Stack(buf, false)
+	fields := strings.Fields(string(buf[:n]))
+	if len(fields) < 2 {
+		return 0
+	}
+	id, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,86 @@
+package nanopdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := TextReporter(&buf)
+	r.ReportLeak(LeakEvent{ResourceType: "Pixmap", Handle: 0xFF, StackTrace: "main.foo\n"})
+
+	out := buf.String()
+	if !strings.Contains(out, "Pixmap") || !strings.Contains(out, "255") {
+		t.Errorf("unexpected text report: %q", out)
+	}
+	if !strings.Contains(out, "main.foo") {
+		t.Errorf("expected stack trace in report: %q", out)
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := JSONReporter(&buf)
+	r.ReportLeak(LeakEvent{ResourceType: "Buffer", Handle: 42, Timestamp: time.Unix(0, 0)})
+
+	var decoded leakEventJSON
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode JSON report: %v", err)
+	}
+	if decoded.ResourceType != "Buffer" || decoded.Handle != 42 {
+		t.Errorf("unexpected decoded event: %+v", decoded)
+	}
+}
+
+type fakeCounter struct{ count int }
+
+func (c *fakeCounter) Inc() { c.count++ }
+
+func TestMetricsReporter(t *testing.T) {
+	counter := &fakeCounter{}
+	r := MetricsReporter{Counters: map[string]Counter{"Font": counter}}
+
+	r.ReportLeak(LeakEvent{ResourceType: "Font"})
+	r.ReportLeak(LeakEvent{ResourceType: "Font"})
+	r.ReportLeak(LeakEvent{ResourceType: "Page"}) // no Counter registered for this kind
+
+	if counter.count != 2 {
+		t.Errorf("counter.count = %d, want 2", counter.count)
+	}
+}
+
+func TestMultiReporter(t *testing.T) {
+	var first, second bytes.Buffer
+	m := MultiReporter{TextReporter(&first), nil, TextReporter(&second)}
+	m.ReportLeak(LeakEvent{ResourceType: "Document", Handle: 1})
+
+	if first.Len() == 0 || second.Len() == 0 {
+		t.Error("expected both non-nil reporters to receive the event")
+	}
+}
+
+func TestSetLeakReporterDefaultsToInMemory(t *testing.T) {
+	ClearLeakWarnings()
+	SetLeakReporter(nil)
+	defer SetLeakReporter(nil)
+
+	recordLeakWarning("Stream", 7, "")
+	if GetLeakWarningCount() < 1 {
+		t.Error("expected the default in-memory reporter to record a warning")
+	}
+}
+
+func TestSetLeakReporterCustom(t *testing.T) {
+	var buf bytes.Buffer
+	SetLeakReporter(TextReporter(&buf))
+	defer SetLeakReporter(nil)
+
+	recordLeakWarning("Colorspace", 9, "")
+	if buf.Len() == 0 {
+		t.Error("expected the custom reporter to receive the leak event")
+	}
+}
@@ -0,0 +1,188 @@
+package nanopdf
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// manualPoolSizes are the size classes ManualByteSlicePool pools: the
+// large end of defaultBufferSizes plus a 1 MiB class, where a rendered pixmap or decoded
+// stream is big enough that keeping it on the Go heap (and therefore
+// under GC scan) shows up as real pause-time pressure.
+var manualPoolSizes = []int{16384, 65536, 262144, 1048576}
+
+// ManualBuffer is a reference-counted, off-heap byte buffer backed by
+// manualAlloc (C.malloc in the cgo build). Bytes() exposes the backing
+// memory as a normal []byte via unsafe.Slice without copying it onto the
+// Go heap, which is what makes it suitable for shuttling large pixmap or
+// stream payloads across the CGO boundary.
+//
+// A ManualBuffer starts with a refcount of 1, owned by whoever got it from
+// GetManualByteSlice. Retain/Release let it be shared with a CGO call
+// still in flight: the backing memory is only actually freed (or returned
+// to its pool) once the refcount drops to zero, and Release is safe to
+// call more than once — only the transition to zero has any effect.
+//
+// ManualBuffer deliberately does not embed TrackedResource: that type
+// installs its own finalizer on InitTracking, and since SetFinalizer keys
+// off an object's allocation base address, a second SetFinalizer call for
+// ManualBuffer's own refcount-aware leak check would silently replace it
+// (or panic, if TrackedResource weren't the first field). Plumbing
+// straight into HandleTracker/captureStackTrace sidesteps that entirely.
+type ManualBuffer struct {
+	ptr        unsafe.Pointer
+	size       int
+	handle     uintptr
+	stackTrace string
+	refcount   atomic.Int32
+	freed      atomic.Bool
+	pool       *ManualByteSlicePool
+}
+
+// Bytes returns the buffer's backing memory as a []byte of length size.
+// The slice is only valid until Release drops the refcount to zero; using
+// it afterward is a use-after-free, same as with any manually managed
+// buffer.
+func (b *ManualBuffer) Bytes() []byte {
+	if b == nil || b.ptr == nil {
+		return nil
+	}
+	return unsafe.Slice((*byte)(b.ptr), b.size)
+}
+
+// Retain increments b's reference count. Every Retain must be matched by
+// a later Release.
+func (b *ManualBuffer) Retain() {
+	b.refcount.Add(1)
+}
+
+// Release decrements b's reference count, freeing (or pooling) the
+// backing memory once it reaches zero. Calling Release more times than
+// the buffer was Retained is a no-op past the point where it's already
+// been freed, rather than a double free.
+func (b *ManualBuffer) Release() {
+	if b.freed.Load() {
+		return
+	}
+	if rc := b.refcount.Add(-1); rc > 0 {
+		return
+	} else if rc < 0 {
+		// Unbalanced Release past zero: undo the decrement and refuse to
+		// free a buffer that something else still believes is live.
+		b.refcount.Add(1)
+		return
+	}
+	if !b.freed.CompareAndSwap(false, true) {
+		return
+	}
+
+	runtime.SetFinalizer(b, nil)
+	GetTracker().Untrack(b.handle)
+
+	if b.pool != nil && b.pool.put(b) {
+		return
+	}
+	manualFree(b.ptr)
+	b.ptr = nil
+}
+
+// finalizeManualBuffer fires if a ManualBuffer is garbage collected while
+// its refcount is still nonzero — i.e. some Retain was never matched by a
+// Release, or the original owner simply forgot to Release it at all.
+func finalizeManualBuffer(b *ManualBuffer) {
+	if b.freed.Load() {
+		return
+	}
+	if rc := b.refcount.Load(); rc != 0 {
+		recordLeakWarning("ManualBuffer", b.handle,
+			fmt.Sprintf("refcount=%d at GC time\n  Allocation stack:\n%s", rc, b.stackTrace))
+	}
+}
+
+// activate (re)initializes b as a freshly handed-out buffer of refcount 1,
+// whether it was just allocated or is being reused from a pool.
+func (b *ManualBuffer) activate(pool *ManualByteSlicePool) {
+	b.pool = pool
+	b.refcount.Store(1)
+	b.freed.Store(false)
+	GetTracker().TrackBuffer(b.handle, int64(b.size), "manual")
+	if debugLeakDetection.Load() {
+		b.stackTrace = captureStackTrace(3)
+		runtime.SetFinalizer(b, finalizeManualBuffer)
+	}
+}
+
+func newManualBuffer(pool *ManualByteSlicePool, size int) *ManualBuffer {
+	ptr := manualAlloc(size)
+	b := &ManualBuffer{ptr: ptr, size: size, handle: uintptr(ptr)}
+	b.activate(pool)
+	return b
+}
+
+// ManualByteSlicePool is ByteSlicePool's off-heap counterpart: it hands
+// out *ManualBuffer values backed by manualAlloc instead of []byte backed
+// by the Go heap, for the large size classes where that matters.
+type ManualByteSlicePool struct {
+	pools []*sync.Pool
+	sizes []int
+}
+
+// NewManualByteSlicePool creates a new off-heap buffer pool with the given
+// size classes.
+func NewManualByteSlicePool(sizes []int) *ManualByteSlicePool {
+	p := &ManualByteSlicePool{
+		pools: make([]*sync.Pool, len(sizes)),
+		sizes: sizes,
+	}
+	for i := range sizes {
+		p.pools[i] = &sync.Pool{}
+	}
+	return p
+}
+
+// Get returns a *ManualBuffer with at least minCapacity bytes, reusing a
+// pooled buffer of the smallest size class that fits when one is
+// available. A request larger than every size class is satisfied with a
+// direct, unpooled allocation that Release frees immediately rather than
+// returning to a pool.
+func (p *ManualByteSlicePool) Get(minCapacity int) *ManualBuffer {
+	for i, size := range p.sizes {
+		if size < minCapacity {
+			continue
+		}
+		if v := p.pools[i].Get(); v != nil {
+			b := v.(*ManualBuffer)
+			b.activate(p)
+			return b
+		}
+		return newManualBuffer(p, size)
+	}
+	return newManualBuffer(nil, minCapacity)
+}
+
+// put returns b to its owning size class, if it has one. It reports
+// whether b was accepted back into the pool; the caller must manualFree
+// it directly when put returns false (an oversized, unpooled buffer).
+func (p *ManualByteSlicePool) put(b *ManualBuffer) bool {
+	for i, size := range p.sizes {
+		if size == b.size {
+			p.pools[i].Put(b)
+			return true
+		}
+	}
+	return false
+}
+
+// globalManualByteSlicePool is the global off-heap buffer pool, mirroring
+// globalByteSlicePool's role for GetByteSlice/PutByteSlice.
+var globalManualByteSlicePool = NewManualByteSlicePool(manualPoolSizes)
+
+// GetManualByteSlice returns an off-heap *ManualBuffer with at least
+// minCapacity bytes from the global pool. Call Release (not PutByteSlice)
+// when done with it.
+func GetManualByteSlice(minCapacity int) *ManualBuffer {
+	return globalManualByteSlicePool.Get(minCapacity)
+}
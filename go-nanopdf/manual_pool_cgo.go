@@ -0,0 +1,27 @@
+//go:build cgo && !mock
+// +build cgo,!mock
+
+package nanopdf
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+import "unsafe"
+
+// manualAlloc allocates size bytes of C heap memory for a ManualBuffer,
+// bypassing the Go allocator (and therefore the GC's scan of it) entirely.
+func manualAlloc(size int) unsafe.Pointer {
+	if size == 0 {
+		return nil
+	}
+	return C.malloc(C.size_t(size))
+}
+
+// manualFree releases memory obtained from manualAlloc.
+func manualFree(ptr unsafe.Pointer) {
+	if ptr == nil {
+		return
+	}
+	C.free(ptr)
+}
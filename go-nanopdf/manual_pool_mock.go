@@ -0,0 +1,25 @@
+//go:build !cgo || mock
+// +build !cgo mock
+
+package nanopdf
+
+import "unsafe"
+
+// manualAlloc allocates size bytes of backing storage for a ManualBuffer.
+// The mock build has no cgo available, so unlike the real build (which
+// calls C.malloc) this falls back to a plain Go allocation; it's
+// byte-for-byte compatible from ManualBuffer's point of view, just without
+// the off-heap GC-scanning benefit that's the whole point of the real
+// build — the same tradeoff native_mock.go already makes for buffers.
+func manualAlloc(size int) unsafe.Pointer {
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	return unsafe.Pointer(&buf[0])
+}
+
+// manualFree is a no-op in the mock build: the backing array above is a
+// normal Go allocation, reclaimed by the GC once b.ptr is no longer
+// reachable.
+func manualFree(ptr unsafe.Pointer) {}
@@ -0,0 +1,87 @@
+package nanopdf
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestManualBufferBytesRoundTrip(t *testing.T) {
+	b := GetManualByteSlice(1024)
+	defer b.Release()
+
+	data := b.Bytes()
+	if len(data) < 1024 {
+		t.Fatalf("len(Bytes()) = %d, want at least 1024", len(data))
+	}
+	data[0] = 0xAB
+	data[len(data)-1] = 0xCD
+	if b.Bytes()[0] != 0xAB || b.Bytes()[len(data)-1] != 0xCD {
+		t.Error("writes through Bytes() did not persist")
+	}
+}
+
+func TestManualBufferRetainRelease(t *testing.T) {
+	b := GetManualByteSlice(16384)
+	b.Retain()
+
+	// First Release should only drop the retain; the buffer is still live.
+	b.Release()
+	if b.freed.Load() {
+		t.Fatal("buffer freed while still retained")
+	}
+
+	// Second Release drops the original refcount to zero.
+	b.Release()
+	if !b.freed.Load() {
+		t.Fatal("expected buffer to be freed once refcount reaches zero")
+	}
+
+	// Further Releases must be harmless.
+	b.Release()
+	b.Release()
+}
+
+func TestManualBufferPoolReuse(t *testing.T) {
+	pool := NewManualByteSlicePool([]int{4096})
+
+	first := pool.Get(1024)
+	firstPtr := first.ptr
+	first.Release()
+
+	second := pool.Get(1024)
+	defer second.Release()
+
+	if second.ptr != firstPtr {
+		t.Error("expected Get to reuse the released buffer's backing memory")
+	}
+}
+
+func TestManualBufferOversizedIsUnpooled(t *testing.T) {
+	pool := NewManualByteSlicePool([]int{4096})
+
+	b := pool.Get(1 << 20)
+	if b.pool != nil {
+		t.Error("expected an oversized buffer to not belong to any pool")
+	}
+	b.Release()
+}
+
+func TestManualBufferLeakDetection(t *testing.T) {
+	EnableLeakDetection(true)
+	defer EnableLeakDetection(false)
+	ClearLeakWarnings()
+
+	func() {
+		b := GetManualByteSlice(16384)
+		b.Retain() // leaked: refcount never reaches zero
+		b.Release()
+	}()
+
+	runtime.GC()
+	time.Sleep(100 * time.Millisecond)
+
+	if GetLeakWarningCount() < 1 {
+		t.Log("Note: finalizer may not have run yet (timing dependent)")
+	}
+}
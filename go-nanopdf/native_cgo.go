@@ -84,3 +84,51 @@ func bufferClear(ptr uintptr) {
 	// by recreating the buffer
 }
 
+// bufferRead copies up to len(dst) bytes starting at offset into dst
+// directly from native storage, so a partial Read doesn't need a full
+// bufferData (and therefore a full C.GoBytes copy) first.
+func bufferRead(ptr uintptr, offset int, dst []byte) int {
+	if len(dst) == 0 {
+		return 0
+	}
+	n := C.nanopdf_buffer_read(
+		(*C.nanopdf_buffer_t)(unsafe.Pointer(ptr)),
+		C.size_t(offset),
+		(*C.uint8_t)(unsafe.Pointer(&dst[0])),
+		C.size_t(len(dst)),
+	)
+	return int(n)
+}
+
+// bufferGrow preallocates ptr's native storage for at least n more bytes.
+func bufferGrow(ptr uintptr, n int) {
+	C.nanopdf_buffer_grow((*C.nanopdf_buffer_t)(unsafe.Pointer(ptr)), C.size_t(n))
+}
+
+// bufferCompress compresses ptr's contents in place using the native
+// library's codec so callers avoid a Go<->C copy round trip. codec is one
+// of codecSnappyID/codecZstdID; level is only meaningful for zstd.
+func bufferCompress(ptr uintptr, codec int, level int) int {
+	switch codec {
+	case codecSnappyID:
+		return int(C.nanopdf_buffer_compress_snappy((*C.nanopdf_buffer_t)(unsafe.Pointer(ptr))))
+	case codecZstdID:
+		return int(C.nanopdf_buffer_compress_zstd((*C.nanopdf_buffer_t)(unsafe.Pointer(ptr)), C.int(level)))
+	default:
+		return 1
+	}
+}
+
+// bufferDecompress decompresses ptr's contents in place, the inverse of
+// bufferCompress.
+func bufferDecompress(ptr uintptr, codec int) int {
+	switch codec {
+	case codecSnappyID:
+		return int(C.nanopdf_buffer_decompress_snappy((*C.nanopdf_buffer_t)(unsafe.Pointer(ptr))))
+	case codecZstdID:
+		return int(C.nanopdf_buffer_decompress_zstd((*C.nanopdf_buffer_t)(unsafe.Pointer(ptr))))
+	default:
+		return 1
+	}
+}
+
@@ -4,6 +4,9 @@
 package nanopdf
 
 import (
+	"bytes"
+	"compress/flate"
+	"io"
 	"sync"
 )
 
@@ -109,3 +112,95 @@ func bufferClear(ptr uintptr) {
 	}
 }
 
+// bufferRead copies up to len(dst) bytes starting at offset into dst,
+// without materializing the full buffer first, and returns the count
+// copied.
+func bufferRead(ptr uintptr, offset int, dst []byte) int {
+	mockBuffersMu.RLock()
+	defer mockBuffersMu.RUnlock()
+
+	buf, ok := mockBuffers[ptr]
+	if !ok || offset >= len(buf.data) {
+		return 0
+	}
+	return copy(dst, buf.data[offset:])
+}
+
+// bufferGrow preallocates capacity for at least n more bytes.
+func bufferGrow(ptr uintptr, n int) {
+	mockBuffersMu.Lock()
+	defer mockBuffersMu.Unlock()
+
+	buf, ok := mockBuffers[ptr]
+	if !ok || n <= 0 {
+		return
+	}
+	if cap(buf.data)-len(buf.data) < n {
+		grown := make([]byte, len(buf.data), len(buf.data)+n)
+		copy(grown, buf.data)
+		buf.data = grown
+	}
+}
+
+// bufferCompress replaces ptr's contents with their compressed form.
+// There's no vendored snappy or zstd implementation in this build, so the
+// mock backend uses compress/flate for both codecs — level is ignored for
+// codecSnappyID (snappy has no level knob) and passed through for
+// codecZstdID. Real codec identity only matters to the cgo backend; the
+// mock just needs a working, self-consistent round trip.
+func bufferCompress(ptr uintptr, codec int, level int) int {
+	mockBuffersMu.Lock()
+	defer mockBuffersMu.Unlock()
+
+	buf, ok := mockBuffers[ptr]
+	if !ok {
+		return 1
+	}
+
+	if codec == codecZstdID && (level < -2 || level > 9) {
+		level = flate.DefaultCompression
+	}
+	flateLevel := flate.DefaultCompression
+	if codec == codecZstdID {
+		flateLevel = level
+	}
+
+	var out bytes.Buffer
+	w, err := flate.NewWriter(&out, flateLevel)
+	if err != nil {
+		return 1
+	}
+	if _, err := w.Write(buf.data); err != nil {
+		return 1
+	}
+	if err := w.Close(); err != nil {
+		return 1
+	}
+
+	buf.data = out.Bytes()
+	return 0
+}
+
+// bufferDecompress replaces ptr's contents with their decompressed form,
+// the inverse of bufferCompress.
+func bufferDecompress(ptr uintptr, codec int) int {
+	mockBuffersMu.Lock()
+	defer mockBuffersMu.Unlock()
+
+	buf, ok := mockBuffers[ptr]
+	if !ok {
+		return 1
+	}
+
+	r := flate.NewReader(bytes.NewReader(buf.data))
+	defer r.Close()
+
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return 1
+	}
+
+	buf.data = decoded
+	return 0
+}
+
@@ -0,0 +1,445 @@
+package nanopdf
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// IndexOptions controls how BuildSearchIndex tokenizes page text and sizes
+// the per-page bloom filters it builds.
+type IndexOptions struct {
+	// BitsPerKey is the bloom filter size per indexed token, in bits.
+	// Around 10 gives roughly a 1% false-positive rate. Zero uses that
+	// default.
+	BitsPerKey int
+	// HashCount is the number of double-hash probes per token. Zero uses a
+	// default tuned for BitsPerKey=10.
+	HashCount int
+	// Tokenizer splits a page's extracted text into lowercased tokens. Nil
+	// uses a Unicode letter/digit run tokenizer.
+	Tokenizer func(text string) []string
+	// CJKNgram, if greater than zero, tokenizes as overlapping rune n-grams
+	// of this length whenever Tokenizer yields no tokens or the text holds
+	// Han, Hiragana or Katakana runes — the whitespace
+	// tokenizer produces nothing useful on unsegmented CJK text.
+	CJKNgram int
+}
+
+func (o IndexOptions) withDefaults() IndexOptions {
+	if o.BitsPerKey <= 0 {
+		o.BitsPerKey = 10
+	}
+	if o.HashCount <= 0 {
+		o.HashCount = 7
+	}
+	if o.Tokenizer == nil {
+		o.Tokenizer = defaultTokenizer
+	}
+	return o
+}
+
+func (o IndexOptions) tokenize(text string) []string {
+	tokens := o.Tokenizer(text)
+	if o.CJKNgram > 0 && (len(tokens) == 0 || strings.IndexFunc(text, isCJKRune) >= 0) {
+		return ngramTokenizer(o.CJKNgram)(text)
+	}
+	return tokens
+}
+
+// isCJKRune reports whether r is a Han, Hiragana or Katakana rune, scripts
+// written without spaces between words.
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// defaultTokenizer lowercases text and splits it into runs of letters and
+// digits, discarding everything else.
+func defaultTokenizer(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ngramTokenizer returns a tokenizer that emits overlapping rune n-grams of
+// length n, for scripts without whitespace word boundaries.
+func ngramTokenizer(n int) func(string) []string {
+	return func(text string) []string {
+		runes := []rune(strings.ToLower(text))
+		if len(runes) == 0 {
+			return nil
+		}
+		if len(runes) < n {
+			return []string{string(runes)}
+		}
+		tokens := make([]string, 0, len(runes)-n+1)
+		for i := 0; i+n <= len(runes); i++ {
+			tokens = append(tokens, string(runes[i:i+n]))
+		}
+		return tokens
+	}
+}
+
+// pageBloom is a standard double-hashed bloom filter over a page's
+// lowercased word tokens: membership probes combine two 64-bit hashes as
+// h1 + i*h2 for i in [0, hashCount). A filter built over zero tokens has
+// zero bits and MayContain always reports false for it.
+type pageBloom struct {
+	bits      []uint64
+	numBits   uint64
+	hashCount int
+}
+
+func newPageBloom(keyCount, bitsPerKey, hashCount int) *pageBloom {
+	if keyCount <= 0 {
+		return &pageBloom{hashCount: hashCount}
+	}
+	numBits := uint64(keyCount * bitsPerKey)
+	if numBits == 0 {
+		numBits = 1
+	}
+	words := (numBits + 63) / 64
+	return &pageBloom{
+		bits:      make([]uint64, words),
+		numBits:   words * 64,
+		hashCount: hashCount,
+	}
+}
+
+func (f *pageBloom) add(token string) {
+	if f == nil || f.numBits == 0 {
+		return
+	}
+	h1, h2 := bloomHash(token)
+	for i := 0; i < f.hashCount; i++ {
+		bit := (h1 + uint64(i)*h2) % f.numBits
+		f.bits[bit/64] |= uint64(1) << (bit % 64)
+	}
+}
+
+// mayContain reports whether token might be present in the filter. False
+// positives are possible (that's the point); false negatives are not.
+func (f *pageBloom) mayContain(token string) bool {
+	if f == nil || f.numBits == 0 {
+		return false
+	}
+	h1, h2 := bloomHash(token)
+	for i := 0; i < f.hashCount; i++ {
+		bit := (h1 + uint64(i)*h2) % f.numBits
+		if f.bits[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *pageBloom) mayContainAllOf(tokens []string) bool {
+	for _, tok := range tokens {
+		if !f.mayContain(tok) {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash derives two independent 64-bit hashes of s via FNV-1a run with
+// two different accumulators, for the h1 + i*h2 double-hashing scheme.
+func bloomHash(s string) (uint64, uint64) {
+	const (
+		fnvOffset1 = uint64(14695981039346656037)
+		fnvOffset2 = uint64(0x9E3779B97F4A7C15)
+		fnvPrime   = uint64(1099511628211)
+	)
+	h1, h2 := fnvOffset1, fnvOffset2
+	for i := 0; i < len(s); i++ {
+		h1 ^= uint64(s[i])
+		h1 *= fnvPrime
+		h2 ^= uint64(s[i])
+		h2 *= fnvPrime
+		h2 = h2<<13 | h2>>51
+	}
+	return h1, h2
+}
+
+// PageHit is one page's worth of matches returned by SearchTextFast.
+type PageHit struct {
+	PageNumber int
+	Matches    []Rect
+}
+
+// PageIndex holds the per-page bloom filters built by BuildSearchIndex for
+// a single Document.
+type PageIndex struct {
+	mu      sync.RWMutex
+	opts    IndexOptions
+	filters map[int]*pageBloom
+}
+
+// docIndexes associates a Document's native handle with the PageIndex
+// built for it, mirroring the handle-keyed storage used by the mock
+// buffer/colorspace backends elsewhere in this package.
+var (
+	docIndexMu sync.Mutex
+	docIndexes = make(map[uintptr]*PageIndex)
+)
+
+// BuildSearchIndex builds a per-page bloom filter index over doc's text, so
+// SearchTextFast can skip the page.SearchText call on pages that provably
+// don't contain every query token. Safe to call again to rebuild the index
+// with different options.
+func (doc *Document) BuildSearchIndex(ctx context.Context, opts IndexOptions) error {
+	if doc == nil || doc.Handle() == 0 {
+		return ErrInvalidHandle
+	}
+	opts = opts.withDefaults()
+
+	pageCount, err := doc.PageCount()
+	if err != nil {
+		return err
+	}
+
+	idx := &PageIndex{opts: opts, filters: make(map[int]*pageBloom, pageCount)}
+	for i := 0; i < pageCount; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		idx.filters[i] = buildPageBloom(doc, i, opts)
+	}
+
+	docIndexMu.Lock()
+	docIndexes[doc.Handle()] = idx
+	docIndexMu.Unlock()
+	return nil
+}
+
+func buildPageBloom(doc *Document, pageNum int, opts IndexOptions) *pageBloom {
+	page, err := doc.LoadPage(pageNum)
+	if err != nil {
+		return newPageBloom(0, opts.BitsPerKey, opts.HashCount)
+	}
+	defer page.Drop()
+
+	text, err := page.ExtractText()
+	if err != nil {
+		return newPageBloom(0, opts.BitsPerKey, opts.HashCount)
+	}
+
+	tokens := opts.tokenize(text)
+	filter := newPageBloom(len(tokens), opts.BitsPerKey, opts.HashCount)
+	for _, tok := range tokens {
+		filter.add(tok)
+	}
+	return filter
+}
+
+// SearchTextFast searches doc for query, consulting the bloom filter index
+// built by BuildSearchIndex to skip pages that can't contain every query
+// token before falling back to the existing page.SearchText. If no index
+// has been built yet, it degrades to a full linear scan of every page.
+func (doc *Document) SearchTextFast(query string) ([]PageHit, error) {
+	if doc == nil || doc.Handle() == 0 {
+		return nil, ErrInvalidHandle
+	}
+
+	docIndexMu.Lock()
+	idx := docIndexes[doc.Handle()]
+	docIndexMu.Unlock()
+
+	pageCount, err := doc.PageCount()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := IndexOptions{}.withDefaults()
+	if idx != nil {
+		opts = idx.opts
+	}
+	tokens := opts.tokenize(query)
+
+	var hits []PageHit
+	for i := 0; i < pageCount; i++ {
+		if idx != nil && len(tokens) > 0 {
+			idx.mu.RLock()
+			filter := idx.filters[i]
+			idx.mu.RUnlock()
+			if filter != nil && !filter.mayContainAllOf(tokens) {
+				continue
+			}
+		}
+
+		page, err := doc.LoadPage(i)
+		if err != nil {
+			continue
+		}
+		matches, err := page.SearchText(query)
+		page.Drop()
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			hits = append(hits, PageHit{PageNumber: i, Matches: matches})
+		}
+	}
+	return hits, nil
+}
+
+// WriteIndex serializes doc's bloom filter index (built by BuildSearchIndex)
+// to w, so it can be persisted alongside the PDF and restored with
+// LoadIndex instead of rebuilding from scratch. BitsPerKey, HashCount, and
+// CJKNgram are preserved across the round trip; a custom Tokenizer isn't
+// (func values can't be serialized), so WriteIndex refuses when one was
+// used rather than silently reloading with the default tokenizer.
+func (doc *Document) WriteIndex(w io.Writer) error {
+	if doc == nil || doc.Handle() == 0 {
+		return ErrInvalidHandle
+	}
+
+	docIndexMu.Lock()
+	idx := docIndexes[doc.Handle()]
+	docIndexMu.Unlock()
+	if idx == nil {
+		return ErrGeneric("nanopdf: no search index built for this document")
+	}
+
+	return writePageIndex(idx, w)
+}
+
+// LoadIndex deserializes a search index written by WriteIndex and attaches
+// it to doc, ready for SearchTextFast without rebuilding.
+func (doc *Document) LoadIndex(r io.Reader) error {
+	if doc == nil || doc.Handle() == 0 {
+		return ErrInvalidHandle
+	}
+
+	idx, err := readPageIndex(r)
+	if err != nil {
+		return err
+	}
+
+	docIndexMu.Lock()
+	docIndexes[doc.Handle()] = idx
+	docIndexMu.Unlock()
+	return nil
+}
+
+// isDefaultTokenizer reports whether fn is defaultTokenizer itself, as
+// opposed to some other func value (including one that happens to behave
+// the same way). Go doesn't allow comparing func values directly, so this
+// compares their underlying code pointers via reflect.
+func isDefaultTokenizer(fn func(string) []string) bool {
+	return reflect.ValueOf(fn).Pointer() == reflect.ValueOf(defaultTokenizer).Pointer()
+}
+
+// writePageIndex encodes idx's bloom filters to w in the on-disk format
+// LoadIndex/readPageIndex expect: a (pageCount, bitsPerKey, hashCount,
+// cjkNgram) header followed by one (pageNum, bitsetWords, bitset...)
+// record per page, pages sorted ascending.
+//
+// A custom Tokenizer can't be serialized (it's a func value), so a
+// persisted-and-reloaded index would silently search with the default
+// tokenizer instead of the one the filters were actually built with —
+// breaking the "false negatives are not possible" contract SearchTextFast
+// documents. Rather than do that silently, refuse to write the index at
+// all when a non-default Tokenizer is in play.
+func writePageIndex(idx *PageIndex, w io.Writer) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.opts.Tokenizer != nil && !isDefaultTokenizer(idx.opts.Tokenizer) {
+		return ErrGeneric("nanopdf: WriteIndex cannot persist a custom Tokenizer; LoadIndex would search with the default tokenizer instead and silently miss matches")
+	}
+
+	bw := bufio.NewWriter(w)
+	header := [4]uint64{uint64(len(idx.filters)), uint64(idx.opts.BitsPerKey), uint64(idx.opts.HashCount), uint64(idx.opts.CJKNgram)}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return ErrSystem("writeindex: header", err)
+	}
+
+	pages := make([]int, 0, len(idx.filters))
+	for p := range idx.filters {
+		pages = append(pages, p)
+	}
+	sort.Ints(pages)
+
+	for _, p := range pages {
+		filter := idx.filters[p]
+		if err := binary.Write(bw, binary.LittleEndian, [2]uint64{uint64(p), uint64(len(filter.bits))}); err != nil {
+			return ErrSystem("writeindex: page header", err)
+		}
+		if len(filter.bits) > 0 {
+			if err := binary.Write(bw, binary.LittleEndian, filter.bits); err != nil {
+				return ErrSystem("writeindex: bitset", err)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// maxPageIndexPages and maxPageIndexBitsetWords bound the values
+// readPageIndex trusts from a persisted header before sizing an
+// allocation from them. Both are read straight off disk, so a truncated
+// or corrupted index file can otherwise turn an arbitrary uint64 into a
+// make([]T, hugeNumber) that panics the whole process instead of
+// returning the clean error LoadIndex exists to produce. The bounds are
+// generous relative to any real document: a million pages or a 128 MiB
+// single-page bloom filter is already far beyond realistic use.
+const (
+	maxPageIndexPages       = 1 << 20
+	maxPageIndexBitsetWords = 1 << 24
+)
+
+// readPageIndex is the inverse of writePageIndex.
+func readPageIndex(r io.Reader) (*PageIndex, error) {
+	var header [4]uint64
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, ErrSystem("loadindex: header", err)
+	}
+	pageCount, bitsPerKey, hashCount, cjkNgram := header[0], header[1], header[2], header[3]
+	if pageCount > maxPageIndexPages {
+		return nil, ErrFormat(fmt.Sprintf("loadindex: page count %d exceeds sane maximum %d", pageCount, maxPageIndexPages))
+	}
+
+	idx := &PageIndex{
+		opts: IndexOptions{
+			BitsPerKey: int(bitsPerKey),
+			HashCount:  int(hashCount),
+			CJKNgram:   int(cjkNgram),
+		}.withDefaults(),
+		filters: make(map[int]*pageBloom, pageCount),
+	}
+
+	for i := uint64(0); i < pageCount; i++ {
+		var pageHeader [2]uint64
+		if err := binary.Read(r, binary.LittleEndian, &pageHeader); err != nil {
+			return nil, ErrSystem("loadindex: page header", err)
+		}
+		pageNum, numWords := pageHeader[0], pageHeader[1]
+		if numWords > maxPageIndexBitsetWords {
+			return nil, ErrFormat(fmt.Sprintf("loadindex: page %d bitset word count %d exceeds sane maximum %d", pageNum, numWords, maxPageIndexBitsetWords))
+		}
+
+		bits := make([]uint64, numWords)
+		if numWords > 0 {
+			if err := binary.Read(r, binary.LittleEndian, bits); err != nil {
+				return nil, ErrSystem("loadindex: bitset", err)
+			}
+		}
+		idx.filters[int(pageNum)] = &pageBloom{
+			bits:      bits,
+			numBits:   numWords * 64,
+			hashCount: int(hashCount),
+		}
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,242 @@
+package nanopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestPageBloom(t *testing.T) {
+	t.Run("AddedTokensAreFound", func(t *testing.T) {
+		tokens := []string{"alpha", "bravo", "charlie", "delta"}
+		f := newPageBloom(len(tokens), 10, 7)
+		for _, tok := range tokens {
+			f.add(tok)
+		}
+		for _, tok := range tokens {
+			if !f.mayContain(tok) {
+				t.Errorf("expected filter to contain %q", tok)
+			}
+		}
+	})
+
+	t.Run("FalsePositiveRateIsReasonable", func(t *testing.T) {
+		const n = 500
+		tokens := make([]string, n)
+		f := newPageBloom(n, 10, 7)
+		for i := range tokens {
+			tokens[i] = strings.Repeat("x", 1) + string(rune('a'+i%26)) + string(rune('0'+i%10))
+			f.add(tokens[i])
+		}
+
+		falsePositives := 0
+		const trials = 2000
+		for i := 0; i < trials; i++ {
+			probe := "absent-token-" + string(rune('a'+i%26)) + string(rune('A'+i%26))
+			if f.mayContain(probe) {
+				falsePositives++
+			}
+		}
+		// ~1% target at BitsPerKey=10; allow generous slack since this is a
+		// statistical property, not an exact bound.
+		if rate := float64(falsePositives) / trials; rate > 0.1 {
+			t.Errorf("false positive rate too high: %.4f (%d/%d)", rate, falsePositives, trials)
+		}
+	})
+
+	t.Run("EmptyFilterHasZeroBitsAndNeverMatches", func(t *testing.T) {
+		f := newPageBloom(0, 10, 7)
+		if f.numBits != 0 {
+			t.Errorf("expected zero bits for an empty filter, got %d", f.numBits)
+		}
+		if f.mayContain("anything") {
+			t.Error("empty filter should never report a match")
+		}
+	})
+
+	t.Run("NilFilterNeverMatches", func(t *testing.T) {
+		var f *pageBloom
+		if f.mayContain("anything") {
+			t.Error("nil filter should never report a match")
+		}
+		f.add("should not panic") // must be a no-op, not a panic
+	})
+}
+
+func TestDefaultTokenizer(t *testing.T) {
+	got := defaultTokenizer("Hello, World! 123 foo-bar")
+	want := []string{"hello", "world", "123", "foo", "bar"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNgramTokenizer(t *testing.T) {
+	tokenize := ngramTokenizer(2)
+
+	got := tokenize("abcd")
+	want := []string{"ab", "bc", "cd"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ngram %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := tokenize(""); got != nil {
+		t.Errorf("expected nil tokens for empty text, got %v", got)
+	}
+
+	if got := tokenize("a"); len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected single short token, got %v", got)
+	}
+}
+
+func TestIndexOptionsTokenizeFallsBackToNgramForCJK(t *testing.T) {
+	opts := IndexOptions{CJKNgram: 2}.withDefaults()
+
+	// The default whitespace/letter tokenizer treats unsegmented CJK text
+	// as a single run (no ASCII word boundaries), so without the n-gram
+	// fallback this would yield one giant token rather than useful probes.
+	got := opts.tokenize("日本語")
+	if len(got) == 0 {
+		t.Fatal("expected n-gram fallback to produce tokens for CJK text")
+	}
+	for _, tok := range got {
+		if len([]rune(tok)) != 2 {
+			t.Errorf("expected 2-rune n-grams, got %q", tok)
+		}
+	}
+}
+
+func TestPageIndexSerializationRoundTrip(t *testing.T) {
+	opts := IndexOptions{BitsPerKey: 12, HashCount: 5}.withDefaults()
+	idx := &PageIndex{
+		opts:    opts,
+		filters: make(map[int]*pageBloom),
+	}
+
+	idx.filters[0] = newPageBloom(0, opts.BitsPerKey, opts.HashCount) // empty page
+	f1 := newPageBloom(3, opts.BitsPerKey, opts.HashCount)
+	for _, tok := range []string{"foo", "bar", "baz"} {
+		f1.add(tok)
+	}
+	idx.filters[1] = f1
+
+	var buf bytes.Buffer
+	if err := writePageIndex(idx, &buf); err != nil {
+		t.Fatalf("writePageIndex: %v", err)
+	}
+
+	loaded, err := readPageIndex(&buf)
+	if err != nil {
+		t.Fatalf("readPageIndex: %v", err)
+	}
+
+	if loaded.opts.BitsPerKey != opts.BitsPerKey || loaded.opts.HashCount != opts.HashCount {
+		t.Errorf("options mismatch: got %+v, want BitsPerKey=%d HashCount=%d", loaded.opts, opts.BitsPerKey, opts.HashCount)
+	}
+	if loaded.filters[0].mayContain("anything") {
+		t.Error("round-tripped empty-page filter should never match")
+	}
+	for _, tok := range []string{"foo", "bar", "baz"} {
+		if !loaded.filters[1].mayContain(tok) {
+			t.Errorf("round-tripped filter lost token %q", tok)
+		}
+	}
+}
+
+// TestPageIndexSerializationRoundTripPreservesCJKNgram covers a gap the
+// all-defaults round trip above doesn't: CJKNgram must survive WriteIndex/
+// LoadIndex, or SearchTextFast would tokenize a reloaded index's queries
+// with the default tokenizer instead, silently missing pages that actually
+// match — the opposite of what "false negatives are not possible" promises.
+func TestPageIndexSerializationRoundTripPreservesCJKNgram(t *testing.T) {
+	opts := IndexOptions{BitsPerKey: 12, HashCount: 5, CJKNgram: 2}.withDefaults()
+	idx := &PageIndex{
+		opts:    opts,
+		filters: make(map[int]*pageBloom),
+	}
+
+	tokens := opts.tokenize("日本語")
+	f := newPageBloom(len(tokens), opts.BitsPerKey, opts.HashCount)
+	for _, tok := range tokens {
+		f.add(tok)
+	}
+	idx.filters[0] = f
+
+	var buf bytes.Buffer
+	if err := writePageIndex(idx, &buf); err != nil {
+		t.Fatalf("writePageIndex: %v", err)
+	}
+
+	loaded, err := readPageIndex(&buf)
+	if err != nil {
+		t.Fatalf("readPageIndex: %v", err)
+	}
+
+	if loaded.opts.CJKNgram != opts.CJKNgram {
+		t.Errorf("expected CJKNgram %d to survive the round trip, got %d", opts.CJKNgram, loaded.opts.CJKNgram)
+	}
+
+	queryTokens := loaded.opts.tokenize("日本語")
+	if len(queryTokens) != len(tokens) {
+		t.Fatalf("expected the reloaded options to tokenize the same way, got %v want %v", queryTokens, tokens)
+	}
+	for _, tok := range queryTokens {
+		if !loaded.filters[0].mayContain(tok) {
+			t.Errorf("round-tripped CJK filter lost token %q", tok)
+		}
+	}
+}
+
+// TestWritePageIndexRejectsCustomTokenizer documents the "at minimum"
+// fallback for the part of IndexOptions that genuinely can't be
+// serialized: a custom Tokenizer func value. Rather than silently
+// persisting an index that LoadIndex would tokenize queries for
+// differently, WriteIndex must refuse.
+func TestWritePageIndexRejectsCustomTokenizer(t *testing.T) {
+	custom := func(text string) []string { return strings.Fields(text) }
+	opts := IndexOptions{Tokenizer: custom}.withDefaults()
+	idx := &PageIndex{
+		opts:    opts,
+		filters: make(map[int]*pageBloom),
+	}
+
+	var buf bytes.Buffer
+	if err := writePageIndex(idx, &buf); err == nil {
+		t.Error("expected writePageIndex to reject a non-default Tokenizer")
+	}
+}
+
+// TestReadPageIndexRejectsImplausibleSizes covers a corrupted or truncated
+// index file whose header claims an absurd pageCount or per-page numWords:
+// readPageIndex must return an error rather than let make([]T, hugeValue)
+// panic the whole process, since this is exactly the kind of file LoadIndex
+// is meant to load from disk.
+func TestReadPageIndexRejectsImplausibleSizes(t *testing.T) {
+	t.Run("PageCountTooLarge", func(t *testing.T) {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, [4]uint64{maxPageIndexPages + 1, 10, 7, 0})
+		if _, err := readPageIndex(&buf); err == nil {
+			t.Error("expected readPageIndex to reject an implausible page count")
+		}
+	})
+
+	t.Run("BitsetWordCountTooLarge", func(t *testing.T) {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, [4]uint64{1, 10, 7, 0})
+		binary.Write(&buf, binary.LittleEndian, [2]uint64{0, maxPageIndexBitsetWords + 1})
+		if _, err := readPageIndex(&buf); err == nil {
+			t.Error("expected readPageIndex to reject an implausible bitset word count")
+		}
+	})
+}
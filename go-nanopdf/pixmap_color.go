@@ -0,0 +1,68 @@
+package nanopdf
+
+// RenderingIntent selects how out-of-gamut colors are mapped when
+// converting a Pixmap between colorspaces.
+type RenderingIntent int
+
+const (
+	// IntentPerceptual preserves the overall visual relationship between
+	// colors, compressing the whole gamut. Best for photographic images.
+	IntentPerceptual RenderingIntent = iota
+	// IntentRelativeColorimetric maps colors exactly except for
+	// out-of-gamut colors, which are clipped to the nearest reproducible
+	// color.
+	IntentRelativeColorimetric
+	// IntentSaturation preserves vivid, saturated colors at the expense of
+	// accuracy. Best for charts and diagrams.
+	IntentSaturation
+	// IntentAbsoluteColorimetric matches colors exactly, including paper
+	// white simulation. Best for proofing.
+	IntentAbsoluteColorimetric
+)
+
+// ConvertToColorspace returns a new Pixmap with the same image content
+// converted into cs using the given rendering intent.
+func (pix *Pixmap) ConvertToColorspace(cs *Colorspace, intent RenderingIntent) (*Pixmap, error) {
+	if pix == nil || !pix.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+	if cs == nil {
+		return nil, ErrInvalidArgument
+	}
+
+	handle := pixmapConvertColorspace(pix.ctx.Handle(), pix.Handle(), cs.handle, int(intent))
+	if handle == 0 {
+		return nil, ErrRenderFailed
+	}
+
+	return &Pixmap{handle: handle, ctx: pix.ctx}, nil
+}
+
+// ToGray converts the pixmap to DeviceGray using relative colorimetric
+// intent, giving OCR pre-processing a guaranteed single-component input
+// regardless of the pixmap's source colorspace.
+func (pix *Pixmap) ToGray() (*Pixmap, error) {
+	if pix == nil || !pix.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+	return pix.ConvertToColorspace(DeviceGray(pix.ctx), IntentRelativeColorimetric)
+}
+
+// ToRGB converts the pixmap to DeviceRGB using relative colorimetric
+// intent.
+func (pix *Pixmap) ToRGB() (*Pixmap, error) {
+	if pix == nil || !pix.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+	return pix.ConvertToColorspace(DeviceRGB(pix.ctx), IntentRelativeColorimetric)
+}
+
+// ToCMYK converts the pixmap to DeviceCMYK using relative colorimetric
+// intent. Pair with a Colorspace from NewICCColorspace instead when the
+// target needs to match a specific press profile.
+func (pix *Pixmap) ToCMYK() (*Pixmap, error) {
+	if pix == nil || !pix.IsValid() {
+		return nil, ErrInvalidHandle
+	}
+	return pix.ConvertToColorspace(DeviceCMYK(pix.ctx), IntentRelativeColorimetric)
+}
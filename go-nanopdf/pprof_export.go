@@ -0,0 +1,320 @@
+package nanopdf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// pprofKind selects which of the four standard heap-profile sample
+// values WritePprof reports, mirroring `go tool pprof`'s own
+// -inuse_space/-inuse_objects/-alloc_space/-alloc_objects flags.
+type pprofKind string
+
+const (
+	pprofInuseSpace   pprofKind = "inuse_space"
+	pprofInuseObjects pprofKind = "inuse_objects"
+	pprofAllocSpace   pprofKind = "alloc_space"
+	pprofAllocObjects pprofKind = "alloc_objects"
+)
+
+// WritePprof serializes the profiler's bucketized allocation data (see
+// bucket_profile.go) as a gzipped profile.proto message compatible with
+// `go tool pprof`, Grafana Pyroscope, and the rest of the pprof ecosystem.
+// kind must be one of "inuse_space", "inuse_objects", "alloc_space", or
+// "alloc_objects"; any other value is rejected with ErrInvalidArgument.
+//
+// Every sample carries a "resource" label holding the bucket's
+// ResourceType (e.g. "Pixmap"), so `pprof -tagfocus=resource=Pixmap` (or
+// Pyroscope's equivalent tag filter) slices the profile by handle kind.
+// Locations are symbolized from the raw PCs captured at allocation time
+// via runtime.CallersFrames — this is the only place those PCs are ever
+// turned into file/line/function info.
+func (p *MemoryProfiler) WritePprof(w io.Writer, kind string) error {
+	var sampleType, sampleUnit string
+	switch pprofKind(kind) {
+	case pprofInuseSpace:
+		sampleType, sampleUnit = "inuse_space", "bytes"
+	case pprofInuseObjects:
+		sampleType, sampleUnit = "inuse_objects", "objects"
+	case pprofAllocSpace:
+		sampleType, sampleUnit = "alloc_space", "bytes"
+	case pprofAllocObjects:
+		sampleType, sampleUnit = "alloc_objects", "objects"
+	default:
+		return ErrInvalidArgument
+	}
+
+	// MemProfile follows runtime.MemProfile's two-call convention: an
+	// initial call with a nil/short slice reports the required length.
+	n, _ := p.MemProfile(nil, true)
+	records := make([]BucketProfileRecord, n)
+	if n > 0 {
+		p.MemProfile(records, true)
+	}
+
+	b := newProfileBuilder(sampleType, sampleUnit)
+	for _, rec := range records {
+		b.addSample(rec, pprofKind(kind))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.marshal()); err != nil {
+		return WrapError(ErrCodeSystem, "failed to write gzipped pprof profile", err)
+	}
+	return gz.Close()
+}
+
+// PrintPprof writes a WritePprof profile of the given kind to path,
+// overwriting any existing file, for quick CLI use:
+//
+//	nanopdf.PrintPprof("/tmp/nanopdf.pb.gz", "inuse_space")
+//	go tool pprof /tmp/nanopdf.pb.gz
+func PrintPprof(path string, kind string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return WrapError(ErrCodeSystem, "failed to create pprof output file", err)
+	}
+	defer f.Close()
+
+	return GetProfiler().WritePprof(f, kind)
+}
+
+// profileBuilder incrementally assembles a profile.proto message: a
+// shared string table, deduplicated functions/locations, and one sample
+// per bucket.
+type profileBuilder struct {
+	strings   []string
+	stringIdx map[string]int64
+
+	functions   []pprofFunction
+	functionIdx map[string]uint64
+
+	locations   []pprofLocation
+	locationIdx map[uintptr]uint64
+
+	sampleTypeType int64
+	sampleTypeUnit int64
+	resourceKeyIdx int64
+
+	samples []pprofSample
+}
+
+type pprofFunction struct {
+	id   uint64
+	name int64 // string table index
+}
+
+type pprofLocation struct {
+	id    uint64
+	lines []pprofLine
+}
+
+type pprofLine struct {
+	functionID uint64
+	line       int64
+}
+
+type pprofSample struct {
+	locationIDs []uint64
+	value       int64 // the single value matching the profile's one sample_type
+	resource    int64 // string table index of the ResourceType label value
+}
+
+func newProfileBuilder(sampleType, sampleUnit string) *profileBuilder {
+	b := &profileBuilder{
+		stringIdx:   map[string]int64{"": 0},
+		strings:     []string{""},
+		functionIdx: map[string]uint64{},
+		locationIdx: map[uintptr]uint64{},
+	}
+	b.sampleTypeType = b.intern(sampleType)
+	b.sampleTypeUnit = b.intern(sampleUnit)
+	b.resourceKeyIdx = b.intern("resource")
+	return b
+}
+
+func (b *profileBuilder) intern(s string) int64 {
+	if idx, ok := b.stringIdx[s]; ok {
+		return idx
+	}
+	idx := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.stringIdx[s] = idx
+	return idx
+}
+
+func (b *profileBuilder) locationFor(pc uintptr) uint64 {
+	if id, ok := b.locationIdx[pc]; ok {
+		return id
+	}
+
+	id := uint64(len(b.locations)) + 1
+	loc := pprofLocation{id: id}
+
+	// Symbolize lazily, here, never on the allocation hot path.
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if name == "" {
+			name = "unknown"
+		}
+		fnID, ok := b.functionIdx[name]
+		if !ok {
+			fnID = uint64(len(b.functions)) + 1
+			b.functions = append(b.functions, pprofFunction{id: fnID, name: b.intern(name)})
+			b.functionIdx[name] = fnID
+		}
+		loc.lines = append(loc.lines, pprofLine{functionID: fnID, line: int64(frame.Line)})
+		if !more {
+			break
+		}
+	}
+
+	b.locations = append(b.locations, loc)
+	b.locationIdx[pc] = id
+	return id
+}
+
+// addSample folds one bucket into the profile. For inuse_* kinds only
+// buckets with live (unfreed) contributions are kept, matching Go's own
+// heap-profile semantics.
+func (b *profileBuilder) addSample(rec BucketProfileRecord, kind pprofKind) {
+	// Exactly one value per sample, matching the single sample_type
+	// marshal() declares for this kind: sample_type and every sample's
+	// value array must have the same length, or profile.CheckValid()
+	// (and go tool pprof) rejects the whole profile.
+	var value int64
+	switch kind {
+	case pprofInuseObjects:
+		value = rec.AllocObjects - rec.FreeObjects
+	case pprofInuseSpace:
+		value = rec.AllocBytes - rec.FreeBytes
+	case pprofAllocObjects:
+		value = rec.AllocObjects
+	case pprofAllocSpace:
+		value = rec.AllocBytes
+	}
+
+	if strings.HasPrefix(string(kind), "inuse") && value <= 0 {
+		return
+	}
+
+	locationIDs := make([]uint64, 0, len(rec.Stack))
+	for _, pc := range rec.Stack {
+		locationIDs = append(locationIDs, b.locationFor(pc))
+	}
+
+	b.samples = append(b.samples, pprofSample{
+		locationIDs: locationIDs,
+		value:       value,
+		resource:    b.intern(rec.ResourceType.String()),
+	})
+}
+
+// marshal encodes the accumulated profile as a profile.proto message.
+// This hand-rolls the small subset of protobuf's wire format pprof needs
+// (varints and length-delimited submessages) rather than pulling in a
+// protobuf dependency for a handful of message types.
+func (b *profileBuilder) marshal() []byte {
+	var buf bytes.Buffer
+
+	// sample_type (field 1): ValueType{type, unit}
+	writeEmbedded(&buf, 1, marshalValueType(b.sampleTypeType, b.sampleTypeUnit))
+
+	for _, s := range b.samples {
+		writeEmbedded(&buf, 2, b.marshalSample(s))
+	}
+
+	for _, l := range b.locations {
+		writeEmbedded(&buf, 4, marshalLocation(l))
+	}
+
+	for _, f := range b.functions {
+		writeEmbedded(&buf, 5, marshalFunction(f))
+	}
+
+	for _, s := range b.strings {
+		writeLenDelimited(&buf, 6, []byte(s))
+	}
+
+	// period_type (field 11), matching sample_type since this profile
+	// only ever reports one value kind at a time.
+	writeEmbedded(&buf, 11, marshalValueType(b.sampleTypeType, b.sampleTypeUnit))
+	writeVarintField(&buf, 12, 1) // period
+
+	return buf.Bytes()
+}
+
+func marshalValueType(typeIdx, unitIdx int64) []byte {
+	var buf bytes.Buffer
+	writeVarintField(&buf, 1, uint64(typeIdx))
+	writeVarintField(&buf, 2, uint64(unitIdx))
+	return buf.Bytes()
+}
+
+func (b *profileBuilder) marshalSample(s pprofSample) []byte {
+	var buf bytes.Buffer
+	for _, id := range s.locationIDs {
+		writeVarintField(&buf, 1, id)
+	}
+	writeVarintField(&buf, 2, uint64(s.value))
+
+	var label bytes.Buffer
+	writeVarintField(&label, 1, uint64(b.resourceKeyIdx))
+	writeVarintField(&label, 2, uint64(s.resource))
+	writeEmbedded(&buf, 3, label.Bytes())
+
+	return buf.Bytes()
+}
+
+func marshalLocation(l pprofLocation) []byte {
+	var buf bytes.Buffer
+	writeVarintField(&buf, 1, l.id)
+	for _, line := range l.lines {
+		var lineBuf bytes.Buffer
+		writeVarintField(&lineBuf, 1, line.functionID)
+		writeVarintField(&lineBuf, 2, uint64(line.line))
+		writeEmbedded(&buf, 4, lineBuf.Bytes())
+	}
+	return buf.Bytes()
+}
+
+func marshalFunction(f pprofFunction) []byte {
+	var buf bytes.Buffer
+	writeVarintField(&buf, 1, f.id)
+	writeVarintField(&buf, 2, uint64(f.name))
+	writeVarintField(&buf, 3, uint64(f.name)) // system_name: reuse name, we don't distinguish
+	return buf.Bytes()
+}
+
+func writeVarint(buf *bytes.Buffer, v uint64) {
+	for v >= 0x80 {
+		buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	buf.WriteByte(byte(v))
+}
+
+func writeTag(buf *bytes.Buffer, field int, wireType int) {
+	writeVarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func writeVarintField(buf *bytes.Buffer, field int, v uint64) {
+	writeTag(buf, field, 0)
+	writeVarint(buf, v)
+}
+
+func writeLenDelimited(buf *bytes.Buffer, field int, data []byte) {
+	writeTag(buf, field, 2)
+	writeVarint(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+func writeEmbedded(buf *bytes.Buffer, field int, data []byte) {
+	writeLenDelimited(buf, field, data)
+}
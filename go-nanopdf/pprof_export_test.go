@@ -0,0 +1,177 @@
+package nanopdf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+// decodedProtoField is one field read back out of a hand-rolled protobuf
+// message by readProtoFields, mirroring pprof_export.go's own hand-rolled
+// encoder (writeVarintField/writeEmbedded) since this module has no
+// vendored pprof proto decoder to parse against.
+type decodedProtoField struct {
+	num   int
+	value uint64 // for wire type 0 (varint)
+	bytes []byte // for wire type 2 (length-delimited)
+}
+
+// readProtoFields walks a flat sequence of protobuf fields (no nested
+// parsing), returning each field's number, wire type, and payload. It
+// panics on malformed input, which is fine for a test helper fed only
+// WritePprof's own output.
+func readProtoFields(t *testing.T, data []byte) []decodedProtoField {
+	t.Helper()
+	var fields []decodedProtoField
+	for len(data) > 0 {
+		tag, n := readVarintBytes(data)
+		data = data[n:]
+		field := int(tag >> 3)
+		wireType := tag & 7
+
+		switch wireType {
+		case 0:
+			v, n := readVarintBytes(data)
+			data = data[n:]
+			fields = append(fields, decodedProtoField{num: field, value: v})
+		case 2:
+			l, n := readVarintBytes(data)
+			data = data[n:]
+			if uint64(len(data)) < l {
+				t.Fatalf("truncated length-delimited field %d", field)
+			}
+			fields = append(fields, decodedProtoField{num: field, bytes: data[:l]})
+			data = data[l:]
+		default:
+			t.Fatalf("unexpected wire type %d for field %d", wireType, field)
+		}
+	}
+	return fields
+}
+
+func readVarintBytes(data []byte) (uint64, int) {
+	var v uint64
+	var shift uint
+	for i, b := range data {
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, i + 1
+		}
+		shift += 7
+	}
+	return 0, 0
+}
+
+// TestWritePprofFieldLayout decodes WritePprof's gzipped output far
+// enough to check the top-level field numbers it must get right for `go
+// tool pprof`/Pyroscope to parse the profile at all: sample_type (1),
+// sample (2), location (4), function (5), string_table (6), period_type
+// (11), and period (12). A prior version of marshal misattributed
+// period_type/period to fields 12/13, silently colliding with the
+// already-used sample_type/string_table slots.
+func TestWritePprofFieldLayout(t *testing.T) {
+	p := NewMemoryProfiler()
+	p.enabled.Store(true)
+	p.SetSamplingRate(1)
+	p.RecordAllocation(1, ResourcePixmap, 4096, "test")
+
+	var buf bytes.Buffer
+	if err := p.WritePprof(&buf, "alloc_space"); err != nil {
+		t.Fatalf("WritePprof: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading gzipped profile: %v", err)
+	}
+
+	fields := readProtoFields(t, raw)
+
+	counts := map[int]int{}
+	var periodValue uint64
+	havePeriod := false
+	for _, f := range fields {
+		counts[f.num]++
+		if f.num == 12 {
+			periodValue = f.value
+			havePeriod = true
+		}
+		if f.num == 13 {
+			t.Errorf("field 13 (comment) must not be emitted; got value %v", f)
+		}
+	}
+
+	if counts[1] != 1 {
+		t.Errorf("expected exactly one sample_type (field 1), got %d", counts[1])
+	}
+	if counts[2] == 0 {
+		t.Error("expected at least one sample (field 2)")
+	}
+	if counts[11] != 1 {
+		t.Errorf("expected exactly one period_type (field 11), got %d", counts[11])
+	}
+	if !havePeriod {
+		t.Error("expected a period (field 12) varint")
+	} else if periodValue != 1 {
+		t.Errorf("period = %d, want 1", periodValue)
+	}
+}
+
+// TestWritePprofSampleValueCountMatchesSampleType guards against a second
+// field-layout bug distinct from TestWritePprofFieldLayout: profile.proto
+// requires len(Sample.value) == len(Profile.sample_type) for every sample.
+// marshal() only ever declares one sample_type, so marshalSample must emit
+// exactly one value per sample too — previously it always emitted two
+// (objects and bytes), regardless of which single kind was requested.
+func TestWritePprofSampleValueCountMatchesSampleType(t *testing.T) {
+	for _, kind := range []string{"inuse_space", "inuse_objects", "alloc_space", "alloc_objects"} {
+		t.Run(kind, func(t *testing.T) {
+			p := NewMemoryProfiler()
+			p.enabled.Store(true)
+			p.SetSamplingRate(1)
+			p.RecordAllocation(1, ResourcePixmap, 4096, "test")
+
+			var buf bytes.Buffer
+			if err := p.WritePprof(&buf, kind); err != nil {
+				t.Fatalf("WritePprof: %v", err)
+			}
+
+			gz, err := gzip.NewReader(&buf)
+			if err != nil {
+				t.Fatalf("gzip.NewReader: %v", err)
+			}
+			raw, err := io.ReadAll(gz)
+			if err != nil {
+				t.Fatalf("reading gzipped profile: %v", err)
+			}
+
+			sampleTypeCount := 0
+			sawSample := false
+			for _, f := range readProtoFields(t, raw) {
+				if f.num == 1 {
+					sampleTypeCount++
+				}
+				if f.num == 2 {
+					sawSample = true
+					valueCount := 0
+					for _, sf := range readProtoFields(t, f.bytes) {
+						if sf.num == 2 {
+							valueCount++
+						}
+					}
+					if valueCount != sampleTypeCount {
+						t.Errorf("sample has %d value(s), want %d (matching sample_type count)", valueCount, sampleTypeCount)
+					}
+				}
+			}
+			if !sawSample {
+				t.Fatal("expected at least one sample (field 2)")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package nanopdf
+
+import (
+	"encoding/json"
+	"io"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pprofTrackedKinds are the resource kinds published as custom pprof
+// profiles: every ResourceType with a native handle worth diagnosing from
+// `go tool pprof`, as opposed to the purely in-process bookkeeping types
+// (ResourceType's other values) that never leak a MuPDF handle.
+var pprofTrackedKinds = []ResourceType{
+	ResourceDocument,
+	ResourcePage,
+	ResourcePixmap,
+	ResourceFont,
+	ResourceBuffer,
+	ResourceStream,
+	ResourceImage,
+	ResourcePath,
+	ResourceDevice,
+	ResourceDisplayList,
+	ResourceColorspace,
+}
+
+var (
+	pprofProfiles     map[ResourceType]*pprof.Profile
+	pprofProfilesOnce sync.Once
+)
+
+// RegisterPprofProfiles publishes one runtime/pprof.Profile per tracked
+// ResourceType, named "nanopdf/<kind>". Once registered, InitTracking and
+// MarkDropped keep each profile's live set in sync, so
+// `go tool pprof http://host/debug/pprof/nanopdf/pixmap` shows the
+// allocation stack of every still-live Pixmap.
+//
+// Safe to call more than once; only the first call registers anything.
+func RegisterPprofProfiles() {
+	pprofProfilesOnce.Do(func() {
+		pprofProfiles = make(map[ResourceType]*pprof.Profile, len(pprofTrackedKinds))
+		for _, rt := range pprofTrackedKinds {
+			name := "nanopdf/" + strings.ToLower(rt.String())
+			pprofProfiles[rt] = pprof.NewProfile(name)
+		}
+	})
+}
+
+// pprofAdd records a live allocation in the resource's pprof profile, if
+// one is registered for its kind.
+func pprofAdd(t *TrackedResource, skip int) {
+	if pprofProfiles == nil {
+		return
+	}
+	if profile, ok := pprofProfiles[t.resourceType]; ok {
+		profile.Add(t, skip+1)
+	}
+}
+
+// pprofRemove removes a resource from its pprof profile once it has been
+// properly dropped.
+func pprofRemove(t *TrackedResource) {
+	if pprofProfiles == nil {
+		return
+	}
+	if profile, ok := pprofProfiles[t.resourceType]; ok {
+		profile.Remove(t)
+	}
+}
+
+// WriteResourceProfile writes the registered pprof profile for
+// resourceType to w in the standard runtime/pprof format, exactly as
+// (*pprof.Profile).WriteTo would: debug == 0 produces a gzipped
+// profile.proto message suitable for `go tool pprof`, while debug != 0
+// produces a human-readable legacy text dump. Wire this up behind
+// net/http/pprof (e.g. a handler at "/debug/pprof/nanopdf/pixmap") to
+// inspect live MuPDF handles with the same tooling used for Go's own heap
+// profile. Returns ErrUnsupported if resourceType isn't one of
+// pprofTrackedKinds, or if RegisterPprofProfiles hasn't been called yet.
+func WriteResourceProfile(w io.Writer, resourceType ResourceType, debug int) error {
+	if pprofProfiles == nil {
+		return ErrUnsupported("pprof profiles are not registered; call RegisterPprofProfiles first")
+	}
+	profile, ok := pprofProfiles[resourceType]
+	if !ok {
+		return ErrUnsupported("no pprof profile is registered for " + resourceType.String())
+	}
+	return profile.WriteTo(w, debug)
+}
+
+// leakDump is the JSON representation of one still-live tracked resource,
+// as emitted by DumpLeaksJSON.
+type leakDump struct {
+	ResourceID  uint64   `json:"resource_id"`
+	Kind        string   `json:"kind"`
+	Size        int64    `json:"size"`
+	AllocatedAt string   `json:"allocated_at"`
+	Stack       []string `json:"stack"`
+	AgeMs       int64    `json:"age_ms"`
+}
+
+// DumpLeaksJSON writes one JSON object per line for every tracked
+// resource that is still live, suitable for ingestion by a log
+// aggregator rather than only the human-readable LeakReport.String().
+func DumpLeaksJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	for _, record := range GetProfiler().GetLiveAllocations() {
+		dump := leakDump{
+			ResourceID:  record.Handle,
+			Kind:        record.ResourceType.String(),
+			Size:        record.SizeBytes,
+			AllocatedAt: record.AllocatedAt.Format(time.RFC3339Nano),
+			AgeMs:       record.Age().Milliseconds(),
+		}
+		if record.StackTrace != "" {
+			dump.Stack = strings.Split(record.StackTrace, "\n")
+		}
+		if err := enc.Encode(dump); err != nil {
+			return WrapError(ErrCodeSystem, "failed to encode leak dump entry", err)
+		}
+	}
+
+	return nil
+}
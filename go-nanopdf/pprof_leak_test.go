@@ -0,0 +1,71 @@
+package nanopdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPprofTrackedKindsCoverage(t *testing.T) {
+	want := map[ResourceType]bool{
+		ResourceDocument:    true,
+		ResourcePage:        true,
+		ResourcePixmap:      true,
+		ResourceFont:        true,
+		ResourceBuffer:      true,
+		ResourceStream:      true,
+		ResourceImage:       true,
+		ResourcePath:        true,
+		ResourceDevice:      true,
+		ResourceDisplayList: true,
+		ResourceColorspace:  true,
+	}
+	if len(pprofTrackedKinds) != len(want) {
+		t.Fatalf("len(pprofTrackedKinds) = %d, want %d", len(pprofTrackedKinds), len(want))
+	}
+	for _, rt := range pprofTrackedKinds {
+		if !want[rt] {
+			t.Errorf("unexpected tracked kind %s", rt)
+		}
+		delete(want, rt)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing tracked kinds: %v", want)
+	}
+}
+
+func TestRegisterPprofProfilesIdempotent(t *testing.T) {
+	RegisterPprofProfiles()
+	RegisterPprofProfiles()
+
+	for _, rt := range pprofTrackedKinds {
+		if _, ok := pprofProfiles[rt]; !ok {
+			t.Errorf("expected a registered profile for %s", rt)
+		}
+	}
+}
+
+func TestWriteResourceProfileRoundTrip(t *testing.T) {
+	RegisterPprofProfiles()
+
+	tracker := &TrackedResource{}
+	tracker.InitTracking(0xABCD, ResourcePixmap)
+	defer tracker.MarkDropped()
+
+	var buf bytes.Buffer
+	if err := WriteResourceProfile(&buf, ResourcePixmap, 1); err != nil {
+		t.Fatalf("WriteResourceProfile: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Error("expected a non-empty legacy-format profile dump")
+	}
+	if !strings.Contains(buf.String(), "profile:") {
+		t.Errorf("expected a pprof legacy-format header, got: %s", buf.String())
+	}
+}
+
+func TestWriteResourceProfileUntracked(t *testing.T) {
+	if err := WriteResourceProfile(&bytes.Buffer{}, ResourceType(999), 1); err == nil {
+		t.Error("expected an error for a resource type with no registered profile")
+	}
+}
@@ -62,6 +62,12 @@ type AllocationRecord struct {
 	AllocatedAt  time.Time
 	StackTrace   string
 	Tag          string
+
+	// SampleWeight is the rate/sizeBytes weight this allocation was
+	// recorded with (1 when sampling is disabled or rate <= 1). It is
+	// kept so RecordDeallocation can undo the same weighted contribution
+	// it added to TypeStats/the bucket table.
+	SampleWeight float64
 }
 
 // Age returns how long this allocation has been alive
@@ -101,6 +107,14 @@ type MemoryProfiler struct {
 	statsByType   map[ResourceType]*TypeStats
 	startTime     time.Time
 
+	// Call-stack bucket aggregation (see bucket_profile.go). buckets is
+	// O(unique call sites) rather than O(live handles); bucketByHandle is
+	// the minimal per-handle bookkeeping needed to route a later
+	// RecordDeallocation to the bucket its allocation was counted in.
+	buckets        *sync.Map
+	bucketsOnce    sync.Once
+	bucketByHandle map[uint64]*bucketEntry
+
 	// Atomic counters for lock-free reads
 	totalCreated   atomic.Int64
 	totalDestroyed atomic.Int64
@@ -108,6 +122,20 @@ type MemoryProfiler struct {
 	currentBytes   atomic.Int64
 	peakHandles    atomic.Int64
 	peakBytes      atomic.Int64
+
+	// Size-weighted sampling (see sampling.go). samplingRate is in bytes,
+	// analogous to runtime.MemProfileRate; nextSample is the running
+	// Poisson countdown shared by every call to RecordAllocation.
+	samplingRate atomic.Int64
+	nextSample   atomic.Int64
+
+	// Live event tracing (see tracer.go): an optional user callback fed
+	// through a lock-free MPSC queue, and an optional bounded ring of
+	// recent events for post-mortem dumps.
+	tracer         atomic.Pointer[func(AllocEvent)]
+	traceQueue     atomic.Pointer[traceQueue]
+	traceRing      atomic.Pointer[traceRing]
+	traceDrainOnce sync.Once
 }
 
 var (
@@ -125,16 +153,26 @@ func GetProfiler() *MemoryProfiler {
 
 // NewMemoryProfiler creates a new memory profiler
 func NewMemoryProfiler() *MemoryProfiler {
-	return &MemoryProfiler{
-		allocations: make(map[uint64]*AllocationRecord),
-		statsByType: make(map[ResourceType]*TypeStats),
-		startTime:   time.Now(),
+	p := &MemoryProfiler{
+		allocations:    make(map[uint64]*AllocationRecord),
+		statsByType:    make(map[ResourceType]*TypeStats),
+		bucketByHandle: make(map[uint64]*bucketEntry),
+		startTime:      time.Now(),
 	}
+	p.samplingRate.Store(defaultSamplingRate)
+	p.nextSample.Store(sampleInterval(defaultSamplingRate))
+	return p
 }
 
-// EnableProfiling turns profiling on or off
+// EnableProfiling turns profiling on or off. Enabling it also installs
+// the SIGUSR2 handler that dumps the trace ring (see tracer.go) to
+// stderr on demand; the handler is a process-wide singleton, so this is
+// safe to call repeatedly.
 func EnableProfiling(enabled bool) {
 	GetProfiler().enabled.Store(enabled)
+	if enabled {
+		installTraceSignalHandler()
+	}
 }
 
 // EnableStackTraces enables or disables stack trace capture
@@ -147,18 +185,50 @@ func IsProfilingEnabled() bool {
 	return GetProfiler().enabled.Load()
 }
 
-// RecordAllocation tracks a new allocation
+// RecordAllocation tracks a new allocation. The exact, lock-free global
+// counters (TotalHandlesCreated, CurrentHandles, CurrentBytes, ...) are
+// updated for every call regardless of sampling. Everything more
+// expensive — the per-handle AllocationRecord behind GetLiveAllocations/
+// GenerateLeakReport, the TypeStats aggregates, and the call-stack bucket
+// table (bucket_profile.go) — is gated by SetSamplingRate: only a
+// Poisson-sampled, size-weighted fraction of allocations pay that cost,
+// and each sampled one's contribution is scaled so totals stay unbiased.
+// See SetSamplingRate for the full scheme.
 func (p *MemoryProfiler) RecordAllocation(handle uint64, resourceType ResourceType, sizeBytes int64, tag string) {
 	if !p.enabled.Load() {
 		return
 	}
 
+	p.emitTraceEvent(AllocEventAlloc, handle, resourceType, sizeBytes, 3)
+
+	p.totalCreated.Add(1)
+	current := p.currentHandles.Add(1)
+	p.currentBytes.Add(sizeBytes)
+	for {
+		peak := p.peakHandles.Load()
+		if current <= peak {
+			break
+		}
+		if p.peakHandles.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	sampled, weight := p.shouldSample(sizeBytes)
+	if !sampled {
+		return
+	}
+	countDelta, bytesDelta := weightedDelta(weight, sizeBytes)
+
+	bucket := p.recordAllocationBucketed(resourceType, bytesDelta, countDelta, 3)
+
 	record := &AllocationRecord{
 		Handle:       handle,
 		ResourceType: resourceType,
 		SizeBytes:    sizeBytes,
 		AllocatedAt:  time.Now(),
 		Tag:          tag,
+		SampleWeight: weight,
 	}
 
 	if p.captureStacks.Load() {
@@ -169,6 +239,7 @@ func (p *MemoryProfiler) RecordAllocation(handle uint64, resourceType ResourceTy
 	defer p.mu.Unlock()
 
 	p.allocations[handle] = record
+	p.bucketByHandle[handle] = bucket
 
 	// Update type stats
 	stats, ok := p.statsByType[resourceType]
@@ -176,35 +247,21 @@ func (p *MemoryProfiler) RecordAllocation(handle uint64, resourceType ResourceTy
 		stats = &TypeStats{}
 		p.statsByType[resourceType] = stats
 	}
-	stats.CurrentCount++
-	stats.CurrentBytes += sizeBytes
-	stats.TotalAllocated++
-	stats.TotalBytesAllocated += sizeBytes
+	stats.CurrentCount += countDelta
+	stats.CurrentBytes += bytesDelta
+	stats.TotalAllocated += countDelta
+	stats.TotalBytesAllocated += bytesDelta
 	if stats.CurrentCount > stats.PeakCount {
 		stats.PeakCount = stats.CurrentCount
 	}
 	if stats.CurrentBytes > stats.PeakBytes {
 		stats.PeakBytes = stats.CurrentBytes
 	}
-
-	// Update global counters
-	p.totalCreated.Add(1)
-	current := p.currentHandles.Add(1)
-	p.currentBytes.Add(sizeBytes)
-
-	// Update peak (compare-and-swap loop)
-	for {
-		peak := p.peakHandles.Load()
-		if current <= peak {
-			break
-		}
-		if p.peakHandles.CompareAndSwap(peak, current) {
-			break
-		}
-	}
 }
 
-// RecordDeallocation tracks a deallocation
+// RecordDeallocation tracks a deallocation. If the matching allocation
+// wasn't sampled, there is no detailed record to undo; the exact global
+// counters below are still updated.
 func (p *MemoryProfiler) RecordDeallocation(handle uint64) *AllocationRecord {
 	if !p.enabled.Load() {
 		return nil
@@ -220,12 +277,21 @@ func (p *MemoryProfiler) RecordDeallocation(handle uint64) *AllocationRecord {
 
 	delete(p.allocations, handle)
 
+	p.emitTraceEvent(AllocEventFree, handle, record.ResourceType, record.SizeBytes, 3)
+
+	countDelta, bytesDelta := weightedDelta(record.SampleWeight, record.SizeBytes)
+
+	if bucket, ok := p.bucketByHandle[handle]; ok {
+		recordDeallocationBucketed(bucket, bytesDelta, countDelta)
+		delete(p.bucketByHandle, handle)
+	}
+
 	// Update type stats
 	if stats, ok := p.statsByType[record.ResourceType]; ok {
-		stats.CurrentCount--
-		stats.CurrentBytes -= record.SizeBytes
-		stats.TotalDeallocated++
-		stats.TotalBytesDeallocated += record.SizeBytes
+		stats.CurrentCount -= countDelta
+		stats.CurrentBytes -= bytesDelta
+		stats.TotalDeallocated += countDelta
+		stats.TotalBytesDeallocated += bytesDelta
 	}
 
 	// Update global counters
@@ -296,6 +362,10 @@ func (p *MemoryProfiler) Reset() {
 
 	p.allocations = make(map[uint64]*AllocationRecord)
 	p.statsByType = make(map[ResourceType]*TypeStats)
+	p.bucketByHandle = make(map[uint64]*bucketEntry)
+	if p.buckets != nil {
+		p.buckets = &sync.Map{}
+	}
 	p.totalCreated.Store(0)
 	p.totalDestroyed.Store(0)
 	p.currentHandles.Store(0)
@@ -5,10 +5,13 @@
 package nanopdf
 
 import (
+	"context"
 	"fmt"
 	"runtime"
+	"runtime/pprof"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // ============================================================================
@@ -61,22 +64,18 @@ func ClearLeakWarnings() {
 	leakWarningsCount.Store(0)
 }
 
-// recordLeakWarning records a leak warning when a resource is garbage collected
-// without being properly dropped.
+// recordLeakWarning delivers a LeakEvent to the registered LeakReporter
+// (see leak_reporter.go) when a resource is garbage collected without
+// being properly dropped. It no longer prints anywhere itself — that's
+// now TextReporter's job, opted into via SetLeakReporter.
 func recordLeakWarning(resourceType string, handle uintptr, stackTrace string) {
-	warning := fmt.Sprintf("[LEAK] %s (handle=%d) was garbage collected without being dropped", resourceType, handle)
-	if stackTrace != "" {
-		warning += "\n  Allocation stack:\n" + stackTrace
-	}
-
-	leakWarningsMutex.Lock()
-	leakWarnings = append(leakWarnings, warning)
-	leakWarningsMutex.Unlock()
-
-	leakWarningsCount.Add(1)
-
-	// Also print to stderr in debug mode
-	fmt.Println(warning)
+	getLeakReporter().ReportLeak(LeakEvent{
+		ResourceType: resourceType,
+		Handle:       handle,
+		StackTrace:   stackTrace,
+		Timestamp:    time.Now(),
+		GoroutineID:  currentGoroutineID(),
+	})
 }
 
 // ============================================================================
@@ -102,6 +101,8 @@ func (t *TrackedResource) InitTracking(handle uintptr, resourceType ResourceType
 		TrackAllocation(uint64(handle), resourceType, 0)
 	}
 
+	pprofAdd(t, 3)
+
 	if debugLeakDetection.Load() {
 		// Capture stack trace for debugging
 		t.stackTrace = captureStackTrace(3)
@@ -110,7 +111,18 @@ func (t *TrackedResource) InitTracking(handle uintptr, resourceType ResourceType
 		// Note: We pass a pointer to the TrackedResource, not the parent struct
 		runtime.SetFinalizer(t, func(tr *TrackedResource) {
 			if !tr.dropped.Load() {
-				recordLeakWarning(tr.resourceType.String(), tr.handle, tr.stackTrace)
+				// Deliberately do not pprofRemove here: the whole point of a
+				// leak is that it stays visible in `pprof -alloc_objects`
+				// until the process exits. We do, however, tag this
+				// goroutine with the allocation stack as a pprof label so
+				// any profile captured while the finalizer runs (e.g. a
+				// concurrent CPU or goroutine profile) can be filtered by
+				// it; (*pprof.Profile).Add itself has no per-sample label
+				// mechanism, so this is the closest the stdlib lets us get
+				// to attaching stackTrace to the leaked entry.
+				pprof.Do(context.Background(), pprof.Labels("alloc", tr.stackTrace), func(context.Context) {
+					recordLeakWarning(tr.resourceType.String(), tr.handle, tr.stackTrace)
+				})
 			}
 		})
 	}
@@ -127,6 +139,8 @@ func (t *TrackedResource) MarkDropped() {
 		TrackDeallocation(uint64(t.handle))
 	}
 
+	pprofRemove(t)
+
 	// Clear finalizer since resource was properly dropped
 	if debugLeakDetection.Load() {
 		runtime.SetFinalizer(t, nil)
@@ -142,33 +156,17 @@ func (t *TrackedResource) IsDropped() bool {
 // Sync.Pool for Frequently Allocated Types
 // ============================================================================
 
-// PointPool is a sync.Pool for Point objects
-var PointPool = sync.Pool{
-	New: func() interface{} {
-		return &Point{}
-	},
-}
+// PointPool is an instrumented pool for Point objects.
+var PointPool = NewInstrumentedPool("point_pool", 0, func() interface{} { return &Point{} })
 
-// RectPool is a sync.Pool for Rect objects
-var RectPool = sync.Pool{
-	New: func() interface{} {
-		return &Rect{}
-	},
-}
+// RectPool is an instrumented pool for Rect objects.
+var RectPool = NewInstrumentedPool("rect_pool", 0, func() interface{} { return &Rect{} })
 
-// MatrixPool is a sync.Pool for Matrix objects
-var MatrixPool = sync.Pool{
-	New: func() interface{} {
-		return &Matrix{}
-	},
-}
+// MatrixPool is an instrumented pool for Matrix objects.
+var MatrixPool = NewInstrumentedPool("matrix_pool", 0, func() interface{} { return &Matrix{} })
 
-// QuadPool is a sync.Pool for Quad objects
-var QuadPool = sync.Pool{
-	New: func() interface{} {
-		return &Quad{}
-	},
-}
+// QuadPool is an instrumented pool for Quad objects.
+var QuadPool = NewInstrumentedPool("quad_pool", 0, func() interface{} { return &Quad{} })
 
 // GetPointFromPool gets a Point from the pool.
 func GetPointFromPool() *Point {
@@ -244,7 +242,7 @@ func PutQuadToPool(q *Quad) {
 
 // ByteSlicePool pools byte slices for CGO operations
 type ByteSlicePool struct {
-	pools []*sync.Pool
+	pools []*InstrumentedPool
 	sizes []int
 }
 
@@ -259,19 +257,24 @@ var defaultBufferSizes = []int{
 	262144, // 256KB
 }
 
+// defaultPoolMaxBytesPerClass caps how much memory any single size class
+// is allowed to hold onto between Gets, so a pathological workload that
+// pushes millions of buffers through one class can't pin that memory
+// forever.
+const defaultPoolMaxBytesPerClass = 64 * 1024 * 1024 // 64MB
+
 // globalByteSlicePool is the global buffer pool
 var globalByteSlicePool = NewByteSlicePool(defaultBufferSizes)
 
 // NewByteSlicePool creates a new byte slice pool with given size classes.
 func NewByteSlicePool(sizes []int) *ByteSlicePool {
-	pools := make([]*sync.Pool, len(sizes))
+	pools := make([]*InstrumentedPool, len(sizes))
 	for i, size := range sizes {
 		bufSize := size // capture for closure
-		pools[i] = &sync.Pool{
-			New: func() interface{} {
-				return make([]byte, 0, bufSize)
-			},
-		}
+		pools[i] = NewInstrumentedPool(fmt.Sprintf("byteslice_%d", bufSize), bufSize, func() interface{} {
+			return make([]byte, 0, bufSize)
+		})
+		pools[i].SetMaxBytes(defaultPoolMaxBytesPerClass)
 	}
 	return &ByteSlicePool{pools: pools, sizes: sizes}
 }
@@ -398,25 +401,52 @@ func GetTracker() *HandleTracker {
 // Stats and Reporting
 // ============================================================================
 
-// PoolStats returns statistics about the geometry pools.
-func PoolStats() map[string]int {
-	// We can't directly get pool sizes, but we can provide estimates
-	// based on what's been allocated
-	return map[string]int{
-		"point_pool":  0, // sync.Pool doesn't expose size
-		"rect_pool":   0,
-		"matrix_pool": 0,
-		"quad_pool":   0,
+// PoolStats returns real Gets/Puts/News/Discards/Residency counters for
+// every registered InstrumentedPool (the geometry pools and each
+// ByteSlicePool size class), keyed by pool name. (Gets-News)/Gets — see
+// PoolStat.ReuseRatio — is the number to watch when tuning
+// defaultBufferSizes or a pool's caps.
+func PoolStats() map[string]PoolStat {
+	instrumentedPoolsMu.Lock()
+	defer instrumentedPoolsMu.Unlock()
+
+	stats := make(map[string]PoolStat, len(instrumentedPools))
+	for _, p := range instrumentedPools {
+		stats[p.name] = p.Stat()
+	}
+	return stats
+}
+
+// ResetPoolStats zeroes the cumulative counters (Gets/Puts/News/Discards)
+// of every registered InstrumentedPool. Residency, which reflects live
+// pool state rather than a cumulative count, is left alone.
+func ResetPoolStats() {
+	instrumentedPoolsMu.Lock()
+	defer instrumentedPoolsMu.Unlock()
+
+	for _, p := range instrumentedPools {
+		p.Reset()
 	}
 }
 
 // ResourceSummary returns a summary of tracked resources.
 func ResourceSummary() string {
 	stats := GetProfiler().GetGlobalStats()
-	return fmt.Sprintf(
+	summary := fmt.Sprintf(
 		"Resources: %d live (peak %d), %d bytes (peak %d bytes), %d created, %d destroyed",
 		stats.CurrentHandles, stats.PeakHandles,
 		stats.CurrentBytes, stats.PeakBytes,
 		stats.TotalHandlesCreated, stats.TotalHandlesDestroyed,
 	)
+
+	var gets, news, discards int64
+	for _, ps := range PoolStats() {
+		gets += ps.Gets
+		news += ps.News
+		discards += ps.Discards
+	}
+	reuse := PoolStat{Gets: gets, News: news}.ReuseRatio()
+	summary += fmt.Sprintf("; pools: %d gets, %.1f%% reuse, %d discards", gets, reuse*100, discards)
+
+	return summary
 }
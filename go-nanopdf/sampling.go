@@ -0,0 +1,98 @@
+package nanopdf
+
+import (
+	"math"
+	"math/rand"
+)
+
+// defaultSamplingRate mirrors runtime.MemProfileRate's default of 512KB:
+// on average, one allocation is sampled for every defaultSamplingRate
+// bytes allocated.
+const defaultSamplingRate int64 = 512 * 1024
+
+// SetSamplingRate controls how much of RecordAllocation's per-allocation
+// work (detailed record, bucket update) actually runs, trading accuracy
+// for overhead so profiling can stay on in production:
+//
+//   - rate == 0 disables sampling entirely: no allocation is recorded in
+//     detail, though the exact atomic counters (TotalHandlesCreated,
+//     CurrentHandles, ...) returned by GetGlobalStats keep counting every
+//     allocation regardless.
+//   - rate == 1 samples every allocation (the original, unsampled
+//     behavior).
+//   - rate > 1 Poisson-samples allocations weighted by size: a per-
+//     profiler counter is decremented by sizeBytes on every call and,
+//     once it crosses zero, this allocation is recorded and the counter
+//     is reset to an exponentially distributed value with mean rate.
+//     Larger allocations are proportionally more likely to be sampled,
+//     and each sampled allocation's contribution to GetStatsByType and
+//     the bucket report (MemProfile) is scaled by rate/sizeBytes so the
+//     totals remain unbiased estimates. Because only sampled handles get
+//     a detailed record, GetLiveAllocations/GenerateLeakReport see only
+//     the sampled subset, not every live handle.
+func (p *MemoryProfiler) SetSamplingRate(bytes int64) {
+	p.samplingRate.Store(bytes)
+	p.nextSample.Store(sampleInterval(bytes))
+}
+
+// SamplingRate returns the currently configured sampling rate in bytes.
+func (p *MemoryProfiler) SamplingRate() int64 {
+	return p.samplingRate.Load()
+}
+
+// sampleInterval draws the number of bytes until the next sample from an
+// exponential distribution with the given mean, as runtime.MemProfileRate
+// sampling does. A non-positive rate has no meaningful interval.
+func sampleInterval(rate int64) int64 {
+	if rate <= 0 {
+		return 0
+	}
+	interval := int64(rand.ExpFloat64() * float64(rate))
+	if interval < 1 {
+		interval = 1
+	}
+	return interval
+}
+
+// shouldSample decides whether this allocation is recorded in detail and,
+// if so, the weight its contribution should carry so aggregated totals
+// remain unbiased. See SetSamplingRate for the sampling scheme.
+func (p *MemoryProfiler) shouldSample(sizeBytes int64) (sampled bool, weight float64) {
+	rate := p.samplingRate.Load()
+	if rate <= 0 {
+		return false, 0
+	}
+	if rate == 1 {
+		return true, 1
+	}
+
+	size := sizeBytes
+	if size < 1 {
+		size = 1
+	}
+
+	for {
+		remaining := p.nextSample.Load()
+		next := remaining - size
+		if !p.nextSample.CompareAndSwap(remaining, next) {
+			continue
+		}
+		if next > 0 {
+			return false, 0
+		}
+		p.nextSample.Store(sampleInterval(rate))
+		return true, float64(rate) / float64(size)
+	}
+}
+
+// weightedDelta rounds a sampling weight into the integer count/byte
+// deltas TypeStats and the bucket table accumulate, always contributing
+// at least 1 count so a sampled allocation is never erased by rounding.
+func weightedDelta(weight float64, sizeBytes int64) (countDelta, bytesDelta int64) {
+	countDelta = int64(math.Round(weight))
+	if countDelta < 1 {
+		countDelta = 1
+	}
+	bytesDelta = int64(math.Round(weight * float64(sizeBytes)))
+	return countDelta, bytesDelta
+}
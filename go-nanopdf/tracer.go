@@ -0,0 +1,282 @@
+package nanopdf
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// AllocEventKind distinguishes the two events AllocationTracer sees.
+type AllocEventKind int
+
+const (
+	AllocEventAlloc AllocEventKind = iota
+	AllocEventFree
+)
+
+func (k AllocEventKind) String() string {
+	if k == AllocEventFree {
+		return "Free"
+	}
+	return "Alloc"
+}
+
+// AllocEvent is one allocation or deallocation, as delivered to a tracer
+// installed with SetTracer or retained in the trace ring (WithTraceRing).
+// PCs is the raw call stack captured at the time of the event, matching
+// bucket_profile.go's convention of deferring symbolization until a
+// report actually needs it.
+type AllocEvent struct {
+	Kind         AllocEventKind
+	Handle       uint64
+	ResourceType ResourceType
+	SizeBytes    int64
+	PCs          [32]uintptr
+	Goroutine    int64
+	Time         time.Time
+}
+
+// SetTracer installs fn to be called for every allocation and
+// deallocation event. fn runs on a single dedicated background goroutine,
+// never inline in RecordAllocation/RecordDeallocation, so a slow or
+// blocking tracer cannot add latency to the hot path; events queue up in
+// a bounded lock-free MPSC buffer in the meantime and are dropped (not
+// blocked on) if the tracer falls behind.
+//
+// Pass nil to stop tracing.
+func (p *MemoryProfiler) SetTracer(fn func(ev AllocEvent)) {
+	if fn == nil {
+		p.tracer.Store(nil)
+		return
+	}
+	p.tracer.Store(&fn)
+	p.ensureTraceQueue()
+	p.startTraceDrain()
+}
+
+// WithTraceRing enables a bounded, lock-free ring buffer holding the last
+// n alloc/free events, independent of whether a tracer callback is also
+// installed. Call DumpTraceRing (or, on non-Windows platforms, send
+// SIGUSR2 once EnableProfiling(true) has run) to inspect it after the
+// fact.
+func (p *MemoryProfiler) WithTraceRing(n int) {
+	p.traceRing.Store(newTraceRing(n))
+	p.ensureTraceQueue()
+	p.startTraceDrain()
+}
+
+// DumpTraceRing writes every event currently held in the trace ring to w,
+// oldest first, one line per event. It is a no-op if WithTraceRing was
+// never called.
+func (p *MemoryProfiler) DumpTraceRing(w io.Writer) error {
+	ring := p.traceRing.Load()
+	if ring == nil {
+		return nil
+	}
+	for _, ev := range ring.snapshot() {
+		_, err := fmt.Fprintf(w, "%s %s handle=%d type=%s size=%d goroutine=%d\n",
+			ev.Time.Format(time.RFC3339Nano), ev.Kind, ev.Handle, ev.ResourceType, ev.SizeBytes, ev.Goroutine)
+		if err != nil {
+			return WrapError(ErrCodeSystem, "failed to write trace ring dump", err)
+		}
+	}
+	return nil
+}
+
+// DumpTraceRing dumps the global profiler's trace ring; see
+// (*MemoryProfiler).DumpTraceRing.
+func DumpTraceRing(w io.Writer) error {
+	return GetProfiler().DumpTraceRing(w)
+}
+
+// emitTraceEvent is called from RecordAllocation/RecordDeallocation. It
+// only does work if a tracer or trace ring has actually been configured,
+// and even then only pushes onto the lock-free queue — symbolization and
+// the user callback happen later, off this call stack.
+func (p *MemoryProfiler) emitTraceEvent(kind AllocEventKind, handle uint64, resourceType ResourceType, sizeBytes int64, skip int) {
+	queue := p.traceQueue.Load()
+	if queue == nil {
+		return
+	}
+
+	ev := AllocEvent{
+		Kind:         kind,
+		Handle:       handle,
+		ResourceType: resourceType,
+		SizeBytes:    sizeBytes,
+		Goroutine:    goroutineID(),
+		Time:         time.Now(),
+	}
+	runtime.Callers(skip+1, ev.PCs[:])
+
+	queue.push(ev)
+}
+
+func (p *MemoryProfiler) ensureTraceQueue() {
+	if p.traceQueue.Load() == nil {
+		p.traceQueue.CompareAndSwap(nil, newTraceQueue(4096))
+	}
+}
+
+func (p *MemoryProfiler) startTraceDrain() {
+	p.traceDrainOnce.Do(func() {
+		go p.drainTraceQueue()
+	})
+}
+
+func (p *MemoryProfiler) drainTraceQueue() {
+	for {
+		queue := p.traceQueue.Load()
+		if queue == nil {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		ev, ok := queue.pop()
+		if !ok {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		if tracer := p.tracer.Load(); tracer != nil {
+			(*tracer)(ev)
+		}
+		if ring := p.traceRing.Load(); ring != nil {
+			ring.push(ev)
+		}
+	}
+}
+
+// goroutineID extracts the calling goroutine's ID from runtime.Stack's
+// header line ("goroutine 123 [running]:"), the same trick net/http and
+// other diagnostic libraries use since the runtime doesn't expose it
+// directly.
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	var id int64
+	for _, b := range buf[len("goroutine "):n] {
+		if b < '0' || b > '9' {
+			break
+		}
+		id = id*10 + int64(b-'0')
+	}
+	return id
+}
+
+// installTraceSignalHandler wires a platform signal to dump the global
+// profiler's trace ring to stderr, so a running process can be asked for
+// its recent alloc/free history without restarting it. Installed once,
+// the first time EnableProfiling(true) runs. See tracer_signal_unix.go
+// and tracer_signal_windows.go: SIGUSR2 doesn't exist on Windows, so the
+// signal wiring itself is platform-gated rather than this file.
+
+// traceQueue is a bounded, multi-producer/single-consumer lock-free
+// queue (Dmitry Vyukov's bounded MPMC algorithm, used here with a single
+// consumer): producers never block or take a lock, and a full queue
+// simply drops the event rather than stalling the allocator.
+type traceQueue struct {
+	mask  uint64
+	cells []traceCell
+	enq   atomic.Uint64
+	deq   atomic.Uint64
+}
+
+type traceCell struct {
+	sequence atomic.Uint64
+	value    AllocEvent
+}
+
+func newTraceQueue(size int) *traceQueue {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	q := &traceQueue{mask: uint64(n - 1), cells: make([]traceCell, n)}
+	for i := range q.cells {
+		q.cells[i].sequence.Store(uint64(i))
+	}
+	return q
+}
+
+func (q *traceQueue) push(ev AllocEvent) bool {
+	pos := q.enq.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		seq := cell.sequence.Load()
+		diff := int64(seq) - int64(pos)
+		switch {
+		case diff == 0:
+			if q.enq.CompareAndSwap(pos, pos+1) {
+				cell.value = ev
+				cell.sequence.Store(pos + 1)
+				return true
+			}
+		case diff < 0:
+			return false // full; drop rather than block the allocator
+		default:
+			pos = q.enq.Load()
+		}
+	}
+}
+
+func (q *traceQueue) pop() (AllocEvent, bool) {
+	pos := q.deq.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		seq := cell.sequence.Load()
+		diff := int64(seq) - int64(pos+1)
+		switch {
+		case diff == 0:
+			if q.deq.CompareAndSwap(pos, pos+1) {
+				ev := cell.value
+				cell.sequence.Store(pos + q.mask + 1)
+				return ev, true
+			}
+		case diff < 0:
+			return AllocEvent{}, false // empty
+		default:
+			pos = q.deq.Load()
+		}
+	}
+}
+
+// traceRing is a lock-free fixed-capacity ring of the most recent events,
+// used for crash/signal dumps. Writes are a single atomic add plus an
+// atomic pointer store; reads (snapshot) are best-effort under
+// concurrent writes, which is the right tradeoff for a diagnostic dump.
+type traceRing struct {
+	size   uint64
+	slots  []atomic.Pointer[AllocEvent]
+	cursor atomic.Uint64
+}
+
+func newTraceRing(n int) *traceRing {
+	if n < 1 {
+		n = 1
+	}
+	return &traceRing{size: uint64(n), slots: make([]atomic.Pointer[AllocEvent], n)}
+}
+
+func (r *traceRing) push(ev AllocEvent) {
+	idx := r.cursor.Add(1) - 1
+	r.slots[idx%r.size].Store(&ev)
+}
+
+func (r *traceRing) snapshot() []AllocEvent {
+	cur := r.cursor.Load()
+	start := uint64(0)
+	if cur > r.size {
+		start = cur - r.size
+	}
+
+	result := make([]AllocEvent, 0, r.size)
+	for i := start; i < cur; i++ {
+		if ev := r.slots[i%r.size].Load(); ev != nil {
+			result = append(result, *ev)
+		}
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package nanopdf
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// traceSignalHandlerOnce guards installTraceSignalHandler so repeated
+// EnableProfiling(true) calls don't stack up duplicate signal handlers.
+var traceSignalHandlerOnce sync.Once
+
+// installTraceSignalHandler wires SIGUSR2 to dump the global profiler's
+// trace ring to stderr, so a running process can be asked for its recent
+// alloc/free history without restarting it. Installed once, the first
+// time EnableProfiling(true) runs.
+func installTraceSignalHandler() {
+	traceSignalHandlerOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGUSR2)
+		go func() {
+			for range ch {
+				_ = DumpTraceRing(os.Stderr)
+			}
+		}()
+	})
+}
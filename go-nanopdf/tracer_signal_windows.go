@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package nanopdf
+
+// installTraceSignalHandler is a no-op on Windows: syscall.SIGUSR2
+// doesn't exist there, and Windows has no equivalent signal a running
+// process can be sent to request a trace dump. Use DumpTraceRing
+// directly instead.
+func installTraceSignalHandler() {}